@@ -1,15 +0,0 @@
-package main
-
-import "fmt"
-
-/*
-this is a multi-line comment.
-everything between the open slash/star and
-the end star/slash is a comment
-they are not used very much
-*/
-
-// main is the primary function:
-func main() {
-	fmt.Println("Hello World!") // trailing comment
-}
@@ -0,0 +1,58 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"gbdmp/learningo/playground"
+)
+
+// maxSnippet limits the size of a POST /play request.
+const maxSnippet = 64 << 10
+
+// playRequest is the body of POST /play.
+type playRequest struct {
+	Code string `json:"code"`
+}
+
+// EnablePlayground makes s run snippets posted to /play with the limits of
+// opts, at most n at a time. It must be called before s serves requests.
+func (s *Server) EnablePlayground(opts playground.Options, n int) {
+	s.play = &opts
+	s.playSlots = make(chan struct{}, max(n, 1))
+}
+
+func (s *Server) playground(w http.ResponseWriter, r *http.Request) {
+	if s.play == nil {
+		writeError(w, http.StatusNotFound, "the playground is not enabled")
+		return
+	}
+	if r.Method != http.MethodPost {
+		notAllowed(w, "POST")
+		return
+	}
+	var req playRequest
+	dec := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxSnippet))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if req.Code == "" {
+		writeError(w, http.StatusBadRequest, "code is required")
+		return
+	}
+
+	select {
+	case s.playSlots <- struct{}{}:
+		defer func() { <-s.playSlots }()
+	case <-r.Context().Done():
+		return
+	}
+	res, err := playground.Run(r.Context(), req.Code, *s.play)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	writeJSON(w, r, http.StatusOK, res)
+}
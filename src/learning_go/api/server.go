@@ -0,0 +1,406 @@
+// Package api serves the people registry as a JSON HTTP API.
+//
+// Routes:
+//
+//	GET    /people                    everybody, sorted by name
+//	POST   /people                    add {"name": ..., "birthday": ...}
+//	GET    /people/{name}             one person
+//	DELETE /people/{name}             remove a person
+//	GET    /people/{name}/age         age today or ?on=DATE
+//	GET    /people/{name}/milestones  milestones, ?on=DATE&jurisdiction=J
+//	GET    /upcoming                  birthdays, ?days=N&from=DATE&leap=P
+//	POST   /play                      run {"code": ...} in the playground, if enabled
+//
+// GET responses carry an ETag and honour If-None-Match. POST /people and
+// DELETE /people/{name} honour If-Match against the ETag of the collection
+// and of the person respectively, so clients can make conditional updates.
+package api
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode/utf8"
+
+	"gbdmp/learningo/calendar"
+	"gbdmp/learningo/milestones"
+	"gbdmp/learningo/people"
+	"gbdmp/learningo/playground"
+)
+
+// Server is an http.Handler serving a registry. It is safe for
+// concurrent use; the registry must not be changed by anybody else while
+// it is being served.
+type Server struct {
+	mu       sync.RWMutex
+	registry *people.Registry
+	rules    *milestones.RuleSet
+	policy   calendar.LeapPolicy
+
+	play      *playground.Options
+	playSlots chan struct{}
+}
+
+// NewServer returns a server for r that evaluates milestones with rules
+// and celebrates 29 February according to policy.
+func NewServer(r *people.Registry, rules *milestones.RuleSet, policy calendar.LeapPolicy) *Server {
+	return &Server{registry: r, rules: rules, policy: policy}
+}
+
+// ServeHTTP implements http.Handler.
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	path := strings.Trim(r.URL.Path, "/")
+	switch {
+	case path == "people":
+		s.people(w, r)
+	case path == "upcoming":
+		s.upcoming(w, r)
+	case path == "play":
+		s.playground(w, r)
+	case strings.HasPrefix(path, "people/"):
+		rest := strings.TrimPrefix(path, "people/")
+		name, sub, _ := strings.Cut(rest, "/")
+		switch sub {
+		case "":
+			s.person(w, r, name)
+		case "age":
+			s.age(w, r, name)
+		case "milestones":
+			s.milestones(w, r, name)
+		default:
+			writeError(w, http.StatusNotFound, "no such resource")
+		}
+	default:
+		writeError(w, http.StatusNotFound, "no such resource")
+	}
+}
+
+// maxPerson limits the size of a POST /people request.
+const maxPerson = 4 << 10
+
+func (s *Server) people(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet, http.MethodHead:
+		s.mu.RLock()
+		list := s.registry.List()
+		s.mu.RUnlock()
+		writeJSON(w, r, http.StatusOK, list)
+
+	case http.MethodPost:
+		// encoding/json quietly replaces invalid UTF-8, so check the
+		// body before decoding it
+		body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxPerson))
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			writeError(w, http.StatusRequestEntityTooLarge, fmt.Sprintf("body is larger than %d bytes", maxPerson))
+			return
+		}
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		if !utf8.Valid(body) {
+			writeError(w, http.StatusBadRequest, "body is not valid UTF-8")
+			return
+		}
+		var p people.Person
+		dec := json.NewDecoder(bytes.NewReader(body))
+		dec.DisallowUnknownFields()
+		if err := dec.Decode(&p); err != nil {
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		if p.Name == "" || p.Birthday.IsZero() {
+			writeError(w, http.StatusBadRequest, "name and birthday are required")
+			return
+		}
+		if strings.Contains(p.Name, "/") {
+			writeError(w, http.StatusBadRequest, "name must not contain a slash")
+			return
+		}
+
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		if !ifMatch(r, etag(s.registry.List())) {
+			writeError(w, http.StatusPreconditionFailed, "people have changed")
+			return
+		}
+		if err := s.registry.Add(p); err != nil {
+			if errors.Is(err, people.ErrExists) {
+				writeError(w, http.StatusConflict, err.Error())
+				return
+			}
+			if errors.Is(err, people.ErrName) {
+				writeError(w, http.StatusBadRequest, err.Error())
+				return
+			}
+			writeError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+		w.Header().Set("Location", "/people/"+url.PathEscape(p.Name))
+		writeJSON(w, r, http.StatusCreated, p)
+
+	default:
+		notAllowed(w, "GET, HEAD, POST")
+	}
+}
+
+func (s *Server) person(w http.ResponseWriter, r *http.Request, name string) {
+	switch r.Method {
+	case http.MethodGet, http.MethodHead:
+		p, ok := s.lookup(w, name)
+		if ok {
+			writeJSON(w, r, http.StatusOK, p)
+		}
+
+	case http.MethodDelete:
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		p, ok := s.registry.Lookup(name)
+		if !ok {
+			writeError(w, http.StatusNotFound, fmt.Sprintf("%v: %s", people.ErrNotFound, name))
+			return
+		}
+		if !ifMatch(r, etag(p)) {
+			writeError(w, http.StatusPreconditionFailed, name+" has changed")
+			return
+		}
+		if err := s.registry.Remove(name); err != nil {
+			writeError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+
+	default:
+		notAllowed(w, "GET, HEAD, DELETE")
+	}
+}
+
+type ageResponse struct {
+	Name     string      `json:"name"`
+	Birthday people.Date `json:"birthday"`
+	On       people.Date `json:"on"`
+	Age      int         `json:"age"`
+}
+
+func (s *Server) age(w http.ResponseWriter, r *http.Request, name string) {
+	if !onlyGet(w, r) {
+		return
+	}
+	on, ok := dateParam(w, r, "on")
+	if !ok {
+		return
+	}
+	p, ok := s.lookup(w, name)
+	if !ok {
+		return
+	}
+	writeJSON(w, r, http.StatusOK, ageResponse{Name: p.Name, Birthday: p.Birthday, On: on, Age: p.AgeOn(on)})
+}
+
+type milestone struct {
+	Name  string       `json:"name"`
+	Label string       `json:"label"`
+	Age   int          `json:"age"`
+	Date  *people.Date `json:"date,omitempty"`
+}
+
+type milestonesResponse struct {
+	Name    string      `json:"name"`
+	On      people.Date `json:"on"`
+	Age     int         `json:"age"`
+	Reached []milestone `json:"reached"`
+	Next    []milestone `json:"next"`
+}
+
+func (s *Server) milestones(w http.ResponseWriter, r *http.Request, name string) {
+	if !onlyGet(w, r) {
+		return
+	}
+	on, ok := dateParam(w, r, "on")
+	if !ok {
+		return
+	}
+	rules, err := s.rules.Rules(r.URL.Query().Get("jurisdiction"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	p, ok := s.lookup(w, name)
+	if !ok {
+		return
+	}
+
+	rep := rules.Evaluate(p, on)
+	resp := milestonesResponse{Name: p.Name, On: on, Age: rep.Age, Reached: []milestone{}, Next: []milestone{}}
+	for _, m := range rep.Reached {
+		resp.Reached = append(resp.Reached, milestone{Name: m.Name, Label: m.String(), Age: m.Since(rep.Age)})
+	}
+	if rep.Next != nil {
+		for _, m := range rep.Next.Rules {
+			date := rep.Next.Date
+			resp.Next = append(resp.Next, milestone{Name: m.Name, Label: m.String(), Age: rep.Next.Age, Date: &date})
+		}
+	}
+	writeJSON(w, r, http.StatusOK, resp)
+}
+
+type upcomingEntry struct {
+	Name  string      `json:"name"`
+	Kind  string      `json:"kind"`
+	On    people.Date `json:"on"`
+	Turns int         `json:"turns"`
+	Days  int         `json:"days"`
+}
+
+func (s *Server) upcoming(w http.ResponseWriter, r *http.Request) {
+	if !onlyGet(w, r) {
+		return
+	}
+	q := r.URL.Query()
+	days := 30
+	if v := q.Get("days"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 0 {
+			writeError(w, http.StatusBadRequest, "days must be a number of days")
+			return
+		}
+		days = n
+	}
+	policy := s.policy
+	if v := q.Get("leap"); v != "" {
+		p, err := calendar.ParseLeapPolicy(v)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		policy = p
+	}
+	from, ok := dateParam(w, r, "from")
+	if !ok {
+		return
+	}
+
+	s.mu.RLock()
+	cal := calendar.FromRegistry(s.registry, policy)
+	s.mu.RUnlock()
+	list := []upcomingEntry{}
+	for _, o := range cal.Upcoming(from, days) {
+		list = append(list, upcomingEntry{Name: o.Name, Kind: o.Kind, On: o.On, Turns: o.Years, Days: o.Days})
+	}
+	writeJSON(w, r, http.StatusOK, list)
+}
+
+// lookup returns the named person or answers with 404.
+func (s *Server) lookup(w http.ResponseWriter, name string) (people.Person, bool) {
+	s.mu.RLock()
+	p, ok := s.registry.Lookup(name)
+	s.mu.RUnlock()
+	if !ok {
+		writeError(w, http.StatusNotFound, fmt.Sprintf("%v: %s", people.ErrNotFound, name))
+	}
+	return p, ok
+}
+
+// dateParam returns the date in the named query parameter, or today if
+// there is none. It answers with 400 if the date is invalid.
+func dateParam(w http.ResponseWriter, r *http.Request, name string) (people.Date, bool) {
+	v := r.URL.Query().Get(name)
+	if v == "" {
+		return people.Today(), true
+	}
+	d, err := people.ParseDate(v)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return people.Date{}, false
+	}
+	return d, true
+}
+
+func onlyGet(w http.ResponseWriter, r *http.Request) bool {
+	if r.Method == http.MethodGet || r.Method == http.MethodHead {
+		return true
+	}
+	notAllowed(w, "GET, HEAD")
+	return false
+}
+
+func notAllowed(w http.ResponseWriter, allow string) {
+	w.Header().Set("Allow", allow)
+	writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+}
+
+// etag returns a strong entity tag for the JSON encoding of v.
+func etag(v any) string {
+	data, _ := json.Marshal(v)
+	sum := sha256.Sum256(data)
+	return `"` + hex.EncodeToString(sum[:8]) + `"`
+}
+
+// ifMatch reports whether the If-Match header of r, if any, lists tag.
+func ifMatch(r *http.Request, tag string) bool {
+	h := r.Header.Get("If-Match")
+	if h == "" {
+		return true
+	}
+	for _, t := range strings.Split(h, ",") {
+		t = strings.TrimSpace(t)
+		if t == "*" || t == tag {
+			return true
+		}
+	}
+	return false
+}
+
+// noneMatch reports whether the If-None-Match header of r lists tag.
+func noneMatch(r *http.Request, tag string) bool {
+	for _, t := range strings.Split(r.Header.Get("If-None-Match"), ",") {
+		t = strings.TrimPrefix(strings.TrimSpace(t), "W/")
+		if t == "*" || t == tag {
+			return true
+		}
+	}
+	return false
+}
+
+// writeJSON writes v with an ETag. GET and HEAD requests whose
+// If-None-Match matches the tag get 304 Not Modified instead.
+func writeJSON(w http.ResponseWriter, r *http.Request, status int, v any) {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	tag := etag(v)
+	w.Header().Set("ETag", tag)
+	if status == http.StatusOK && noneMatch(r, tag) {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Content-Length", strconv.Itoa(len(data)+1))
+	w.WriteHeader(status)
+	if r.Method != http.MethodHead {
+		w.Write(append(data, '\n'))
+	}
+}
+
+type errorResponse struct {
+	Error string `json:"error"`
+}
+
+func writeError(w http.ResponseWriter, status int, msg string) {
+	data, _ := json.Marshal(errorResponse{Error: msg})
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	w.Write(append(data, '\n'))
+}
@@ -0,0 +1,282 @@
+package api_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"gbdmp/learningo/api"
+	"gbdmp/learningo/calendar"
+	"gbdmp/learningo/milestones"
+	"gbdmp/learningo/people"
+)
+
+func newServer() *api.Server {
+	r := people.NewRegistry(
+		people.Person{Name: "Gerd", Birthday: people.NewDate(1967, time.June, 30)},
+		people.Person{Name: "Leap", Birthday: people.NewDate(2004, time.February, 29)},
+		people.Person{Name: "Tim", Birthday: people.NewDate(2017, time.June, 6)},
+	)
+	return api.NewServer(r, milestones.Default(), calendar.Mar1)
+}
+
+// do sends a request to s and returns the response. header holds pairs of
+// header names and values.
+func do(t *testing.T, s http.Handler, method, target, body string, header ...string) *httptest.ResponseRecorder {
+	t.Helper()
+	req := httptest.NewRequest(method, target, strings.NewReader(body))
+	for i := 0; i+1 < len(header); i += 2 {
+		req.Header.Set(header[i], header[i+1])
+	}
+	w := httptest.NewRecorder()
+	s.ServeHTTP(w, req)
+	return w
+}
+
+func decode(t *testing.T, w *httptest.ResponseRecorder, v any) {
+	t.Helper()
+	if err := json.Unmarshal(w.Body.Bytes(), v); err != nil {
+		t.Fatalf("decoding %q: %v", w.Body, err)
+	}
+}
+
+func TestStatus(t *testing.T) {
+	for _, tt := range []struct {
+		method, target, body string
+		want                 int
+	}{
+		{"GET", "/people", "", http.StatusOK},
+		{"HEAD", "/people", "", http.StatusOK},
+		{"GET", "/people/Tim", "", http.StatusOK},
+		{"GET", "/people/Nobody", "", http.StatusNotFound},
+		{"GET", "/people/Tim/shoe-size", "", http.StatusNotFound},
+		{"GET", "/nothing", "", http.StatusNotFound},
+		{"POST", "/people", `{"name":"Ada","birthday":"1815-12-10"}`, http.StatusCreated},
+		{"POST", "/people", `{"name":"Tim","birthday":"2017-06-06"}`, http.StatusConflict},
+		{"POST", "/people", `{"name":"Ada"}`, http.StatusBadRequest},
+		{"POST", "/people", `{"name":"A/B","birthday":"1815-12-10"}`, http.StatusBadRequest},
+		{"POST", "/people", "{\"name\":\"X\x81\",\"birthday\":\"1815-12-10\"}", http.StatusBadRequest},
+		{"POST", "/people", `{"name":"Ada","birthday":"1815-12-10","age":3}`, http.StatusBadRequest},
+		{"POST", "/people", `{"name":"Ada","birthday":"31.02.2000"}`, http.StatusBadRequest},
+		{"POST", "/people", `{"name":"` + strings.Repeat("a", 4<<10) + `","birthday":"1815-12-10"}`, http.StatusRequestEntityTooLarge},
+		{"PUT", "/people", "", http.StatusMethodNotAllowed},
+		{"DELETE", "/people/Tim", "", http.StatusNoContent},
+		{"DELETE", "/people/Nobody", "", http.StatusNotFound},
+		{"POST", "/people/Tim", "", http.StatusMethodNotAllowed},
+		{"POST", "/people/Tim/age", "", http.StatusMethodNotAllowed},
+		{"GET", "/people/Tim/age?on=yesterday", "", http.StatusBadRequest},
+		{"GET", "/people/Tim/milestones?jurisdiction=XX", "", http.StatusBadRequest},
+		{"GET", "/upcoming?days=-1", "", http.StatusBadRequest},
+		{"GET", "/upcoming?leap=never", "", http.StatusBadRequest},
+		{"DELETE", "/upcoming", "", http.StatusMethodNotAllowed},
+		{"POST", "/play", `{"code":"package main"}`, http.StatusNotFound},
+	} {
+		w := do(t, newServer(), tt.method, tt.target, tt.body)
+		if w.Code != tt.want {
+			t.Errorf("%s %s: status %d, want %d: %s", tt.method, tt.target, w.Code, tt.want, w.Body)
+		}
+		if w.Code == http.StatusMethodNotAllowed && w.Header().Get("Allow") == "" {
+			t.Errorf("%s %s: 405 without an Allow header", tt.method, tt.target)
+		}
+	}
+}
+
+func TestAddAndRemove(t *testing.T) {
+	s := newServer()
+	w := do(t, s, "POST", "/people", `{"name":"Ada Lovelace","birthday":"10.12.1815"}`)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("POST: status %d: %s", w.Code, w.Body)
+	}
+	if got, want := w.Header().Get("Location"), "/people/Ada%20Lovelace"; got != want {
+		t.Errorf("Location = %q, want %q", got, want)
+	}
+	var p people.Person
+	decode(t, do(t, s, "GET", "/people/Ada%20Lovelace", ""), &p)
+	if want := (people.Person{Name: "Ada Lovelace", Birthday: people.NewDate(1815, time.December, 10)}); p != want {
+		t.Errorf("GET after POST = %v, want %v", p, want)
+	}
+	if w := do(t, s, "DELETE", "/people/Ada%20Lovelace", ""); w.Code != http.StatusNoContent {
+		t.Fatalf("DELETE: status %d", w.Code)
+	}
+	if w := do(t, s, "GET", "/people/Ada%20Lovelace", ""); w.Code != http.StatusNotFound {
+		t.Errorf("GET after DELETE: status %d, want 404", w.Code)
+	}
+}
+
+func TestAddTooLarge(t *testing.T) {
+	s := newServer()
+	// spaces count too: the limit is on the body, not the name
+	body := `{"name":"Ada","birthday":"1815-12-10"}` + strings.Repeat(" ", 5<<10)
+	w := do(t, s, "POST", "/people", body)
+	var res struct{ Error string }
+	decode(t, w, &res)
+	if w.Code != http.StatusRequestEntityTooLarge || res.Error != "body is larger than 4096 bytes" {
+		t.Errorf("POST of %d bytes: status %d: %s", len(body), w.Code, w.Body)
+	}
+	if w := do(t, s, "GET", "/people/Ada", ""); w.Code != http.StatusNotFound {
+		t.Errorf("a body that is too large added Ada: status %d", w.Code)
+	}
+	if w := do(t, s, "POST", "/people", body[:4<<10]); w.Code != http.StatusCreated {
+		t.Errorf("POST of 4096 bytes: status %d: %s", w.Code, w.Body)
+	}
+}
+
+func TestIfNoneMatch(t *testing.T) {
+	s := newServer()
+	w := do(t, s, "GET", "/people", "")
+	tag := w.Header().Get("ETag")
+	if tag == "" {
+		t.Fatal("GET /people has no ETag")
+	}
+	for _, h := range []string{tag, "W/" + tag, `"other", ` + tag, "*"} {
+		w := do(t, s, "GET", "/people", "", "If-None-Match", h)
+		if w.Code != http.StatusNotModified || w.Body.Len() != 0 {
+			t.Errorf("If-None-Match %s: status %d with %d bytes, want 304 without a body", h, w.Code, w.Body.Len())
+		}
+	}
+	if w := do(t, s, "GET", "/people", "", "If-None-Match", `"other"`); w.Code != http.StatusOK {
+		t.Errorf("If-None-Match of another tag: status %d, want 200", w.Code)
+	}
+
+	do(t, s, "POST", "/people", `{"name":"Ada","birthday":"1815-12-10"}`)
+	w = do(t, s, "GET", "/people", "", "If-None-Match", tag)
+	if w.Code != http.StatusOK {
+		t.Errorf("If-None-Match after a change: status %d, want 200", w.Code)
+	}
+	if w.Header().Get("ETag") == tag {
+		t.Error("ETag did not change with the people")
+	}
+}
+
+func TestIfMatch(t *testing.T) {
+	s := newServer()
+	tag := do(t, s, "GET", "/people", "").Header().Get("ETag")
+	ada := `{"name":"Ada","birthday":"1815-12-10"}`
+	if w := do(t, s, "POST", "/people", ada, "If-Match", `"stale"`); w.Code != http.StatusPreconditionFailed {
+		t.Errorf("POST with a stale If-Match: status %d, want 412", w.Code)
+	}
+	if w := do(t, s, "POST", "/people", ada, "If-Match", tag); w.Code != http.StatusCreated {
+		t.Errorf("POST with a current If-Match: status %d, want 201", w.Code)
+	}
+	// the collection changed, so the old tag no longer matches
+	bob := `{"name":"Bob","birthday":"1990-01-01"}`
+	if w := do(t, s, "POST", "/people", bob, "If-Match", tag); w.Code != http.StatusPreconditionFailed {
+		t.Errorf("POST with the tag from before the change: status %d, want 412", w.Code)
+	}
+
+	timTag := do(t, s, "GET", "/people/Tim", "").Header().Get("ETag")
+	if w := do(t, s, "DELETE", "/people/Tim", "", "If-Match", tag); w.Code != http.StatusPreconditionFailed {
+		t.Errorf("DELETE with the tag of another resource: status %d, want 412", w.Code)
+	}
+	if w := do(t, s, "DELETE", "/people/Tim", "", "If-Match", timTag); w.Code != http.StatusNoContent {
+		t.Errorf("DELETE with the person's tag: status %d, want 204", w.Code)
+	}
+	if w := do(t, s, "DELETE", "/people/Gerd", "", "If-Match", "*"); w.Code != http.StatusNoContent {
+		t.Errorf("DELETE with If-Match *: status %d, want 204", w.Code)
+	}
+}
+
+func TestAge(t *testing.T) {
+	s := newServer()
+	for _, tt := range []struct {
+		target string
+		want   int
+	}{
+		{"/people/Tim/age?on=2023-10-13", 6},
+		{"/people/Tim/age?on=05.06.2024", 6},
+		{"/people/Tim/age?on=06.06.2024", 7},
+		{"/people/Leap/age?on=2023-02-28", 18},
+		{"/people/Leap/age?on=2023-03-01", 19},
+	} {
+		w := do(t, s, "GET", tt.target, "")
+		var got struct {
+			Name string `json:"name"`
+			On   string `json:"on"`
+			Age  int    `json:"age"`
+		}
+		decode(t, w, &got)
+		if w.Code != http.StatusOK || got.Age != tt.want {
+			t.Errorf("GET %s = %d %+v, want age %d", tt.target, w.Code, got, tt.want)
+		}
+	}
+}
+
+type milestonesResponse struct {
+	Age     int `json:"age"`
+	Reached []struct {
+		Name string `json:"name"`
+	} `json:"reached"`
+	Next []struct {
+		Name string `json:"name"`
+		Age  int    `json:"age"`
+		Date string `json:"date"`
+	} `json:"next"`
+}
+
+func TestMilestones(t *testing.T) {
+	s := newServer()
+	var got milestonesResponse
+	decode(t, do(t, s, "GET", "/people/Tim/milestones?on=2023-10-13", ""), &got)
+	if got.Age != 6 || len(got.Reached) != 0 {
+		t.Errorf("Tim at 6 = %+v, want no milestones reached", got)
+	}
+	if len(got.Next) != 1 || got.Next[0].Name != "small-prime" || got.Next[0].Age != 7 || got.Next[0].Date != "2024-06-06" {
+		t.Errorf("next milestones of Tim at 6 = %+v, want small-prime at 7 on 2024-06-06", got.Next)
+	}
+
+	got = milestonesResponse{}
+	decode(t, do(t, s, "GET", "/people/Gerd/milestones?on=2023-10-13&jurisdiction=UK", ""), &got)
+	var reached []string
+	for _, m := range got.Reached {
+		reached = append(reached, m.Name)
+	}
+	if strings.Join(reached, " ") != "drive vote drink" {
+		t.Errorf("Gerd in the UK reached %v, want drive, vote and drink", reached)
+	}
+	if len(got.Next) != 1 || got.Next[0].Name != "retire" || got.Next[0].Age != 66 {
+		t.Errorf("next milestone of Gerd in the UK = %+v, want retire at 66", got.Next)
+	}
+}
+
+func TestUpcoming(t *testing.T) {
+	s := newServer()
+	type entry struct {
+		Name  string `json:"name"`
+		On    string `json:"on"`
+		Turns int    `json:"turns"`
+		Days  int    `json:"days"`
+	}
+	var got []entry
+	decode(t, do(t, s, "GET", "/upcoming?from=2024-06-01&days=30", ""), &got)
+	want := []entry{{"Tim", "2024-06-06", 7, 5}, {"Gerd", "2024-06-30", 57, 29}}
+	if len(got) != len(want) || got[0] != want[0] || got[1] != want[1] {
+		t.Errorf("upcoming = %+v, want %+v", got, want)
+	}
+
+	for _, tt := range []struct{ leap, want string }{
+		{"", "2023-03-01"}, // the server's policy
+		{"feb28", "2023-02-28"},
+		{"mar1", "2023-03-01"},
+		{"leap-only", ""},
+	} {
+		got = nil
+		decode(t, do(t, s, "GET", "/upcoming?from=2023-02-20&days=10&leap="+tt.leap, ""), &got)
+		on := ""
+		for _, e := range got {
+			if e.Name == "Leap" {
+				on = e.On
+			}
+		}
+		if on != tt.want {
+			t.Errorf("leap=%s: Leap's birthday on %q, want %q", tt.leap, on, tt.want)
+		}
+	}
+
+	w := do(t, s, "GET", "/upcoming?from=2024-01-01&days=0", "")
+	if strings.TrimSpace(w.Body.String()) != "[]" {
+		t.Errorf("no upcoming birthdays = %s, want []", w.Body)
+	}
+}
@@ -0,0 +1,158 @@
+// Package calendar lists upcoming birthdays and other anniversaries and
+// exports them as an iCalendar (RFC 5545) file.
+package calendar
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"gbdmp/learningo/people"
+)
+
+// LeapPolicy decides when an anniversary that falls on 29 February is
+// celebrated in years that are not leap years.
+type LeapPolicy int
+
+const (
+	// Feb28 moves the anniversary to 28 February.
+	Feb28 LeapPolicy = iota
+	// Mar1 moves the anniversary to 1 March. This is the day on which
+	// people.Person.AgeOn counts somebody born on 29 February as a year older.
+	Mar1
+	// LeapYearsOnly skips the anniversary in years that are not leap years.
+	LeapYearsOnly
+)
+
+var leapPolicyNames = map[LeapPolicy]string{
+	Feb28:         "feb28",
+	Mar1:          "mar1",
+	LeapYearsOnly: "leap-only",
+}
+
+func (p LeapPolicy) String() string {
+	if name, ok := leapPolicyNames[p]; ok {
+		return name
+	}
+	return fmt.Sprintf("LeapPolicy(%d)", int(p))
+}
+
+// ParseLeapPolicy returns the policy with the given name: feb28, mar1 or
+// leap-only.
+func ParseLeapPolicy(name string) (LeapPolicy, error) {
+	for p, n := range leapPolicyNames {
+		if n == name {
+			return p, nil
+		}
+	}
+	return 0, fmt.Errorf("unknown leap day policy %q, want feb28, mar1 or leap-only", name)
+}
+
+// Birthday is the kind of the anniversaries made from people.
+const Birthday = "birthday"
+
+// Anniversary is a date that recurs every year.
+type Anniversary struct {
+	Name string      // who or what is celebrated
+	Kind string      // Birthday, "wedding", ...
+	Date people.Date // the original date
+}
+
+// Occurrence is an anniversary on a particular day.
+type Occurrence struct {
+	Anniversary
+	On    people.Date // the day it is celebrated
+	Years int         // the age turned, or the number of years since Date
+	Days  int         // days from the start of the query until On
+}
+
+// Calendar is a set of anniversaries.
+type Calendar struct {
+	Policy  LeapPolicy
+	entries []Anniversary
+}
+
+// New returns an empty calendar using the given leap day policy.
+func New(policy LeapPolicy) *Calendar {
+	return &Calendar{Policy: policy}
+}
+
+// FromRegistry returns a calendar with the birthday of everybody in r.
+func FromRegistry(r *people.Registry, policy LeapPolicy) *Calendar {
+	c := New(policy)
+	for _, p := range r.List() {
+		c.Add(Anniversary{Name: p.Name, Kind: Birthday, Date: p.Birthday})
+	}
+	return c
+}
+
+// FromBirthdays returns a calendar with the birthdays in m, a map from
+// name to birth date like the one in the maps lesson.
+func FromBirthdays(m map[string]people.Date, policy LeapPolicy) *Calendar {
+	c := New(policy)
+	for name, d := range m {
+		c.Add(Anniversary{Name: name, Kind: Birthday, Date: d})
+	}
+	sort.Slice(c.entries, func(i, j int) bool { return c.entries[i].Name < c.entries[j].Name })
+	return c
+}
+
+// Add adds an anniversary to the calendar.
+func (c *Calendar) Add(a Anniversary) {
+	c.entries = append(c.entries, a)
+}
+
+// Anniversaries returns the anniversaries in the calendar.
+func (c *Calendar) Anniversaries() []Anniversary {
+	return append([]Anniversary(nil), c.entries...)
+}
+
+// Upcoming returns the anniversaries celebrated from the day from up to and
+// including the day days later, sorted by date and name.
+func (c *Calendar) Upcoming(from people.Date, days int) []Occurrence {
+	end := addDays(from, days)
+	var list []Occurrence
+	for _, a := range c.entries {
+		for year := from.Year; year <= end.Year; year++ {
+			on, ok := c.on(a.Date, year)
+			if !ok || on.Before(from) || on.After(end) || !on.After(a.Date) {
+				continue
+			}
+			list = append(list, Occurrence{
+				Anniversary: a,
+				On:          on,
+				Years:       year - a.Date.Year,
+				Days:        daysBetween(from, on),
+			})
+		}
+	}
+	sort.SliceStable(list, func(i, j int) bool {
+		if list[i].On != list[j].On {
+			return list[i].On.Before(list[j].On)
+		}
+		return list[i].Name < list[j].Name
+	})
+	return list
+}
+
+// on returns the day on which an anniversary of d is celebrated in year.
+func (c *Calendar) on(d people.Date, year int) (people.Date, bool) {
+	if d.Month != time.February || d.Day != 29 || people.IsLeap(year) {
+		return people.NewDate(year, d.Month, d.Day), true
+	}
+	switch c.Policy {
+	case Feb28:
+		return people.NewDate(year, time.February, 28), true
+	case Mar1:
+		return people.NewDate(year, time.March, 1), true
+	}
+	return people.Date{}, false
+}
+
+func addDays(d people.Date, n int) people.Date {
+	return people.DateOf(d.Time().AddDate(0, 0, n))
+}
+
+func daysBetween(from, to people.Date) int {
+	return int(to.Time().Sub(from.Time()) / (24 * time.Hour))
+}
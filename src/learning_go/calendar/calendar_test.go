@@ -0,0 +1,215 @@
+package calendar_test
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	"gbdmp/learningo/calendar"
+	"gbdmp/learningo/people"
+)
+
+var leap = calendar.Anniversary{Name: "Leap", Kind: calendar.Birthday, Date: people.NewDate(2004, time.February, 29)}
+
+// list returns the occurrences as "name day years" separated by commas.
+func list(occs []calendar.Occurrence) string {
+	var s []string
+	for _, o := range occs {
+		s = append(s, fmt.Sprintf("%s %s %d", o.Name, o.On, o.Years))
+	}
+	return strings.Join(s, ", ")
+}
+
+func TestUpcoming(t *testing.T) {
+	c := calendar.New(calendar.Feb28)
+	c.Add(calendar.Anniversary{Name: "Bob", Kind: calendar.Birthday, Date: people.NewDate(2000, time.March, 1)})
+	c.Add(calendar.Anniversary{Name: "Ada", Kind: "wedding", Date: people.NewDate(2010, time.March, 1)})
+	c.Add(calendar.Anniversary{Name: "Eve", Kind: calendar.Birthday, Date: people.NewDate(1990, time.December, 31)})
+	c.Add(calendar.Anniversary{Name: "New", Kind: calendar.Birthday, Date: people.NewDate(2023, time.March, 1)})
+
+	from := people.NewDate(2023, time.February, 20)
+	got := c.Upcoming(from, 375)
+	want := "Ada 01.03.2023 13, Bob 01.03.2023 23, Eve 31.12.2023 33, Ada 01.03.2024 14, Bob 01.03.2024 24, New 01.03.2024 1"
+	if list(got) != want {
+		t.Errorf("Upcoming(%s, 375) =\n%s\nwant\n%s", from, list(got), want)
+	}
+	if got[0].Days != 9 || got[2].Days != 314 {
+		t.Errorf("Days = %d, %d, want 9, 314", got[0].Days, got[2].Days)
+	}
+	// the range includes both ends
+	if got := list(c.Upcoming(people.NewDate(2023, time.March, 1), 0)); got != "Ada 01.03.2023 13, Bob 01.03.2023 23" {
+		t.Errorf("Upcoming(01.03.2023, 0) = %s", got)
+	}
+	if got := c.Upcoming(people.NewDate(2023, time.March, 2), 30); len(got) != 0 {
+		t.Errorf("Upcoming(02.03.2023, 30) = %s, want nothing", list(got))
+	}
+}
+
+func TestUpcomingLeapDay(t *testing.T) {
+	for _, tt := range []struct {
+		policy calendar.LeapPolicy
+		from   people.Date
+		want   string
+	}{
+		{calendar.Feb28, people.NewDate(2023, time.February, 1), "Leap 28.02.2023 19"},
+		{calendar.Mar1, people.NewDate(2023, time.February, 1), "Leap 01.03.2023 19"},
+		{calendar.LeapYearsOnly, people.NewDate(2023, time.February, 1), ""},
+		// in leap years every policy keeps 29 February
+		{calendar.Feb28, people.NewDate(2024, time.February, 1), "Leap 29.02.2024 20"},
+		{calendar.Mar1, people.NewDate(2024, time.February, 1), "Leap 29.02.2024 20"},
+		{calendar.LeapYearsOnly, people.NewDate(2024, time.February, 1), "Leap 29.02.2024 20"},
+		// not before the day itself
+		{calendar.Mar1, people.NewDate(2004, time.February, 1), ""},
+	} {
+		c := calendar.New(tt.policy)
+		c.Add(leap)
+		if got := list(c.Upcoming(tt.from, 40)); got != tt.want {
+			t.Errorf("%s: Upcoming(%s, 40) = %q, want %q", tt.policy, tt.from, got, tt.want)
+		}
+	}
+}
+
+func TestParseLeapPolicy(t *testing.T) {
+	for _, p := range []calendar.LeapPolicy{calendar.Feb28, calendar.Mar1, calendar.LeapYearsOnly} {
+		if got, err := calendar.ParseLeapPolicy(p.String()); err != nil || got != p {
+			t.Errorf("ParseLeapPolicy(%q) = %v, %v", p, got, err)
+		}
+	}
+	if _, err := calendar.ParseLeapPolicy("feb29"); err == nil {
+		t.Error("ParseLeapPolicy(feb29) succeeds")
+	}
+}
+
+// writeICS returns the lines c.WriteICS writes, without the line ends.
+func writeICS(t *testing.T, c *calendar.Calendar) []string {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := c.WriteICS(&buf, time.Date(2023, time.October, 13, 12, 0, 0, 0, time.UTC)); err != nil {
+		t.Fatal(err)
+	}
+	out := buf.String()
+	if !strings.HasSuffix(out, "END:VCALENDAR\r\n") {
+		t.Fatalf("ICS does not end with END:VCALENDAR and CRLF:\n%s", out)
+	}
+	lines := strings.Split(strings.TrimSuffix(out, "\r\n"), "\r\n")
+	for _, l := range lines {
+		if strings.Contains(l, "\n") {
+			t.Errorf("line without CR: %q", l)
+		}
+	}
+	return lines
+}
+
+// property returns the unfolded value of the first property name in lines.
+func property(lines []string, name string) string {
+	unfolded := strings.ReplaceAll(strings.Join(lines, "\r\n"), "\r\n ", "")
+	for _, l := range strings.Split(unfolded, "\r\n") {
+		if v, ok := strings.CutPrefix(l, name+":"); ok {
+			return v
+		}
+	}
+	return ""
+}
+
+func TestWriteICS(t *testing.T) {
+	c := calendar.New(calendar.Feb28)
+	c.Add(calendar.Anniversary{Name: "Ada", Kind: calendar.Birthday, Date: people.NewDate(1815, time.December, 10)})
+	lines := writeICS(t, c)
+	want := []string{
+		"BEGIN:VCALENDAR",
+		"VERSION:2.0",
+		"PRODID:" + calendar.ProdID,
+		"CALSCALE:GREGORIAN",
+		"METHOD:PUBLISH",
+		"BEGIN:VEVENT",
+		"UID:birthday-ada-18151210@learningo",
+		"DTSTAMP:20231013T120000Z",
+		"DTSTART;VALUE=DATE:18151210",
+		"DURATION:P1D",
+		"RRULE:FREQ=YEARLY",
+		"SUMMARY:Ada's birthday",
+		"DESCRIPTION:Born on 10.12.1815",
+		"TRANSP:TRANSPARENT",
+		"END:VEVENT",
+		"END:VCALENDAR",
+	}
+	if strings.Join(lines, "\n") != strings.Join(want, "\n") {
+		t.Errorf("WriteICS =\n%s\nwant\n%s", strings.Join(lines, "\n"), strings.Join(want, "\n"))
+	}
+}
+
+func TestWriteICSLeapDay(t *testing.T) {
+	for _, tt := range []struct {
+		policy calendar.LeapPolicy
+		rrule  string
+	}{
+		{calendar.Feb28, "FREQ=YEARLY;BYMONTH=2;BYMONTHDAY=-1"},
+		{calendar.Mar1, "FREQ=YEARLY;BYYEARDAY=60"},
+		{calendar.LeapYearsOnly, "FREQ=YEARLY"},
+	} {
+		c := calendar.New(tt.policy)
+		c.Add(leap)
+		if got := property(writeICS(t, c), "RRULE"); got != tt.rrule {
+			t.Errorf("%s: RRULE = %q, want %q", tt.policy, got, tt.rrule)
+		}
+	}
+}
+
+func TestWriteICSEscape(t *testing.T) {
+	c := calendar.New(calendar.Feb28)
+	c.Add(calendar.Anniversary{Name: `Smith, Jones; and\or`, Kind: "wedding\nparty", Date: people.NewDate(2000, time.June, 1)})
+	lines := writeICS(t, c)
+	if got, want := property(lines, "SUMMARY"), `Smith\, Jones\; and\\or's wedding\nparty`; got != want {
+		t.Errorf("SUMMARY = %q, want %q", got, want)
+	}
+	if got, want := property(lines, "UID"), "wedding-party-smith--jones--and-or-20000601@learningo"; got != want {
+		t.Errorf("UID = %q, want %q", got, want)
+	}
+}
+
+func TestWriteICSFolding(t *testing.T) {
+	for _, name := range []string{
+		strings.Repeat("a", 200),
+		strings.Repeat("ä", 100), // two octets each
+		strings.Repeat("a€", 60), // three octets at changing offsets
+		strings.Repeat("😀b", 40), // four octets
+		strings.Repeat("x", 74-len("SUMMARY:")),
+	} {
+		c := calendar.New(calendar.Feb28)
+		c.Add(calendar.Anniversary{Name: name, Kind: calendar.Birthday, Date: people.NewDate(2000, time.June, 1)})
+		lines := writeICS(t, c)
+		for i, l := range lines {
+			if len(l) > 75 {
+				t.Errorf("line %d has %d octets: %q", i, len(l), l)
+			}
+			if !utf8.ValidString(l) {
+				t.Errorf("line %d splits a UTF-8 sequence: %q", i, l)
+			}
+			if i > 0 && l[0] == ' ' && len(l) == 1 {
+				t.Errorf("line %d continues with nothing", i)
+			}
+		}
+		if got, want := property(lines, "SUMMARY"), name+"'s birthday"; got != want {
+			t.Errorf("unfolded SUMMARY = %q, want %q", got, want)
+		}
+	}
+}
+
+func TestWriteICSInvalidUTF8(t *testing.T) {
+	name := "X" + strings.Repeat("\x81", 90)
+	c := calendar.New(calendar.Feb28)
+	c.Add(calendar.Anniversary{Name: name, Kind: calendar.Birthday, Date: people.NewDate(2000, time.January, 1)})
+	lines := writeICS(t, c)
+	// a run of invalid bytes becomes a single replacement character
+	for i, l := range lines {
+		if len(l) > 75 || !utf8.ValidString(l) {
+			t.Errorf("line %d = %q, want valid UTF-8 of at most 75 octets", i, l)
+		}
+	}
+	if got, want := property(lines, "SUMMARY"), "X\uFFFD's birthday"; got != want {
+		t.Errorf("SUMMARY = %q, want %q", got, want)
+	}
+}
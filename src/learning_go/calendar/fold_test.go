@@ -0,0 +1,24 @@
+package calendar
+
+import (
+	"bufio"
+	"strings"
+	"testing"
+)
+
+func TestWriteFoldedInvalidUTF8(t *testing.T) {
+	s := "SUMMARY:X" + strings.Repeat("\x81", 200)
+	var sb strings.Builder
+	w := bufio.NewWriter(&sb)
+	writeFolded(w, s)
+	w.Flush()
+	lines := strings.Split(strings.TrimSuffix(sb.String(), "\r\n"), "\r\n ")
+	for i, l := range lines {
+		if len(l) > 75 || (i > 0 && len(l) > 74) || l == "" {
+			t.Errorf("line %d has %d octets", i, len(l))
+		}
+	}
+	if got := strings.Join(lines, ""); got != s {
+		t.Errorf("unfolded = %q, want %q", got, s)
+	}
+}
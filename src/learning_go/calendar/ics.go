@@ -0,0 +1,130 @@
+package calendar
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"gbdmp/learningo/people"
+)
+
+// ProdID identifies this program in exported iCalendar files.
+const ProdID = "-//gbdmp//learningo//EN"
+
+// WriteICS writes the calendar as an RFC 5545 iCalendar file with one
+// yearly recurring all-day event per anniversary. stamp is used as the
+// DTSTAMP of every event.
+func (c *Calendar) WriteICS(w io.Writer, stamp time.Time) error {
+	bw := bufio.NewWriter(w)
+	line := func(s string) { writeFolded(bw, s) }
+
+	line("BEGIN:VCALENDAR")
+	line("VERSION:2.0")
+	line("PRODID:" + ProdID)
+	line("CALSCALE:GREGORIAN")
+	line("METHOD:PUBLISH")
+	for _, a := range c.entries {
+		line("BEGIN:VEVENT")
+		line("UID:" + uid(a))
+		line("DTSTAMP:" + stamp.UTC().Format("20060102T150405Z"))
+		line("DTSTART;VALUE=DATE:" + icsDate(a.Date))
+		line("DURATION:P1D")
+		line("RRULE:" + c.rrule(a.Date))
+		line("SUMMARY:" + escape(summary(a)))
+		line("DESCRIPTION:" + escape(description(a)))
+		line("TRANSP:TRANSPARENT")
+		line("END:VEVENT")
+	}
+	line("END:VCALENDAR")
+	return bw.Flush()
+}
+
+// rrule returns the recurrence rule for an anniversary of d. Rules for
+// 29 February follow the leap day policy: the last day of February, the
+// 60th day of the year, or only leap years.
+func (c *Calendar) rrule(d people.Date) string {
+	if d.Month == time.February && d.Day == 29 {
+		switch c.Policy {
+		case Feb28:
+			return "FREQ=YEARLY;BYMONTH=2;BYMONTHDAY=-1"
+		case Mar1:
+			return "FREQ=YEARLY;BYYEARDAY=60"
+		}
+	}
+	return "FREQ=YEARLY"
+}
+
+func kind(a Anniversary) string {
+	if a.Kind == "" {
+		return "anniversary"
+	}
+	return a.Kind
+}
+
+func summary(a Anniversary) string {
+	return fmt.Sprintf("%s's %s", a.Name, kind(a))
+}
+
+func description(a Anniversary) string {
+	if a.Kind == Birthday {
+		return "Born on " + a.Date.String()
+	}
+	return "Since " + a.Date.String()
+}
+
+func uid(a Anniversary) string {
+	return fmt.Sprintf("%s-%s-%s@learningo", slug(kind(a)), slug(a.Name), icsDate(a.Date))
+}
+
+// slug returns s in lower case with everything but letters and digits
+// replaced by dashes.
+func slug(s string) string {
+	return strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			return r
+		}
+		return '-'
+	}, strings.ToLower(s))
+}
+
+func icsDate(d people.Date) string {
+	return fmt.Sprintf("%04d%02d%02d", d.Year, d.Month, d.Day)
+}
+
+// escape escapes a TEXT value as described in RFC 5545, section 3.3.11.
+// Invalid UTF-8 is replaced by U+FFFD, as iCalendar files are UTF-8.
+func escape(s string) string {
+	s = strings.ToValidUTF8(s, "\uFFFD")
+	return strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\r\n", `\n`, "\n", `\n`).Replace(s)
+}
+
+// writeFolded writes a content line terminated by CRLF, folding it so
+// that no line is longer than 75 octets without splitting a UTF-8
+// sequence (RFC 5545, section 3.1). Bytes that are not valid UTF-8 may be
+// split.
+func writeFolded(w *bufio.Writer, s string) {
+	limit := 75
+	for len(s) > limit {
+		cut := limit
+		for cut > limit-utf8.UTFMax && !startsRune(s[cut]) {
+			cut--
+		}
+		if cut == limit-utf8.UTFMax {
+			cut = limit // no sequence starts nearby
+		}
+		w.WriteString(s[:cut])
+		w.WriteString("\r\n ")
+		s = s[cut:]
+		limit = 74 // the leading space counts towards the limit
+	}
+	w.WriteString(s)
+	w.WriteString("\r\n")
+}
+
+func startsRune(b byte) bool {
+	return b&0xC0 != 0x80
+}
@@ -0,0 +1,395 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"text/tabwriter"
+
+	"gbdmp/learningo/devenv"
+	"gbdmp/learningo/modcache"
+
+	"golang.org/x/mod/module"
+)
+
+// runCache dispatches the "godev cache" subcommands.
+func runCache(e *env, args []string) error {
+	if len(args) == 0 {
+		return errUsage
+	}
+	switch args[0] {
+	case "ls":
+		return runCacheLs(e, args[1:])
+	case "why":
+		return runCacheWhy(e, args[1:])
+	case "prune":
+		return runCachePrune(e, args[1:])
+	case "verify":
+		return runCacheVerify(e, args[1:])
+	}
+	return errUsage
+}
+
+// cacheGraph lists the module cache of the checkout and loads the module
+// graphs of the manifests in it: the go.mod files and tools.lock.
+func cacheGraph(root string) ([]*modcache.Module, *modcache.Graph, error) {
+	mods, err := modcache.List(modcache.Dir(root))
+	if err != nil {
+		return nil, nil, err
+	}
+	gomods, err := modcache.FindGoMods(root)
+	if err != nil {
+		return nil, nil, err
+	}
+	var roots []modcache.Root
+	for _, name := range gomods {
+		roots = append(roots, modcache.Root{Name: filepath.ToSlash(name), Dir: filepath.Join(root, filepath.Dir(name))})
+	}
+	tools, err := devenv.ReadLock(filepath.Join(root, devenv.LockFile))
+	if err != nil {
+		return nil, nil, err
+	}
+	for _, t := range tools {
+		dir, err := devenv.ToolModule(root, t)
+		if err != nil {
+			return nil, nil, err
+		}
+		defer os.RemoveAll(dir)
+		roots = append(roots, modcache.Root{Name: devenv.LockFile + " " + t.Name, Dir: dir})
+	}
+	g, err := modcache.Reach(context.Background(), root, roots)
+	if err != nil {
+		return nil, nil, err
+	}
+	return mods, g, nil
+}
+
+// runCacheLs lists the module versions in the cache.
+func runCacheLs(e *env, args []string) error {
+	fs := newFlagSet(e, "cache ls")
+	asJSON := fs.Bool("json", false, "print the versions as JSON")
+	unref := fs.Bool("unreferenced", false, "list only the versions no manifest references")
+	paths, err := parseArgs(fs, args)
+	if err != nil {
+		return err
+	}
+	root, err := e.checkout()
+	if err != nil {
+		return err
+	}
+	mods, g, err := cacheGraph(root)
+	if err != nil {
+		return err
+	}
+	if len(paths) > 0 {
+		var picked []*modcache.Module
+		for _, p := range paths {
+			m := findModule(mods, p)
+			if m == nil {
+				return fmt.Errorf("%s is not in the cache", p)
+			}
+			picked = append(picked, m)
+		}
+		mods = picked
+	}
+
+	type version struct {
+		*modcache.Version
+		References []modcache.Reference `json:"references"`
+	}
+	var list []version
+	var size, unrefSize int64
+	unrefCount := 0
+	for _, m := range mods {
+		for _, v := range m.Versions {
+			refs := g.Why(v.Module())
+			if len(refs) == 0 {
+				unrefCount++
+				unrefSize += v.Size
+			} else if *unref {
+				continue
+			}
+			size += v.Size
+			list = append(list, version{v, refs})
+		}
+	}
+
+	if *asJSON {
+		enc := json.NewEncoder(e.stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(list)
+	}
+	tw := tabwriter.NewWriter(e.stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "MODULE\tVERSION\tSIZE\tLAST USED\tREFERENCED BY")
+	for _, v := range list {
+		by := "-"
+		if len(v.References) > 0 {
+			var names []string
+			for _, r := range v.References {
+				names = append(names, r.Root)
+			}
+			by = strings.Join(names, ", ")
+		}
+		contents := ""
+		if !v.HasZip() && v.Dir == "" {
+			contents = " (go.mod only)"
+		}
+		fmt.Fprintf(tw, "%s\t%s%s\t%s\t%s\t%s\n", v.Path, v.Version.Version, contents,
+			formatSize(v.Size), v.LastUsed.Format("2006-01-02 15:04"), by)
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+	fmt.Fprintf(e.stdout, "\n%d versions of %d modules, %s; %d versions not referenced, %s\n",
+		len(list), len(mods), formatSize(size), unrefCount, formatSize(unrefSize))
+	return nil
+}
+
+// runCacheWhy shows how the manifests reach the versions of a module.
+func runCacheWhy(e *env, args []string) error {
+	fs := newFlagSet(e, "cache why")
+	pos, err := parseArgs(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(pos) == 0 {
+		return errUsage
+	}
+	root, err := e.checkout()
+	if err != nil {
+		return err
+	}
+	mods, g, err := cacheGraph(root)
+	if err != nil {
+		return err
+	}
+	for i, arg := range pos {
+		var versions []module.Version
+		if path, version, ok := strings.Cut(arg, "@"); ok {
+			versions = append(versions, module.Version{Path: path, Version: version})
+		} else if m := findModule(mods, arg); m != nil {
+			for _, v := range m.Versions {
+				versions = append(versions, v.Module())
+			}
+		} else {
+			return fmt.Errorf("%s is not in the cache", arg)
+		}
+		if i > 0 {
+			fmt.Fprintln(e.stdout)
+		}
+		for _, mv := range versions {
+			fmt.Fprintln(e.stdout, mv)
+			refs := g.Why(mv)
+			if len(refs) == 0 {
+				fmt.Fprintln(e.stdout, "  not referenced")
+			}
+			for _, r := range refs {
+				fmt.Fprintf(e.stdout, "  %s\n", r)
+			}
+		}
+	}
+	return nil
+}
+
+// runCachePrune removes the versions that no manifest references.
+func runCachePrune(e *env, args []string) error {
+	fs := newFlagSet(e, "cache prune")
+	keepReferenced := fs.Bool("keep-referenced", false, "remove every version that no go.mod of the checkout or tool in tools.lock references")
+	remove := fs.Bool("delete", false, "remove the versions; without it, prune only lists them")
+	pos, err := parseArgs(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(pos) != 0 || !*keepReferenced {
+		return errUsage
+	}
+	root, err := e.checkout()
+	if err != nil {
+		return err
+	}
+	mods, g, err := cacheGraph(root)
+	if err != nil {
+		return err
+	}
+	verb := "would remove"
+	if *remove {
+		verb = "removed"
+	}
+	var size int64
+	unref := g.Unreferenced(mods)
+	for _, v := range unref {
+		if *remove {
+			if err := modcache.Remove(modcache.Dir(root), v); err != nil {
+				return err
+			}
+		}
+		size += v.Size
+		fmt.Fprintf(e.stdout, "%s %s (%s)\n", verb, v.Module(), formatSize(v.Size))
+	}
+	fmt.Fprintf(e.stdout, "%s %d versions, %s\n", verb, len(unref), formatSize(size))
+	if !*remove && len(unref) > 0 {
+		fmt.Fprintln(e.stdout, "run with -delete to remove them")
+	}
+	return nil
+}
+
+// runCacheVerify recomputes the hashes of the cached module versions and
+// compares them with go.sum, tools.lock and the checksum database.
+func runCacheVerify(e *env, args []string) error {
+	fs := newFlagSet(e, "cache verify")
+	asJSON := fs.Bool("json", false, "print the result as JSON")
+	useSumDB := fs.Bool("sumdb", true, "check against the local snapshot of "+modcache.SumDBName)
+	paths, err := parseArgs(fs, args)
+	if err != nil {
+		return err
+	}
+	root, err := e.checkout()
+	if err != nil {
+		return err
+	}
+	mods, err := modcache.List(modcache.Dir(root))
+	if err != nil {
+		return err
+	}
+	if len(paths) > 0 {
+		var picked []*modcache.Module
+		for _, p := range paths {
+			m := findModule(mods, p)
+			if m == nil {
+				return fmt.Errorf("%s is not in the cache", p)
+			}
+			picked = append(picked, m)
+		}
+		mods = picked
+	}
+
+	known := modcache.KnownSums{}
+	gomods, err := modcache.FindGoMods(root)
+	if err != nil {
+		return err
+	}
+	for _, name := range gomods {
+		gosum := filepath.Join(filepath.Dir(name), "go.sum")
+		err := known.ReadGoSum(filepath.ToSlash(gosum), filepath.Join(root, gosum))
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+	}
+	tools, err := devenv.ReadLock(filepath.Join(root, devenv.LockFile))
+	if err != nil {
+		return err
+	}
+	for _, t := range tools {
+		known.Add(devenv.LockFile, t.Module, t.Version, t.Sum)
+	}
+	var db *modcache.SumDB
+	if *useSumDB {
+		db = modcache.OpenSumDB(root)
+	}
+
+	var results []*modcache.Verification
+	count := map[string]int{}
+	for _, m := range mods {
+		for _, v := range m.Versions {
+			var dbErr error
+			if db != nil {
+				lines, err := db.Lookup(v.Module())
+				if err == nil {
+					known.AddLines(modcache.SumDBName, lines)
+				}
+				dbErr = err
+			}
+			res := modcache.Verify(v, known)
+			switch {
+			case dbErr == nil:
+			case errors.Is(dbErr, modcache.ErrNotInSnapshot):
+				res.Notes = append(res.Notes, dbErr.Error())
+			default:
+				// the snapshot has a record, but it does not check out
+				res.Errors = append(res.Errors, modcache.SumDBName+": "+dbErr.Error())
+			}
+			count[res.Status()]++
+			results = append(results, res)
+		}
+	}
+
+	if *asJSON {
+		enc := json.NewEncoder(e.stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(results); err != nil {
+			return err
+		}
+	} else {
+		tw := tabwriter.NewWriter(e.stdout, 0, 0, 2, ' ', 0)
+		for _, res := range results {
+			fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", res.Status(), res.Path, res.Version, verifyDetail(res))
+		}
+		if err := tw.Flush(); err != nil {
+			return err
+		}
+		fmt.Fprintf(e.stdout, "\n%d versions: %d %s, %d %s, %d %s\n", len(results),
+			count[modcache.Verified], modcache.Verified, count[modcache.Unverified], modcache.Unverified,
+			count[modcache.Mismatch], modcache.Mismatch)
+	}
+	if n := count[modcache.Mismatch]; n > 0 {
+		return fmt.Errorf("%d of %d versions do not match their checksums", n, len(results))
+	}
+	return nil
+}
+
+// verifyDetail sums up a verification in a line: what failed, or what
+// was checked against which sources.
+func verifyDetail(res *modcache.Verification) string {
+	var failed []string
+	for _, c := range res.Checks {
+		if !c.OK() {
+			failed = append(failed, fmt.Sprintf("%s is %s, %s has %s", c.File, c.Got, c.Source, c.Want))
+		}
+	}
+	failed = append(failed, res.Errors...)
+	if len(failed) > 0 {
+		return strings.Join(failed, "; ")
+	}
+	var files, sources []string
+	for _, c := range res.Checks {
+		if !slices.Contains(files, c.File) {
+			files = append(files, c.File)
+		}
+		if !slices.Contains(sources, c.Source) {
+			sources = append(sources, c.Source)
+		}
+	}
+	if len(files) == 0 {
+		return strings.Join(res.Notes, "; ")
+	}
+	return strings.Join(files, ", ") + " match " + strings.Join(sources, ", ")
+}
+
+// findModule returns the module with the path, nil if there is none.
+func findModule(mods []*modcache.Module, path string) *modcache.Module {
+	for _, m := range mods {
+		if m.Path == path {
+			return m
+		}
+	}
+	return nil
+}
+
+// formatSize formats a size in bytes with a binary unit, e.g. "1.5M".
+func formatSize(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%dB", n)
+	}
+	f, units := float64(n)/unit, "KMGT"
+	for f >= unit && len(units) > 1 {
+		f /= unit
+		units = units[1:]
+	}
+	return fmt.Sprintf("%.1f%c", f, units[0])
+}
@@ -0,0 +1,59 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"text/tabwriter"
+
+	"gbdmp/learningo/devenv"
+)
+
+// runDoctor checks the environment and lists the fixes for what failed.
+func runDoctor(e *env, args []string) error {
+	fs := newFlagSet(e, "doctor")
+	asJSON := fs.Bool("json", false, "print the result as JSON")
+	pos, err := parseArgs(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(pos) != 0 {
+		return errUsage
+	}
+	root, err := e.checkout()
+	if err != nil {
+		return err
+	}
+	rep := devenv.Doctor(root)
+
+	if *asJSON {
+		enc := json.NewEncoder(e.stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(rep); err != nil {
+			return err
+		}
+	} else {
+		fmt.Fprintf(e.stdout, "checkout %s\n\n", rep.Root)
+		tw := tabwriter.NewWriter(e.stdout, 0, 0, 2, ' ', 0)
+		for _, c := range rep.Checks {
+			fmt.Fprintf(tw, "%s\t%s\t%s\n", c.Status, c.Name, c.Detail)
+		}
+		if err := tw.Flush(); err != nil {
+			return err
+		}
+		first := true
+		for _, c := range rep.Checks {
+			if c.Fix == "" || c.Status == devenv.OK {
+				continue
+			}
+			if first {
+				fmt.Fprintln(e.stdout, "\nto fix:")
+				first = false
+			}
+			fmt.Fprintf(e.stdout, "  %s: %s\n", c.Name, c.Fix)
+		}
+	}
+	if n := rep.Failed(); n > 0 {
+		return fmt.Errorf("%d of %d checks failed", n, len(rep.Checks))
+	}
+	return nil
+}
@@ -0,0 +1,98 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"gbdmp/learningo/devenv"
+)
+
+// setUp makes the environment of the test that of a go_dev checkout without
+// tools, with a fake go command, and returns the checkout.
+func setUp(t *testing.T) string {
+	if runtime.GOOS == "windows" {
+		t.Skip("the fake go command is a shell script")
+	}
+	home := t.TempDir()
+	root := filepath.Join(home, "go_dev")
+	if err := os.MkdirAll(filepath.Join(root, devenv.ModDir), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(filepath.Join(root, "bin"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(root, devenv.ModDir, "go.mod"), []byte("module gbdmp/learningo\n\ngo 1.21.2\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	gobin := t.TempDir()
+	script := "#!/bin/sh\nprintf 'go1.21.2\\n%s\\n' \"$GOPATH\"\n"
+	if err := os.WriteFile(filepath.Join(gobin, "go"), []byte(script), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("HOME", home)
+	t.Setenv("SHELL", "/bin/bash")
+	t.Setenv("GOPATH", root)
+	t.Setenv("PATH", gobin+string(filepath.ListSeparator)+filepath.Join(root, "bin"))
+	return root
+}
+
+func TestDoctor(t *testing.T) {
+	root := setUp(t)
+	var stdout, stderr bytes.Buffer
+	if code := run([]string{"-root", root, "doctor"}, &stdout, &stderr); code != 1 {
+		t.Fatalf("doctor = %d, want 1: %s", code, &stderr)
+	}
+	if want := "godev doctor: 5 of 9 checks failed\n"; stderr.String() != want {
+		t.Errorf("stderr = %q, want %q", &stderr, want)
+	}
+	out := stdout.String()
+	for _, want := range []string{
+		"checkout " + root + "\n\n",
+		"ok    GOPATH               " + root + "\n",
+		"fail  vim-go motion        " + filepath.Join(root, "bin", "motion") + " is missing\n",
+		"\nto fix:\n  vim-go asmfmt: run 'godev tools sync asmfmt'\n",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("doctor output does not contain %q:\n%s", want, out)
+		}
+	}
+	if strings.Contains(out, "  GOPATH: ") {
+		t.Errorf("doctor lists a fix for a check that passed:\n%s", out)
+	}
+
+	// without GOPATH in the environment the go command reports none
+	t.Setenv("GOPATH", "")
+	stdout.Reset()
+	run([]string{"-root", root, "doctor"}, &stdout, &stderr)
+	if want := "  GOPATH: add 'export GOPATH=$HOME/go_dev' to ~/.bashrc\n"; !strings.Contains(stdout.String(), want) {
+		t.Errorf("doctor without GOPATH does not contain %q:\n%s", want, &stdout)
+	}
+}
+
+func TestDoctorJSON(t *testing.T) {
+	root := setUp(t)
+	var stdout, stderr bytes.Buffer
+	if code := run([]string{"-root", root, "doctor", "-json"}, &stdout, &stderr); code != 1 {
+		t.Fatalf("doctor -json = %d, want 1: %s", code, &stderr)
+	}
+	var rep devenv.Report
+	dec := json.NewDecoder(&stdout)
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&rep); err != nil {
+		t.Fatal(err)
+	}
+	if rep.Root != root || rep.OK || len(rep.Checks) != 9 || rep.Failed() != 5 {
+		t.Errorf("doctor -json = %+v", rep)
+	}
+	if c := rep.Checks[0]; c.Name != "go" || c.Status != devenv.OK || c.Fix != "" {
+		t.Errorf("first check = %+v, want go ok", c)
+	}
+	if code := run([]string{"-root", root, "doctor", "extra"}, &stdout, &stderr); code != 2 {
+		t.Errorf("doctor with an argument = %d, want 2", code)
+	}
+}
@@ -0,0 +1,136 @@
+// Command godev checks and maintains the go_dev development environment:
+// the Go installation, GOPATH, the editor tools in bin and the module
+// cache in pkg/mod.
+//
+// Usage:
+//
+//	godev [-root DIR] COMMAND [ARGS]
+//
+// Run "godev help" for the list of commands.
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"gbdmp/learningo/internal/modroot"
+)
+
+// command is a godev subcommand.
+type command struct {
+	name    string
+	args    string
+	summary string
+	run     func(env *env, args []string) error
+}
+
+// env is what commands get to work with.
+type env struct {
+	stdout io.Writer
+	stderr io.Writer
+	root   string // the checkout, "" to find it from the working directory
+}
+
+var commands []*command
+
+func init() {
+	commands = []*command{
+		{"doctor", "[-json]", "check Go, GOPATH, PATH and the vim-go tools against the README", runDoctor},
+		{"setup", "[-dry-run] [-shell bash|zsh] [-home DIR]", "write GOPATH and PATH into the shell startup file and the .vimrc of the README", runSetup},
+		{"tools", "sync [-force] [NAME...] | verify [-json] [NAME...]", "rebuild the editor tools in bin from tools.lock and pkg/mod, or check them against it", runTools},
+		{"cache", "ls [-json] [-unreferenced] [MODULE...] | why MODULE[@VERSION]... | prune -keep-referenced [-delete] | verify [-json] [-sumdb=false] [MODULE...]", "list the module cache in pkg/mod, show why versions are in it, remove the unreferenced ones or verify their checksums", runCache},
+		{"proxy", "[-addr ADDR] [-upstream URL] [-q]", "serve the module cache in pkg/mod to other machines as a GOPROXY", runProxy},
+		{"help", "", "show this help", runHelp},
+	}
+}
+
+// errUsage is returned by commands called with bad arguments; main
+// answers it with exit status 2.
+var errUsage = errors.New("usage")
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdout, os.Stderr))
+}
+
+func run(args []string, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("godev", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	root := fs.String("root", "", "the go_dev checkout `directory` (default: the one around the working directory)")
+	fs.Usage = func() { usage(stderr) }
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if fs.NArg() == 0 {
+		usage(stderr)
+		return 2
+	}
+
+	name := fs.Arg(0)
+	for _, c := range commands {
+		if c.name != name {
+			continue
+		}
+		err := c.run(&env{stdout: stdout, stderr: stderr, root: *root}, fs.Args()[1:])
+		switch {
+		case err == nil:
+			return 0
+		case errors.Is(err, flag.ErrHelp):
+			return 0
+		case errors.Is(err, errUsage):
+			fmt.Fprintf(stderr, "usage: godev %s %s\n", c.name, c.args)
+			return 2
+		}
+		fmt.Fprintf(stderr, "godev %s: %v\n", name, err)
+		return 1
+	}
+	fmt.Fprintf(stderr, "godev: unknown command %q\n", name)
+	usage(stderr)
+	return 2
+}
+
+func usage(w io.Writer) {
+	fmt.Fprintln(w, "usage: godev [-root DIR] COMMAND [ARGS]")
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "commands:")
+	for _, c := range commands {
+		fmt.Fprintf(w, "  %-8s %s\n", c.name, c.summary)
+	}
+}
+
+func runHelp(e *env, args []string) error {
+	usage(e.stdout)
+	return nil
+}
+
+// parseArgs parses flags that may appear before, between or after the
+// positional arguments and returns the positional arguments.
+func parseArgs(fs *flag.FlagSet, args []string) ([]string, error) {
+	var pos []string
+	for {
+		if err := fs.Parse(args); err != nil {
+			return nil, err
+		}
+		if fs.NArg() == 0 {
+			return pos, nil
+		}
+		pos = append(pos, fs.Arg(0))
+		args = fs.Args()[1:]
+	}
+}
+
+func newFlagSet(e *env, name string) *flag.FlagSet {
+	fs := flag.NewFlagSet("godev "+name, flag.ContinueOnError)
+	fs.SetOutput(e.stderr)
+	return fs
+}
+
+// checkout returns the root of the go_dev checkout.
+func (e *env) checkout() (string, error) {
+	if e.root != "" {
+		return e.root, nil
+	}
+	return modroot.Checkout(".")
+}
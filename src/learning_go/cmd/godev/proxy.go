@@ -0,0 +1,99 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"gbdmp/learningo/goproxy"
+	"gbdmp/learningo/modcache"
+)
+
+// shutdownTimeout is how long proxy waits for requests in flight after
+// SIGINT or SIGTERM.
+const shutdownTimeout = 10 * time.Second
+
+// runProxy serves the module cache of the checkout as a GOPROXY.
+func runProxy(e *env, args []string) error {
+	fs := newFlagSet(e, "proxy")
+	addr := fs.String("addr", "127.0.0.1:3000", "listen on this `address`")
+	upstream := fs.String("upstream", "", "fetch what the cache is missing from this GOPROXY `list` into the cache (default: serve the cache as it is)")
+	quiet := fs.Bool("q", false, "do not log the requests")
+	pos, err := parseArgs(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(pos) != 0 {
+		return errUsage
+	}
+	root, err := e.checkout()
+	if err != nil {
+		return err
+	}
+	dir := modcache.Dir(root)
+	var handler http.Handler = goproxy.NewServer(dir, *upstream)
+	if !*quiet {
+		handler = logRequests(e, handler)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	srv := &http.Server{
+		Addr:              *addr,
+		Handler:           handler,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	errc := make(chan error, 1)
+	go func() { errc <- srv.ListenAndServe() }()
+	mode := "read-only"
+	if *upstream != "" {
+		mode = "fetching misses from " + *upstream
+	}
+	fmt.Fprintf(e.stderr, "godev: serving %s on http://%s, %s\n", modcache.DownloadDir(dir), *addr, mode)
+	fmt.Fprintf(e.stderr, "godev: use it with GOPROXY=http://%s\n", *addr)
+
+	select {
+	case err := <-errc:
+		return err
+	case <-ctx.Done():
+	}
+	fmt.Fprintln(e.stderr, "godev: shutting down")
+	sctx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+	if err := srv.Shutdown(sctx); err != nil {
+		return err
+	}
+	if err := <-errc; !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
+
+// statusWriter records the status of a response.
+type statusWriter struct {
+	http.ResponseWriter
+	status int
+}
+
+func (w *statusWriter) WriteHeader(status int) {
+	w.status = status
+	w.ResponseWriter.WriteHeader(status)
+}
+
+// logRequests logs every request with its status and duration to stderr.
+func logRequests(e *env, h http.Handler) http.Handler {
+	logger := log.New(e.stderr, "", log.LstdFlags)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		sw := &statusWriter{ResponseWriter: w, status: http.StatusOK}
+		h.ServeHTTP(sw, r)
+		logger.Printf("%s %s %d %s", r.Method, r.URL.Path, sw.status, time.Since(start).Round(time.Millisecond))
+	})
+}
@@ -0,0 +1,74 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"time"
+
+	"gbdmp/learningo/devenv"
+)
+
+// runSetup writes the managed blocks into the shell startup file and
+// .vimrc.
+func runSetup(e *env, args []string) error {
+	fs := newFlagSet(e, "setup")
+	dryRun := fs.Bool("dry-run", false, "only print the changes as a unified diff")
+	shell := fs.String("shell", devenv.Shell(), "the `shell` to set up: bash or zsh")
+	home := fs.String("home", "", "the home `directory` (default: the current user's)")
+	pos, err := parseArgs(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(pos) != 0 {
+		return errUsage
+	}
+	root, err := e.checkout()
+	if err != nil {
+		return err
+	}
+	if *home == "" {
+		if *home, err = os.UserHomeDir(); err != nil {
+			return err
+		}
+	}
+	gobin := "/usr/local/go/bin"
+	if p, err := exec.LookPath("go"); err == nil {
+		gobin = filepath.Dir(p)
+	}
+	changes, err := devenv.Plan(devenv.SetupOptions{Home: *home, Shell: *shell, Root: root, GoBin: gobin})
+	if err != nil {
+		return err
+	}
+
+	if *dryRun {
+		for _, c := range changes {
+			if c.Changed() {
+				fmt.Fprint(e.stdout, c.Diff())
+			} else {
+				fmt.Fprintf(e.stdout, "%s is up to date\n", c.Path)
+			}
+		}
+		return nil
+	}
+	backups, err := devenv.Apply(changes, time.Now())
+	for _, b := range backups {
+		fmt.Fprintf(e.stdout, "backed up to %s\n", b)
+	}
+	if err != nil {
+		return err
+	}
+	for _, c := range changes {
+		switch {
+		case !c.Changed():
+			fmt.Fprintf(e.stdout, "%s is up to date\n", c.Path)
+		case c.Exists:
+			fmt.Fprintf(e.stdout, "updated %s\n", c.Path)
+		default:
+			fmt.Fprintf(e.stdout, "created %s\n", c.Path)
+		}
+	}
+	fmt.Fprintf(e.stdout, "open a new shell or run 'source %s', then 'godev doctor'\n", changes[0].Path)
+	return nil
+}
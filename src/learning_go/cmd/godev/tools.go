@@ -0,0 +1,139 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+
+	"gbdmp/learningo/devenv"
+)
+
+// runTools dispatches "godev tools sync" and "godev tools verify".
+func runTools(e *env, args []string) error {
+	if len(args) == 0 {
+		return errUsage
+	}
+	switch args[0] {
+	case "sync":
+		return runToolsSync(e, args[1:])
+	case "verify":
+		return runToolsVerify(e, args[1:])
+	}
+	return errUsage
+}
+
+// lockedTools reads tools.lock of the checkout and returns the tools
+// named, all of them if names is empty.
+func lockedTools(root string, names []string) ([]devenv.LockedTool, error) {
+	tools, err := devenv.ReadLock(filepath.Join(root, devenv.LockFile))
+	if err != nil {
+		return nil, err
+	}
+	if len(names) == 0 {
+		return tools, nil
+	}
+	var picked []devenv.LockedTool
+	for _, name := range names {
+		found := false
+		for _, t := range tools {
+			if t.Name == name {
+				picked = append(picked, t)
+				found = true
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("%s is not in %s", name, devenv.LockFile)
+		}
+	}
+	return picked, nil
+}
+
+// runToolsSync rebuilds the tools whose binaries do not match tools.lock.
+func runToolsSync(e *env, args []string) error {
+	fs := newFlagSet(e, "tools sync")
+	force := fs.Bool("force", false, "rebuild tools that match tools.lock, too")
+	names, err := parseArgs(fs, args)
+	if err != nil {
+		return err
+	}
+	root, err := e.checkout()
+	if err != nil {
+		return err
+	}
+	tools, err := lockedTools(root, names)
+	if err != nil {
+		return err
+	}
+	failed := 0
+	for _, t := range tools {
+		s := devenv.InspectTool(filepath.Join(root, "bin", t.Name), t)
+		if s.OK() && !*force {
+			fmt.Fprintf(e.stdout, "%s: up to date, %s %s\n", t.Name, t.Module, t.Version)
+			continue
+		}
+		if _, err := devenv.BuildTool(context.Background(), root, t); err != nil {
+			fmt.Fprintf(e.stderr, "%s: %v\n", t.Name, err)
+			failed++
+			continue
+		}
+		fmt.Fprintf(e.stdout, "%s: built %s %s\n", t.Name, t.Module, t.Version)
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %d tools failed to build", failed, len(tools))
+	}
+	return nil
+}
+
+// runToolsVerify compares the binaries in bin with tools.lock.
+func runToolsVerify(e *env, args []string) error {
+	fs := newFlagSet(e, "tools verify")
+	asJSON := fs.Bool("json", false, "print the result as JSON")
+	names, err := parseArgs(fs, args)
+	if err != nil {
+		return err
+	}
+	root, err := e.checkout()
+	if err != nil {
+		return err
+	}
+	tools, err := lockedTools(root, names)
+	if err != nil {
+		return err
+	}
+	var states []devenv.ToolState
+	drifted := 0
+	for _, t := range tools {
+		s := devenv.InspectTool(filepath.Join(root, "bin", t.Name), t)
+		if !s.OK() {
+			drifted++
+		}
+		states = append(states, s)
+	}
+
+	if *asJSON {
+		enc := json.NewEncoder(e.stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(states); err != nil {
+			return err
+		}
+	} else {
+		tw := tabwriter.NewWriter(e.stdout, 0, 0, 2, ' ', 0)
+		for _, s := range states {
+			status, detail := "ok", fmt.Sprintf("%s %s, built with %s", s.Tool.Module, s.Tool.Version, s.GoVersion)
+			if !s.OK() {
+				status, detail = "drift", strings.Join(s.Drift, "; ")
+			}
+			fmt.Fprintf(tw, "%s\t%s\t%s\n", status, s.Tool.Name, detail)
+		}
+		if err := tw.Flush(); err != nil {
+			return err
+		}
+	}
+	if drifted > 0 {
+		return fmt.Errorf("%d of %d tools do not match %s, run 'godev tools sync'", drifted, len(tools), devenv.LockFile)
+	}
+	return nil
+}
@@ -0,0 +1,318 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+
+	"gbdmp/learningo/calendar"
+	"gbdmp/learningo/milestones"
+	"gbdmp/learningo/people"
+)
+
+// dateFlag is a flag.Value holding a date accepted by people.ParseDate.
+type dateFlag struct {
+	people.Date
+}
+
+func (d *dateFlag) Set(s string) error {
+	parsed, err := people.ParseDate(s)
+	if err != nil {
+		return err
+	}
+	d.Date = parsed
+	return nil
+}
+
+func (d *dateFlag) String() string {
+	if d == nil || d.IsZero() {
+		return "today"
+	}
+	return d.Date.String()
+}
+
+// on returns the date given with the flag or today.
+func (d *dateFlag) on() people.Date {
+	if d.IsZero() {
+		return people.Today()
+	}
+	return d.Date
+}
+
+func leapFlag(fs *flag.FlagSet) *string {
+	return fs.String("leap", calendar.Feb28.String(), "when to celebrate 29 February in other years: feb28, mar1 or leap-only")
+}
+
+func runAdd(e *env, args []string) error {
+	fs := newFlagSet(e, "add")
+	pos, err := parseArgs(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(pos) != 2 {
+		return errUsage
+	}
+	d, err := people.ParseDate(pos[1])
+	if err != nil {
+		return err
+	}
+	r, err := e.openRegistry()
+	if err != nil {
+		return err
+	}
+	return r.Add(people.Person{Name: pos[0], Birthday: d})
+}
+
+func runRemove(e *env, args []string) error {
+	fs := newFlagSet(e, "remove")
+	pos, err := parseArgs(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(pos) != 1 {
+		return errUsage
+	}
+	r, err := e.openRegistry()
+	if err != nil {
+		return err
+	}
+	return r.Remove(pos[0])
+}
+
+type personRow struct {
+	Name     string      `json:"name"`
+	Birthday people.Date `json:"birthday"`
+	Age      int         `json:"age"`
+}
+
+func runList(e *env, args []string) error {
+	fs := newFlagSet(e, "list")
+	by := fs.String("sort", "name", "sort by `key`: name, age or birthday (day of the year)")
+	format := formatFlag(fs)
+	pos, err := parseArgs(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(pos) != 0 {
+		return errUsage
+	}
+	r, err := e.openRegistry()
+	if err != nil {
+		return err
+	}
+
+	list := r.List()
+	today := people.Today()
+	switch *by {
+	case "name":
+	case "age":
+		sort.SliceStable(list, func(i, j int) bool { return list[i].AgeOn(today) < list[j].AgeOn(today) })
+	case "birthday":
+		sort.SliceStable(list, func(i, j int) bool {
+			a, b := list[i].Birthday, list[j].Birthday
+			if a.Month != b.Month {
+				return a.Month < b.Month
+			}
+			return a.Day < b.Day
+		})
+	default:
+		return fmt.Errorf("unknown sort key %q, want name, age or birthday", *by)
+	}
+
+	t := &table{header: []string{"Name", "Birthday", "Age"}}
+	rows := make([]personRow, 0, len(list))
+	for _, p := range list {
+		row := personRow{Name: p.Name, Birthday: p.Birthday, Age: p.AgeOn(today)}
+		rows = append(rows, row)
+		t.add(row.Name, row.Birthday.String(), strconv.Itoa(row.Age))
+	}
+	t.value = rows
+	return t.write(e.stdout, *format)
+}
+
+type ageRow struct {
+	Name     string      `json:"name"`
+	Birthday people.Date `json:"birthday"`
+	On       people.Date `json:"on"`
+	Age      int         `json:"age"`
+}
+
+func runAge(e *env, args []string) error {
+	fs := newFlagSet(e, "age")
+	var on dateFlag
+	fs.Var(&on, "on", "compute the age on this `date`")
+	format := formatFlag(fs)
+	pos, err := parseArgs(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(pos) != 1 {
+		return errUsage
+	}
+	p, err := e.lookup(pos[0])
+	if err != nil {
+		return err
+	}
+	row := ageRow{Name: p.Name, Birthday: p.Birthday, On: on.on(), Age: p.AgeOn(on.on())}
+	t := &table{header: []string{"Name", "Birthday", "On", "Age"}, value: row}
+	t.add(row.Name, row.Birthday.String(), row.On.String(), strconv.Itoa(row.Age))
+	return t.write(e.stdout, *format)
+}
+
+type milestoneRow struct {
+	Name  string `json:"name"`
+	Label string `json:"label"`
+	Age   int    `json:"age"`
+	// Date is when the milestone is reached; it is omitted for milestones
+	// that have been reached already.
+	Date *people.Date `json:"date,omitempty"`
+}
+
+type milestonesReport struct {
+	Name    string         `json:"name"`
+	On      people.Date    `json:"on"`
+	Age     int            `json:"age"`
+	Reached []milestoneRow `json:"reached"`
+	Next    []milestoneRow `json:"next"`
+}
+
+func runMilestones(e *env, args []string) error {
+	fs := newFlagSet(e, "milestones")
+	var on dateFlag
+	fs.Var(&on, "on", "evaluate the milestones on this `date`")
+	rulesFile := fs.String("rules", "", "load the rules from this YAML or JSON `file` instead of the built-in ones")
+	jurisdiction := fs.String("jurisdiction", "", "use the rules of this jurisdiction (default: the rule set's default)")
+	format := formatFlag(fs)
+	pos, err := parseArgs(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(pos) != 1 {
+		return errUsage
+	}
+	p, err := e.lookup(pos[0])
+	if err != nil {
+		return err
+	}
+	rules, err := loadRules(*rulesFile, *jurisdiction)
+	if err != nil {
+		return err
+	}
+
+	rep := rules.Evaluate(p, on.on())
+	out := milestonesReport{Name: p.Name, On: rep.On, Age: rep.Age, Reached: []milestoneRow{}, Next: []milestoneRow{}}
+	t := &table{header: []string{"Milestone", "Age", "Status"}}
+	for _, r := range rep.Reached {
+		out.Reached = append(out.Reached, milestoneRow{Name: r.Name, Label: r.String(), Age: r.Since(rep.Age)})
+		t.add(r.String(), strconv.Itoa(r.Since(rep.Age)), "reached")
+	}
+	if rep.Next != nil {
+		for _, r := range rep.Next.Rules {
+			date := rep.Next.Date
+			out.Next = append(out.Next, milestoneRow{Name: r.Name, Label: r.String(), Age: rep.Next.Age, Date: &date})
+			t.add(r.String(), strconv.Itoa(rep.Next.Age), "next, on "+date.String())
+		}
+	}
+	t.value = out
+	return t.write(e.stdout, *format)
+}
+
+func loadRules(file, jurisdiction string) (milestones.Rules, error) {
+	set := milestones.Default()
+	if file != "" {
+		var err error
+		if set, err = milestones.Load(file); err != nil {
+			return nil, err
+		}
+	}
+	return set.Rules(jurisdiction)
+}
+
+type upcomingRow struct {
+	Name  string      `json:"name"`
+	Kind  string      `json:"kind"`
+	On    people.Date `json:"on"`
+	Turns int         `json:"turns"`
+	Days  int         `json:"days"`
+}
+
+func runUpcoming(e *env, args []string) error {
+	fs := newFlagSet(e, "upcoming")
+	days := fs.Int("days", 30, "look this many `days` ahead")
+	var from dateFlag
+	fs.Var(&from, "from", "start looking on this `date`")
+	leap := leapFlag(fs)
+	format := formatFlag(fs)
+	pos, err := parseArgs(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(pos) != 0 || *days < 0 {
+		return errUsage
+	}
+	policy, err := calendar.ParseLeapPolicy(*leap)
+	if err != nil {
+		return err
+	}
+	r, err := e.openRegistry()
+	if err != nil {
+		return err
+	}
+
+	t := &table{header: []string{"Date", "Name", "Turns", "In days"}}
+	rows := []upcomingRow{}
+	for _, o := range calendar.FromRegistry(r, policy).Upcoming(from.on(), *days) {
+		rows = append(rows, upcomingRow{Name: o.Name, Kind: o.Kind, On: o.On, Turns: o.Years, Days: o.Days})
+		t.add(o.On.String(), o.Name, strconv.Itoa(o.Years), strconv.Itoa(o.Days))
+	}
+	t.value = rows
+	return t.write(e.stdout, *format)
+}
+
+func runICS(e *env, args []string) error {
+	fs := newFlagSet(e, "ics")
+	out := fs.String("o", "", "write to this `file` instead of standard output")
+	leap := leapFlag(fs)
+	pos, err := parseArgs(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(pos) != 0 {
+		return errUsage
+	}
+	policy, err := calendar.ParseLeapPolicy(*leap)
+	if err != nil {
+		return err
+	}
+	r, err := e.openRegistry()
+	if err != nil {
+		return err
+	}
+	cal := calendar.FromRegistry(r, policy)
+	if *out == "" {
+		return cal.WriteICS(e.stdout, people.Now())
+	}
+	f, err := os.Create(*out)
+	if err != nil {
+		return err
+	}
+	if err := cal.WriteICS(f, people.Now()); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+func (e *env) lookup(name string) (people.Person, error) {
+	r, err := e.openRegistry()
+	if err != nil {
+		return people.Person{}, err
+	}
+	p, ok := r.Lookup(name)
+	if !ok {
+		return people.Person{}, fmt.Errorf("%w: %s", people.ErrNotFound, name)
+	}
+	return p, nil
+}
@@ -0,0 +1,116 @@
+// Package devenv checks and sets up the development environment the README
+// describes: Go on the PATH in the version go.mod asks for, GOPATH set to
+// the go_dev checkout with its bin directory on the PATH, and the helper
+// binaries of vim-go in bin.
+package devenv
+
+import (
+	"bufio"
+	"bytes"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// ModDir is the directory of the gbdmp/learningo module in the checkout.
+var ModDir = filepath.Join("src", "learning_go")
+
+// Tool is a helper binary of vim-go that lives in bin.
+type Tool struct {
+	Name    string
+	Package string // the main package, for go install
+}
+
+// VimGoTools are the binaries vim-go needs in bin.
+var VimGoTools = []Tool{
+	{"asmfmt", "github.com/klauspost/asmfmt/cmd/asmfmt"},
+	{"gomodifytags", "github.com/fatih/gomodifytags"},
+	{"gotags", "github.com/jstemmer/gotags"},
+	{"iferr", "github.com/koron/iferr"},
+	{"motion", "github.com/fatih/motion"},
+}
+
+// GoDirective returns the version of the go directive in the go.mod file
+// at path, e.g. "1.21.2".
+func GoDirective(path string) (string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	sc := bufio.NewScanner(bytes.NewReader(data))
+	for sc.Scan() {
+		f := strings.Fields(sc.Text())
+		if len(f) == 2 && f[0] == "go" {
+			return f[1], nil
+		}
+	}
+	return "", nil
+}
+
+// CompareVersions compares two Go versions like "1.21.2" or "go1.22rc1"
+// by their release numbers: -1 if a is older than b, 0 if they are the
+// same release and +1 if a is newer. Missing numbers count as 0, and a
+// prerelease like go1.22rc1 is older than the release go1.22.0.
+func CompareVersions(a, b string) int {
+	x, xpre := versionNumbers(a)
+	y, ypre := versionNumbers(b)
+	for i := 0; i < 3; i++ {
+		switch {
+		case x[i] < y[i]:
+			return -1
+		case x[i] > y[i]:
+			return +1
+		}
+	}
+	switch {
+	case xpre && !ypre:
+		return -1
+	case !xpre && ypre:
+		return +1
+	}
+	return 0
+}
+
+// versionNumbers returns the release numbers of v and whether v is a
+// prerelease, a version with text after its last number like "rc1".
+func versionNumbers(v string) (n [3]int, pre bool) {
+	v = strings.TrimPrefix(v, "go")
+	for i, part := range strings.SplitN(v, ".", 3) {
+		end := 0
+		for end < len(part) && part[end] >= '0' && part[end] <= '9' {
+			end++
+		}
+		n[i], _ = strconv.Atoi(part[:end])
+		pre = end < len(part)
+	}
+	return n, pre
+}
+
+// Shell returns the name of the login shell, e.g. "zsh", from $SHELL.
+func Shell() string {
+	return filepath.Base(os.Getenv("SHELL"))
+}
+
+// RCFile returns the startup file of the shell in the home directory:
+// .zshrc for zsh and .bashrc for everything else.
+func RCFile(home, shell string) string {
+	if shell == "zsh" {
+		return filepath.Join(home, ".zshrc")
+	}
+	return filepath.Join(home, ".bashrc")
+}
+
+// sameFile reports whether two paths name the same existing file or
+// directory.
+func sameFile(a, b string) bool {
+	fa, err := os.Stat(a)
+	if err != nil {
+		return false
+	}
+	fb, err := os.Stat(b)
+	if err != nil {
+		return false
+	}
+	return os.SameFile(fa, fb)
+}
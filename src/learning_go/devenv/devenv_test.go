@@ -0,0 +1,61 @@
+package devenv_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"gbdmp/learningo/devenv"
+)
+
+func TestCompareVersions(t *testing.T) {
+	for _, tt := range []struct {
+		a, b string
+		want int
+	}{
+		{"go1.21.2", "1.21.2", 0},
+		{"1.21.2", "1.21.10", -1},
+		{"go1.22.0", "1.21.2", +1},
+		{"go1.21", "1.21.0", 0},
+		{"go1.21.1", "1.21", +1},
+		{"go1.22rc1", "1.22.0", -1},
+		{"go1.22rc1", "1.21.2", +1},
+		{"go1.22rc1", "go1.22rc1", 0},
+		{"go1.21rc2", "1.21", -1},
+		{"1.22.0", "go1.22beta1", +1},
+		{"go2", "1.99.9", +1},
+		{"", "1.21", -1},
+	} {
+		if got := devenv.CompareVersions(tt.a, tt.b); got != tt.want {
+			t.Errorf("CompareVersions(%q, %q) = %d, want %d", tt.a, tt.b, got, tt.want)
+		}
+		if got := devenv.CompareVersions(tt.b, tt.a); got != -tt.want {
+			t.Errorf("CompareVersions(%q, %q) = %d, want %d", tt.b, tt.a, got, -tt.want)
+		}
+	}
+}
+
+func TestGoDirective(t *testing.T) {
+	dir := t.TempDir()
+	for _, tt := range []struct {
+		name, gomod, want string
+	}{
+		{"release", "module gbdmp/learningo\n\ngo 1.21.2\n", "1.21.2"},
+		{"language version", "module m\ngo 1.21\ntoolchain go1.22.0\n", "1.21"},
+		{"indented", "module m\n\n  go 1.20  \n", "1.20"},
+		{"none", "module m\n\nrequire example.com/go v1.0.0\n", ""},
+		{"in a comment", "module m // go 1.19\n", ""},
+		{"empty", "", ""},
+	} {
+		path := filepath.Join(dir, tt.name+".mod")
+		if err := os.WriteFile(path, []byte(tt.gomod), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		if got, err := devenv.GoDirective(path); err != nil || got != tt.want {
+			t.Errorf("%s: GoDirective = %q, %v, want %q", tt.name, got, err, tt.want)
+		}
+	}
+	if _, err := devenv.GoDirective(filepath.Join(dir, "missing")); err == nil {
+		t.Error("GoDirective of a missing file succeeds")
+	}
+}
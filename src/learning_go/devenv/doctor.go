@@ -0,0 +1,201 @@
+package devenv
+
+import (
+	"debug/buildinfo"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// Status is the outcome of a check.
+type Status string
+
+const (
+	OK   Status = "ok"
+	Warn Status = "warn" // works, but not as the README sets it up
+	Fail Status = "fail"
+)
+
+// Check is one step of the setup, checked.
+type Check struct {
+	Name   string `json:"name"`
+	Status Status `json:"status"`
+	Detail string `json:"detail"`
+	// Fix is what to do about a check that did not pass.
+	Fix string `json:"fix,omitempty"`
+}
+
+// Report is the outcome of Doctor.
+type Report struct {
+	Root   string  `json:"root"`
+	OK     bool    `json:"ok"` // no check failed
+	Checks []Check `json:"checks"`
+}
+
+// Failed returns the number of failed checks.
+func (r *Report) Failed() int {
+	n := 0
+	for _, c := range r.Checks {
+		if c.Status == Fail {
+			n++
+		}
+	}
+	return n
+}
+
+// Doctor checks the environment of this process against the setup of the
+// checkout in root.
+func Doctor(root string) *Report {
+	return DoctorEnv(root, os.Environ())
+}
+
+// DoctorEnv is Doctor for the environment env, a list of key=value pairs
+// like os.Environ. PATH, GOPATH, HOME and SHELL are read from it, and go
+// runs with it.
+func DoctorEnv(root string, env []string) *Report {
+	d := &doctor{Report: Report{Root: root}, env: env}
+	d.rc = "~/" + filepath.Base(RCFile("", filepath.Base(d.getenv("SHELL"))))
+	d.checkGo()
+	d.checkGOPATH()
+	d.checkPATH()
+	for _, t := range VimGoTools {
+		d.checkTool(t)
+	}
+	d.OK = d.Failed() == 0
+	return &d.Report
+}
+
+type doctor struct {
+	Report
+	env    []string
+	rc     string // the shell startup file, for fixes
+	gopath string // the GOPATH of the go command
+}
+
+// getenv returns the last value of key in d.env, as the go command does.
+func (d *doctor) getenv(key string) string {
+	v := ""
+	for _, kv := range d.env {
+		if k, val, ok := strings.Cut(kv, "="); ok && k == key {
+			v = val
+		}
+	}
+	return v
+}
+
+// lookPath searches the PATH of d.env for an executable file like
+// exec.LookPath. Relative directories on PATH are skipped.
+func (d *doctor) lookPath(file string) (string, error) {
+	for _, dir := range filepath.SplitList(d.getenv("PATH")) {
+		if !filepath.IsAbs(dir) {
+			continue
+		}
+		if path, err := exec.LookPath(filepath.Join(dir, file)); err == nil {
+			return path, nil
+		}
+	}
+	return "", &exec.Error{Name: file, Err: exec.ErrNotFound}
+}
+
+func (d *doctor) add(name string, status Status, fix string, format string, args ...any) {
+	d.Checks = append(d.Checks, Check{Name: name, Status: status, Detail: fmt.Sprintf(format, args...), Fix: fix})
+}
+
+func (d *doctor) checkGo() {
+	gobin, err := d.lookPath("go")
+	if err != nil {
+		d.add("go", Fail, "install Go from https://go.dev/dl/ and add 'export PATH=$PATH:/usr/local/go/bin' to "+d.rc,
+			"go is not on PATH")
+		d.add("go version", Fail, "", "cannot run go")
+		return
+	}
+	d.add("go", OK, "", "%s", gobin)
+
+	cmd := exec.Command(gobin, "env", "GOVERSION", "GOPATH")
+	cmd.Env = d.env
+	out, err := cmd.Output()
+	lines := strings.Split(strings.TrimSpace(string(out)), "\n")
+	if err != nil || len(lines) != 2 {
+		d.add("go version", Fail, "check the Go installation in "+filepath.Dir(gobin), "go env failed: %v", err)
+		return
+	}
+	version := lines[0]
+	d.gopath = lines[1]
+
+	want, err := GoDirective(filepath.Join(d.Root, ModDir, "go.mod"))
+	switch {
+	case err != nil || want == "":
+		d.add("go version", Warn, "", "%s, no go directive in %s: %v", version, filepath.Join(ModDir, "go.mod"), err)
+	case !strings.HasPrefix(version, "go1"):
+		d.add("go version", Warn, "", "%s is a development version, go.mod wants go %s", version, want)
+	case CompareVersions(version, want) < 0:
+		d.add("go version", Fail, "install Go "+want+" or newer from https://go.dev/dl/",
+			"%s is older than go %s from go.mod", version, want)
+	default:
+		d.add("go version", OK, "", "%s, go.mod wants go %s", version, want)
+	}
+}
+
+func (d *doctor) checkGOPATH() {
+	root := d.Root
+	if home := d.getenv("HOME"); home != "" {
+		root = homeRelativeTo(home, root)
+	}
+	fix := "add 'export GOPATH=" + root + "' to " + d.rc
+	if d.gopath == "" {
+		d.add("GOPATH", Fail, fix, "cannot tell the GOPATH without go")
+		return
+	}
+	list := filepath.SplitList(d.gopath)
+	switch {
+	case sameFile(list[0], d.Root) && d.getenv("GOPATH") == "":
+		d.add("GOPATH", Warn, fix, "%s is the GOPATH only by default or 'go env -w'", d.gopath)
+	case sameFile(list[0], d.Root):
+		d.add("GOPATH", OK, "", "%s", d.gopath)
+	default:
+		d.add("GOPATH", Fail, fix, "GOPATH is %s, not the checkout %s", d.gopath, d.Root)
+	}
+}
+
+func (d *doctor) checkPATH() {
+	bin := filepath.Join(d.Root, "bin")
+	for _, dir := range filepath.SplitList(d.getenv("PATH")) {
+		if dir != "" && sameFile(dir, bin) {
+			d.add("bin on PATH", OK, "", "%s", bin)
+			return
+		}
+	}
+	d.add("bin on PATH", Fail, "add 'export PATH=$PATH:$GOPATH/bin' to "+d.rc, "%s is not on PATH", bin)
+}
+
+func (d *doctor) checkTool(t Tool) {
+	name := "vim-go " + t.Name
+	path := filepath.Join(d.Root, "bin", t.Name)
+	install := "run 'godev tools sync " + t.Name + "'"
+	fi, err := os.Stat(path)
+	switch {
+	case err != nil:
+		d.add(name, Fail, install, "%s is missing", path)
+		return
+	case !fi.Mode().IsRegular():
+		d.add(name, Fail, install, "%s is not a file", path)
+		return
+	case fi.Mode().Perm()&0o111 == 0:
+		d.add(name, Fail, "chmod +x "+path, "%s is not executable", path)
+		return
+	}
+	info, err := buildinfo.ReadFile(path)
+	if err != nil {
+		d.add(name, Fail, install, "%s is not a Go program: %v", path, err)
+		return
+	}
+	detail := fmt.Sprintf("%s %s, built with %s", info.Main.Path, info.Main.Version, info.GoVersion)
+	if found, err := d.lookPath(t.Name); err == nil && !sameFile(found, path) {
+		d.add(name, Warn, "put "+filepath.Dir(path)+" before "+filepath.Dir(found)+" on PATH",
+			"%s, but %s comes first on PATH", detail, found)
+		return
+	}
+	d.add(name, OK, "", "%s", detail)
+}
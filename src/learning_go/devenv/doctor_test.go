@@ -0,0 +1,196 @@
+package devenv_test
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"sort"
+	"strings"
+	"testing"
+
+	"gbdmp/learningo/devenv"
+)
+
+// fakeGo writes a go command that answers go env GOVERSION GOPATH with
+// version and gopath, and returns its directory.
+func fakeGo(t *testing.T, version, gopath string) string {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("the fake go command is a shell script")
+	}
+	dir := t.TempDir()
+	script := "#!/bin/sh\nprintf '%s\\n%s\\n' '" + version + "' '" + gopath + "'\n"
+	if err := os.WriteFile(filepath.Join(dir, "go"), []byte(script), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	return dir
+}
+
+// checkout creates a go_dev checkout in a new home directory: go.mod asks
+// for go 1.21.2, and bin has asmfmt, a Go program, and broken versions of
+// the other vim-go tools.
+func checkout(t *testing.T) (home, root string) {
+	t.Helper()
+	home = t.TempDir()
+	root = filepath.Join(home, "go_dev")
+	bin := filepath.Join(root, "bin")
+	if err := os.MkdirAll(filepath.Join(root, devenv.ModDir), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(root, devenv.ModDir, "go.mod"), []byte("module gbdmp/learningo\n\ngo 1.21.2\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(filepath.Join(bin, "iferr"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	exe, err := os.Executable()
+	if err != nil {
+		t.Fatal(err)
+	}
+	self, err := os.ReadFile(exe)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for name, f := range map[string]struct {
+		data []byte
+		perm os.FileMode
+	}{
+		"asmfmt":       {self, 0o755},
+		"gomodifytags": {self, 0o644},
+		"gotags":       {[]byte("#!/bin/sh\n"), 0o755},
+	} {
+		if err := os.WriteFile(filepath.Join(bin, name), f.data, f.perm); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return home, root
+}
+
+// check is what a test expects of a check: its status and the start of
+// its detail and fix.
+type check struct {
+	status      devenv.Status
+	detail, fix string
+}
+
+func TestDoctor(t *testing.T) {
+	home, root := checkout(t)
+	bin := filepath.Join(root, "bin")
+	good := fakeGo(t, "go1.21.2", root)
+	other := filepath.Join(t.TempDir(), "asmfmt")
+	if err := os.WriteFile(other, []byte("#!/bin/sh\n"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	path := func(dirs ...string) string { return "PATH=" + strings.Join(dirs, string(filepath.ListSeparator)) }
+	base := []string{"HOME=" + home, "SHELL=/bin/zsh", "GOPATH=" + root}
+
+	for _, tt := range []struct {
+		name string
+		env  []string
+		want map[string]check
+	}{
+		{"set up", []string{path(good, bin)}, map[string]check{
+			"go":          {devenv.OK, filepath.Join(good, "go"), ""},
+			"go version":  {devenv.OK, "go1.21.2, go.mod wants go 1.21.2", ""},
+			"GOPATH":      {devenv.OK, root, ""},
+			"bin on PATH": {devenv.OK, bin, ""},
+		}},
+		{"no go", []string{path(bin)}, map[string]check{
+			"go":         {devenv.Fail, "go is not on PATH", "install Go from https://go.dev/dl/ and add 'export PATH=$PATH:/usr/local/go/bin' to ~/.zshrc"},
+			"go version": {devenv.Fail, "cannot run go", ""},
+			"GOPATH":     {devenv.Fail, "cannot tell the GOPATH without go", "add 'export GOPATH=$HOME/go_dev' to ~/.zshrc"},
+		}},
+		{"old go", []string{path(fakeGo(t, "go1.20.5", root), bin)}, map[string]check{
+			"go version": {devenv.Fail, "go1.20.5 is older than go 1.21.2 from go.mod", "install Go 1.21.2 or newer"},
+		}},
+		{"prerelease", []string{path(fakeGo(t, "go1.21rc2", root), bin)}, map[string]check{
+			"go version": {devenv.Fail, "go1.21rc2 is older", "install Go 1.21.2 or newer"},
+		}},
+		{"newer go", []string{path(fakeGo(t, "go1.22.1", root), bin)}, map[string]check{
+			"go version": {devenv.OK, "go1.22.1", ""},
+		}},
+		{"development go", []string{path(fakeGo(t, "devel go1.23-abc", root), bin)}, map[string]check{
+			"go version": {devenv.Warn, "devel go1.23-abc is a development version", ""},
+		}},
+		{"default GOPATH", []string{path(good, bin), "GOPATH="}, map[string]check{
+			"GOPATH": {devenv.Warn, root + " is the GOPATH only by default", "add 'export GOPATH=$HOME/go_dev' to ~/.zshrc"},
+		}},
+		{"other GOPATH", []string{path(fakeGo(t, "go1.21.2", home), bin)}, map[string]check{
+			"GOPATH": {devenv.Fail, "GOPATH is " + home + ", not the checkout", "add 'export GOPATH=$HOME/go_dev'"},
+		}},
+		{"bin not on PATH", []string{path(good), "SHELL=/bin/bash"}, map[string]check{
+			"bin on PATH":   {devenv.Fail, bin + " is not on PATH", "add 'export PATH=$PATH:$GOPATH/bin' to ~/.bashrc"},
+			"vim-go asmfmt": {devenv.OK, "", ""},
+		}},
+		{"tools", []string{path(good, bin)}, map[string]check{
+			"vim-go asmfmt":       {devenv.OK, "gbdmp/learningo ", ""},
+			"vim-go gomodifytags": {devenv.Fail, filepath.Join(bin, "gomodifytags") + " is not executable", "chmod +x " + filepath.Join(bin, "gomodifytags")},
+			"vim-go gotags":       {devenv.Fail, filepath.Join(bin, "gotags") + " is not a Go program", "run 'godev tools sync gotags'"},
+			"vim-go iferr":        {devenv.Fail, filepath.Join(bin, "iferr") + " is not a file", "run 'godev tools sync iferr'"},
+			"vim-go motion":       {devenv.Fail, filepath.Join(bin, "motion") + " is missing", "run 'godev tools sync motion'"},
+		}},
+		{"shadowed tool", []string{path(good, filepath.Dir(other), bin)}, map[string]check{
+			"vim-go asmfmt": {devenv.Warn, "gbdmp/learningo ", "put " + bin + " before " + filepath.Dir(other) + " on PATH"},
+		}},
+	} {
+		rep := devenv.DoctorEnv(root, append(append([]string{}, base...), tt.env...))
+		checks := map[string]devenv.Check{}
+		var names []string
+		for _, c := range rep.Checks {
+			checks[c.Name] = c
+			names = append(names, c.Name)
+		}
+		if want := "go, go version, GOPATH, bin on PATH, vim-go asmfmt, vim-go gomodifytags, vim-go gotags, vim-go iferr, vim-go motion"; strings.Join(names, ", ") != want {
+			t.Errorf("%s: checks %s, want %s", tt.name, strings.Join(names, ", "), want)
+		}
+		for name, want := range tt.want {
+			c := checks[name]
+			if c.Status != want.status || !strings.HasPrefix(c.Detail, want.detail) || !strings.HasPrefix(c.Fix, want.fix) || (want.fix == "" && c.Fix != "") {
+				t.Errorf("%s: %s = %+v, want %+v", tt.name, name, c, want)
+			}
+		}
+		// the tools in bin are broken in every case
+		if rep.OK || rep.Failed() < 4 {
+			t.Errorf("%s: %d failed, OK %v", tt.name, rep.Failed(), rep.OK)
+		}
+	}
+}
+
+func TestReportJSON(t *testing.T) {
+	_, root := checkout(t)
+	rep := devenv.DoctorEnv(root, []string{"PATH=" + fakeGo(t, "go1.21.2", root)})
+	data, err := json.Marshal(rep)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var shape struct {
+		Root   string           `json:"root"`
+		OK     *bool            `json:"ok"`
+		Checks []map[string]any `json:"checks"`
+	}
+	if err := json.Unmarshal(data, &shape); err != nil {
+		t.Fatal(err)
+	}
+	if shape.Root != root || shape.OK == nil || *shape.OK || len(shape.Checks) != len(rep.Checks) {
+		t.Fatalf("JSON report = %s", data)
+	}
+	for i, c := range shape.Checks {
+		var keys []string
+		for k := range c {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		want := []string{"detail", "name", "status"}
+		if rep.Checks[i].Fix != "" {
+			want = []string{"detail", "fix", "name", "status"}
+		}
+		if !reflect.DeepEqual(keys, want) {
+			t.Errorf("check %d has keys %v, want %v", i, keys, want)
+		}
+		if s := c["status"]; s != "ok" && s != "warn" && s != "fail" {
+			t.Errorf("check %d has status %v", i, s)
+		}
+	}
+}
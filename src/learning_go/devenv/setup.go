@@ -0,0 +1,172 @@
+package devenv
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"gbdmp/learningo/internal/atomicfile"
+	"gbdmp/learningo/internal/textdiff"
+)
+
+// The managed blocks that setup writes start and end with these marker
+// lines, behind the comment leader of the file. Everything between the
+// markers belongs to setup and is replaced when it runs again.
+const (
+	beginMarker = ">>> go_dev: managed by godev setup, changes are overwritten >>>"
+	endMarker   = "<<< go_dev <<<"
+)
+
+// Vimrc is the .vimrc of the README: vim-plug with vim-go, comma as the
+// leader and tabs in Go files.
+const Vimrc = `call plug#begin('~/.vim/plugged')
+
+Plug 'fatih/vim-go'
+
+call plug#end()
+
+filetype off
+filetype plugin indent on
+
+set number
+set noswapfile
+set noshowmode
+set ts=2 sw=2 sts=2 et
+set backspace=indent,eol,start
+
+" Map <leader> to comma
+let mapleader=","
+
+if has("autocmd")
+  autocmd FileType go set ts=2 sw=2 sts=2 noet nolist autowrite
+endif
+`
+
+// SetupOptions are the inputs of Plan.
+type SetupOptions struct {
+	Home  string // the home directory
+	Shell string // bash or zsh
+	Root  string // the go_dev checkout, which becomes GOPATH
+	GoBin string // the directory of the go command
+}
+
+// Change is a planned change of a file.
+type Change struct {
+	Path   string
+	Old    string
+	New    string
+	Exists bool
+}
+
+// Changed reports whether the change changes anything.
+func (c Change) Changed() bool { return !c.Exists || c.Old != c.New }
+
+// Diff returns the change as a unified diff, "" if there is none.
+func (c Change) Diff() string {
+	old := c.Path
+	if !c.Exists {
+		old = "/dev/null"
+	}
+	return textdiff.Unified(old, c.Path, c.Old, c.New)
+}
+
+// rcBlock returns the lines setup manages in the shell startup file.
+func rcBlock(o SetupOptions) string {
+	return fmt.Sprintf("export PATH=$PATH:%s\nexport GOPATH=%s\nexport PATH=$PATH:$GOPATH/bin\n",
+		homeRelativeTo(o.Home, o.GoBin), homeRelativeTo(o.Home, o.Root))
+}
+
+// Plan returns the changes setup makes: the managed block in the startup
+// file of the shell and the one in .vimrc. Files that are up to date give
+// changes without effect.
+func Plan(o SetupOptions) ([]Change, error) {
+	if o.Shell != "bash" && o.Shell != "zsh" {
+		return nil, fmt.Errorf("unsupported shell %q, want bash or zsh", o.Shell)
+	}
+	files := []struct {
+		path, comment, block string
+	}{
+		{RCFile(o.Home, o.Shell), "#", rcBlock(o)},
+		{filepath.Join(o.Home, ".vimrc"), `"`, Vimrc},
+	}
+	var changes []Change
+	for _, f := range files {
+		// write through symbolic links, as dotfile managers use them
+		if p, err := filepath.EvalSymlinks(f.path); err == nil {
+			f.path = p
+		}
+		data, err := os.ReadFile(f.path)
+		if err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return nil, err
+		}
+		c := Change{Path: f.path, Old: string(data), Exists: err == nil}
+		if c.New, err = SetBlock(c.Old, f.comment, f.block); err != nil {
+			return nil, fmt.Errorf("%s: %w", f.path, err)
+		}
+		changes = append(changes, c)
+	}
+	return changes, nil
+}
+
+// SetBlock returns text with the managed block replaced by block, or
+// with the block appended if text has none. comment is the comment leader
+// of the file.
+func SetBlock(text, comment, block string) (string, error) {
+	begin, end := comment+" "+beginMarker+"\n", comment+" "+endMarker+"\n"
+	managed := begin + block + end
+	i := strings.Index(text, begin)
+	if i < 0 {
+		if text != "" && !strings.HasSuffix(text, "\n") {
+			text += "\n"
+		}
+		if text != "" {
+			text += "\n"
+		}
+		return text + managed, nil
+	}
+	j := strings.Index(text[i:], end)
+	if j < 0 {
+		return "", errors.New("the managed block has no end marker")
+	}
+	return text[:i] + managed + text[i+j+len(end):], nil
+}
+
+// Apply makes the changes. An existing file is copied to a backup named
+// after it and now before it is replaced; Apply returns the backups.
+func Apply(changes []Change, now time.Time) ([]string, error) {
+	var backups []string
+	for _, c := range changes {
+		if !c.Changed() {
+			continue
+		}
+		perm := os.FileMode(0o644)
+		if c.Exists {
+			fi, err := os.Stat(c.Path)
+			if err != nil {
+				return backups, err
+			}
+			perm = fi.Mode().Perm()
+			backup := c.Path + ".godev-" + now.Format("20060102-150405") + ".bak"
+			if err := atomicfile.WriteFile(backup, []byte(c.Old), perm); err != nil {
+				return backups, err
+			}
+			backups = append(backups, backup)
+		}
+		if err := atomicfile.WriteFile(c.Path, []byte(c.New), perm); err != nil {
+			return backups, err
+		}
+	}
+	return backups, nil
+}
+
+// homeRelativeTo writes a path below home as $HOME/....
+func homeRelativeTo(home, path string) string {
+	if rel, err := filepath.Rel(home, path); err == nil && rel != "." && !strings.HasPrefix(rel, "..") {
+		return "$HOME/" + filepath.ToSlash(rel)
+	}
+	return path
+}
@@ -0,0 +1,107 @@
+package devenv_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"gbdmp/learningo/devenv"
+)
+
+func TestSetBlock(t *testing.T) {
+	block := "export GOPATH=$HOME/go_dev\n"
+	for _, tt := range []struct {
+		name, text string
+	}{
+		{"empty", ""},
+		{"no final newline", "alias ll='ls -l'"},
+		{"final newline", "alias ll='ls -l'\n"},
+		{"old block", "a\n\n# >>> go_dev: managed by godev setup, changes are overwritten >>>\nexport GOPATH=/old\n# <<< go_dev <<<\nb\n"},
+	} {
+		once, err := devenv.SetBlock(tt.text, "#", block)
+		if err != nil {
+			t.Errorf("%s: %v", tt.name, err)
+			continue
+		}
+		if strings.Count(once, ">>> go_dev") != 1 || strings.Count(once, block) != 1 {
+			t.Errorf("%s: SetBlock = %q, want one managed block", tt.name, once)
+		}
+		if strings.Contains(once, "/old") {
+			t.Errorf("%s: SetBlock kept the old block: %q", tt.name, once)
+		}
+		twice, err := devenv.SetBlock(once, "#", block)
+		if err != nil || twice != once {
+			t.Errorf("%s: SetBlock run again = %q, %v, want %q", tt.name, twice, err, once)
+		}
+	}
+}
+
+func TestSetBlockKeepsTheRest(t *testing.T) {
+	text := "a\n# >>> go_dev: managed by godev setup, changes are overwritten >>>\nold\n# <<< go_dev <<<\nb\n"
+	got, err := devenv.SetBlock(text, "#", "new\n")
+	want := "a\n# >>> go_dev: managed by godev setup, changes are overwritten >>>\nnew\n# <<< go_dev <<<\nb\n"
+	if err != nil || got != want {
+		t.Errorf("SetBlock = %q, %v, want %q", got, err, want)
+	}
+	if _, err := devenv.SetBlock("# >>> go_dev: managed by godev setup, changes are overwritten >>>\nold\n", "#", "new\n"); err == nil {
+		t.Error("SetBlock of a block without end marker succeeds")
+	}
+}
+
+func TestPlanTwice(t *testing.T) {
+	home := t.TempDir()
+	if err := os.WriteFile(filepath.Join(home, ".bashrc"), []byte("alias ll='ls -l'"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	o := devenv.SetupOptions{
+		Home:  home,
+		Shell: "bash",
+		Root:  filepath.Join(home, "go_dev"),
+		GoBin: "/usr/local/go/bin",
+	}
+	changes, err := devenv.Plan(o)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, c := range changes {
+		if !c.Changed() || c.Diff() == "" {
+			t.Errorf("%s: nothing to change on the first run", c.Path)
+		}
+	}
+	backups, err := devenv.Apply(changes, time.Date(2023, time.October, 13, 12, 0, 0, 0, time.UTC))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// only .bashrc existed
+	if want := filepath.Join(home, ".bashrc.godev-20231013-120000.bak"); len(backups) != 1 || backups[0] != want {
+		t.Errorf("backups = %v, want %s", backups, want)
+	}
+	if fi, err := os.Stat(filepath.Join(home, ".bashrc")); err != nil || fi.Mode().Perm() != 0o600 {
+		t.Errorf("mode of .bashrc = %v, %v, want it kept", fi.Mode(), err)
+	}
+	rc, _ := os.ReadFile(filepath.Join(home, ".bashrc"))
+	if want := "alias ll='ls -l'\n\n# >>> go_dev"; !strings.HasPrefix(string(rc), want) || !strings.Contains(string(rc), "export GOPATH=$HOME/go_dev\n") {
+		t.Errorf(".bashrc =\n%s", rc)
+	}
+
+	changes, err = devenv.Plan(o)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, c := range changes {
+		if c.Changed() || c.Diff() != "" {
+			t.Errorf("%s: the second run changes\n%s", c.Path, c.Diff())
+		}
+	}
+	if backups, err := devenv.Apply(changes, time.Now()); err != nil || len(backups) != 0 {
+		t.Errorf("second Apply = %v, %v, want no backups", backups, err)
+	}
+}
+
+func TestPlanShell(t *testing.T) {
+	if _, err := devenv.Plan(devenv.SetupOptions{Home: t.TempDir(), Shell: "fish"}); err == nil {
+		t.Error("Plan for fish succeeds")
+	}
+}
@@ -0,0 +1,182 @@
+package devenv
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"debug/buildinfo"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"gbdmp/learningo/internal/gotool"
+	"gbdmp/learningo/modcache"
+)
+
+// LockFile is the manifest of the tools in bin, in the checkout.
+const LockFile = "tools.lock"
+
+// ErrLock is returned for a malformed lock file.
+var ErrLock = errors.New("bad lock file")
+
+// LockedTool is a line of the lock file.
+type LockedTool struct {
+	Name    string `json:"name"`    // the binary in bin
+	Package string `json:"package"` // its main package
+	Module  string `json:"module"`
+	Version string `json:"version"`
+	Sum     string `json:"sum"` // the h1: hash of the module, as in go.sum
+}
+
+// ReadLock reads a lock file. Every line that is not empty or a #
+// comment has the fields binary, package, module, version and sum.
+func ReadLock(path string) ([]LockedTool, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var tools []LockedTool
+	seen := map[string]bool{}
+	sc := bufio.NewScanner(bytes.NewReader(data))
+	for n := 1; sc.Scan(); n++ {
+		line := strings.TrimSpace(sc.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		f := strings.Fields(line)
+		if len(f) != 5 {
+			return nil, fmt.Errorf("%w: %s:%d: want binary, package, module, version and sum", ErrLock, path, n)
+		}
+		t := LockedTool{f[0], f[1], f[2], f[3], f[4]}
+		if seen[t.Name] {
+			return nil, fmt.Errorf("%w: %s:%d: %s is listed twice", ErrLock, path, n, t.Name)
+		}
+		if t.Package != t.Module && !strings.HasPrefix(t.Package, t.Module+"/") {
+			return nil, fmt.Errorf("%w: %s:%d: package %s is not in module %s", ErrLock, path, n, t.Package, t.Module)
+		}
+		seen[t.Name] = true
+		tools = append(tools, t)
+	}
+	return tools, sc.Err()
+}
+
+// ToolState is a binary compared with its lock file entry.
+type ToolState struct {
+	Tool LockedTool `json:"tool"`
+	Path string     `json:"path"`
+	// What the binary was built from, as far as it could be read.
+	Package   string   `json:"foundPackage,omitempty"`
+	Version   string   `json:"foundVersion,omitempty"`
+	Sum       string   `json:"foundSum,omitempty"`
+	GoVersion string   `json:"goVersion,omitempty"`
+	Drift     []string `json:"drift,omitempty"` // what differs, empty if nothing
+}
+
+// OK reports whether the binary matches the lock file.
+func (s ToolState) OK() bool { return len(s.Drift) == 0 }
+
+// InspectTool reads the build information of the binary at path and
+// compares it with t.
+func InspectTool(path string, t LockedTool) ToolState {
+	s := ToolState{Tool: t, Path: path}
+	if _, err := os.Stat(path); err != nil {
+		s.Drift = append(s.Drift, "missing")
+		return s
+	}
+	info, err := buildinfo.ReadFile(path)
+	if err != nil {
+		s.Drift = append(s.Drift, "no Go build information: "+err.Error())
+		return s
+	}
+	s.Package, s.GoVersion = info.Path, info.GoVersion
+	mod := &info.Main
+	if mod.Path != t.Module {
+		mod = nil
+		for _, dep := range info.Deps {
+			if dep.Path == t.Module {
+				mod = dep
+			}
+		}
+	}
+	if s.Package != t.Package {
+		s.Drift = append(s.Drift, fmt.Sprintf("package is %s, want %s", s.Package, t.Package))
+	}
+	if mod == nil {
+		s.Drift = append(s.Drift, "not built from module "+t.Module)
+		return s
+	}
+	if mod.Replace != nil {
+		s.Drift = append(s.Drift, fmt.Sprintf("module is replaced by %s %s", mod.Replace.Path, mod.Replace.Version))
+	}
+	s.Version, s.Sum = mod.Version, mod.Sum
+	if s.Version != t.Version {
+		s.Drift = append(s.Drift, fmt.Sprintf("version is %s, want %s", s.Version, t.Version))
+	}
+	if s.Sum != t.Sum {
+		s.Drift = append(s.Drift, fmt.Sprintf("sum is %q, want %s", s.Sum, t.Sum))
+	}
+	return s
+}
+
+// ToolModule writes the throwaway module a tool is built in to a new
+// temporary directory and returns it; the caller removes it. The module
+// requires the module of t, and its go.sum holds the sum from the lock
+// file, which the go command checks the cached module against.
+func ToolModule(root string, t LockedTool) (string, error) {
+	goVersion, err := GoDirective(filepath.Join(root, ModDir, "go.mod"))
+	if err != nil || goVersion == "" {
+		goVersion = "1.21"
+	}
+	tmp, err := os.MkdirTemp("", "godev-tools")
+	if err != nil {
+		return "", err
+	}
+	gomod := fmt.Sprintf("module godev/tools\n\ngo %s\n\nrequire %s %s\n", goVersion, t.Module, t.Version)
+	gosum := fmt.Sprintf("%s %s %s\n", t.Module, t.Version, t.Sum)
+	if err := os.WriteFile(filepath.Join(tmp, "go.mod"), []byte(gomod), 0o644); err != nil {
+		os.RemoveAll(tmp)
+		return "", err
+	}
+	if err := os.WriteFile(filepath.Join(tmp, "go.sum"), []byte(gosum), 0o644); err != nil {
+		os.RemoveAll(tmp)
+		return "", err
+	}
+	return tmp, nil
+}
+
+// BuildTool builds t from the module cache of the checkout in root into
+// bin, without network access: the package is built in the module of
+// ToolModule, with the environment of modcache.OfflineEnv so that every
+// module has to come from pkg/mod. The checksum database cannot be
+// reached and is not asked. The binary replaces the old one only once it
+// matches the lock file.
+func BuildTool(ctx context.Context, root string, t LockedTool) (ToolState, error) {
+	tmp, err := ToolModule(root, t)
+	if err != nil {
+		return ToolState{}, err
+	}
+	defer os.RemoveAll(tmp)
+
+	bin := filepath.Join(root, "bin")
+	if err := os.MkdirAll(bin, 0o755); err != nil {
+		return ToolState{}, err
+	}
+	out := filepath.Join(bin, "."+t.Name+".godev-tmp")
+	defer os.Remove(out)
+	cmd := exec.CommandContext(ctx, gotool.Path(), "build", "-o", out, t.Package)
+	cmd.Dir = tmp
+	cmd.Env = modcache.OfflineEnv(root)
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return ToolState{}, fmt.Errorf("building %s: %v\n%s", t.Name, err, output)
+	}
+
+	s := InspectTool(out, t)
+	if !s.OK() {
+		return s, fmt.Errorf("%s was built, but does not match %s: %s", t.Name, LockFile, strings.Join(s.Drift, "; "))
+	}
+	s.Path = filepath.Join(bin, t.Name)
+	return s, os.Rename(out, s.Path)
+}
@@ -0,0 +1,156 @@
+package devenv_test
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"runtime/debug"
+	"strings"
+	"testing"
+
+	"gbdmp/learningo/devenv"
+)
+
+const lockLine = "gopls golang.org/x/tools/gopls golang.org/x/tools/gopls v0.14.0 h1:abc=\n"
+
+func writeLock(t *testing.T, text string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), devenv.LockFile)
+	if err := os.WriteFile(path, []byte(text), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestReadLock(t *testing.T) {
+	tools, err := devenv.ReadLock(writeLock(t, "# tools\n\n"+lockLine+
+		"  staticcheck honnef.co/go/tools/cmd/staticcheck honnef.co/go/tools 2023.1.6 h1:def=  \n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []devenv.LockedTool{
+		{"gopls", "golang.org/x/tools/gopls", "golang.org/x/tools/gopls", "v0.14.0", "h1:abc="},
+		{"staticcheck", "honnef.co/go/tools/cmd/staticcheck", "honnef.co/go/tools", "2023.1.6", "h1:def="},
+	}
+	if len(tools) != len(want) || tools[0] != want[0] || tools[1] != want[1] {
+		t.Errorf("ReadLock = %+v, want %+v", tools, want)
+	}
+}
+
+func TestReadLockErrors(t *testing.T) {
+	for _, tt := range []struct {
+		name, text, err string
+	}{
+		{"too few fields", "gopls golang.org/x/tools/gopls v0.14.0 h1:abc=\n", ":1: want binary, package, module, version and sum"},
+		{"too many fields", "# a comment\ngopls golang.org/x/tools/gopls golang.org/x/tools/gopls v0.14.0 h1:abc= extra\n", ":2: want binary"},
+		{"listed twice", lockLine + lockLine, ":2: gopls is listed twice"},
+		{"outside the module", "dlv github.com/go-delve/delve/cmd/dlv github.com/go-delve/delve2 v1.21.0 h1:abc=\n", ":1: package github.com/go-delve/delve/cmd/dlv is not in module github.com/go-delve/delve2"},
+	} {
+		_, err := devenv.ReadLock(writeLock(t, tt.text))
+		if !errors.Is(err, devenv.ErrLock) || !strings.Contains(err.Error(), tt.err) {
+			t.Errorf("%s: ReadLock = %v, want %q", tt.name, err, tt.err)
+		}
+	}
+	if _, err := devenv.ReadLock(filepath.Join(t.TempDir(), "missing")); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("ReadLock of a missing file = %v", err)
+	}
+}
+
+// selfLock returns the path of the test binary and a lock entry that
+// matches it: its main package with golang.org/x/mod, which it is linked
+// with.
+func selfLock(t *testing.T) (string, devenv.LockedTool) {
+	t.Helper()
+	exe, err := os.Executable()
+	if err != nil {
+		t.Fatal(err)
+	}
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		t.Skip("the test binary has no build information")
+	}
+	for _, dep := range info.Deps {
+		if dep.Path == "golang.org/x/mod" && dep.Replace == nil {
+			return exe, devenv.LockedTool{Name: "self", Package: info.Path, Module: dep.Path, Version: dep.Version, Sum: dep.Sum}
+		}
+	}
+	t.Skip("the test binary is not built with golang.org/x/mod")
+	return "", devenv.LockedTool{}
+}
+
+func TestInspectTool(t *testing.T) {
+	exe, lock := selfLock(t)
+	if s := devenv.InspectTool(exe, lock); !s.OK() || s.Version != lock.Version || s.Sum != lock.Sum || s.GoVersion == "" {
+		t.Fatalf("InspectTool of the test binary = %+v, want no drift", s)
+	}
+
+	notGo := filepath.Join(t.TempDir(), "tool")
+	if err := os.WriteFile(notGo, []byte("#!/bin/sh\necho hello\n"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	for _, tt := range []struct {
+		name   string
+		path   string
+		change func(*devenv.LockedTool)
+		drift  []string // the start of each drift
+	}{
+		{"missing", filepath.Join(t.TempDir(), "missing"), nil, []string{"missing"}},
+		{"not Go", notGo, nil, []string{"no Go build information"}},
+		{"version", exe, func(l *devenv.LockedTool) { l.Version = "v0.99.0" }, []string{"version is " + lock.Version + ", want v0.99.0"}},
+		{"sum", exe, func(l *devenv.LockedTool) { l.Sum = "h1:abc=" }, []string{"sum is \"" + lock.Sum + "\", want h1:abc="}},
+		{"version and sum", exe, func(l *devenv.LockedTool) { l.Version, l.Sum = "v0.99.0", "h1:abc=" }, []string{"version is", "sum is"}},
+		{"package", exe, func(l *devenv.LockedTool) { l.Package = "golang.org/x/mod/cmd/gorelease" }, []string{"package is " + lock.Package}},
+		{"module", exe, func(l *devenv.LockedTool) { l.Module = "golang.org/x/tools" }, []string{"not built from module golang.org/x/tools"}},
+	} {
+		l := lock
+		if tt.change != nil {
+			tt.change(&l)
+		}
+		s := devenv.InspectTool(tt.path, l)
+		if s.OK() || len(s.Drift) != len(tt.drift) {
+			t.Errorf("%s: drift %q, want %q", tt.name, s.Drift, tt.drift)
+			continue
+		}
+		for i, d := range tt.drift {
+			if !strings.HasPrefix(s.Drift[i], d) {
+				t.Errorf("%s: drift %q, want %q", tt.name, s.Drift, tt.drift)
+				break
+			}
+		}
+	}
+}
+
+func TestToolModule(t *testing.T) {
+	lock := devenv.LockedTool{Name: "gopls", Package: "golang.org/x/tools/gopls", Module: "golang.org/x/tools/gopls", Version: "v0.14.0", Sum: "h1:abc="}
+	root := t.TempDir()
+	for _, tt := range []struct {
+		name, gomod, goVersion string
+	}{
+		{"no go.mod", "", "1.21"},
+		{"go directive", "module gbdmp/learningo\n\ngo 1.21.2\n", "1.21.2"},
+		{"no go directive", "module gbdmp/learningo\n", "1.21"},
+	} {
+		if tt.gomod != "" {
+			gomod := filepath.Join(root, devenv.ModDir, "go.mod")
+			if err := os.MkdirAll(filepath.Dir(gomod), 0o755); err != nil {
+				t.Fatal(err)
+			}
+			if err := os.WriteFile(gomod, []byte(tt.gomod), 0o644); err != nil {
+				t.Fatal(err)
+			}
+		}
+		tmp, err := devenv.ToolModule(root, lock)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		gomod, _ := os.ReadFile(filepath.Join(tmp, "go.mod"))
+		gosum, _ := os.ReadFile(filepath.Join(tmp, "go.sum"))
+		os.RemoveAll(tmp)
+		if want := "module godev/tools\n\ngo " + tt.goVersion + "\n\nrequire golang.org/x/tools/gopls v0.14.0\n"; string(gomod) != want {
+			t.Errorf("%s: go.mod =\n%s\nwant\n%s", tt.name, gomod, want)
+		}
+		if want := "golang.org/x/tools/gopls v0.14.0 h1:abc=\n"; string(gosum) != want {
+			t.Errorf("%s: go.sum = %q, want %q", tt.name, gosum, want)
+		}
+	}
+}
@@ -0,0 +1,109 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"gbdmp/learningo/exercises"
+	"gbdmp/learningo/progress"
+)
+
+func defaultWorkspace() string {
+	if dir := os.Getenv("LEARNINGO_EXERCISES"); dir != "" {
+		return dir
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "learningo-exercises"
+	}
+	return filepath.Join(home, "learningo", "exercises")
+}
+
+func runLessonsExercise(e *env, args []string) error {
+	fs := newFlagSet(e, "lessons exercise")
+	dir := fs.String("dir", defaultWorkspace(), "exercise workspace `directory`")
+	force := fs.Bool("force", false, "overwrite a stub that exists already")
+	pos, err := parseArgs(fs, args)
+	if err != nil {
+		return err
+	}
+	ws := exercises.Workspace(*dir)
+
+	if len(pos) == 0 {
+		t := &table{header: []string{"Exercise", "Title", "Started"}}
+		for _, x := range exercises.All() {
+			started := ""
+			if _, err := os.Stat(ws.StubPath(x)); err == nil {
+				started = "yes"
+			}
+			t.add(x.ID(), x.Title, started)
+		}
+		return t.write(e.stdout, "table")
+	}
+	if len(pos) != 1 {
+		return errUsage
+	}
+	x, err := exercises.Lookup(pos[0])
+	if err != nil {
+		return err
+	}
+	path, err := ws.Write(x, *force)
+	if err != nil {
+		return fmt.Errorf("%v (use -force to start over)", err)
+	}
+	fmt.Fprintf(e.stdout, "%s: %s\n\n%s\n\nEdit %s and run \"learningo lessons check %s\".\n",
+		x.ID(), x.Title, strings.TrimSpace(x.Description), path, x.ID())
+	return nil
+}
+
+func runLessonsCheck(e *env, args []string) error {
+	fs := newFlagSet(e, "lessons check")
+	dir := fs.String("dir", defaultWorkspace(), "exercise workspace `directory`")
+	learner := learnerFlag(fs)
+	pos, err := parseArgs(fs, args)
+	if err != nil {
+		return err
+	}
+	ws := exercises.Workspace(*dir)
+
+	var list []exercises.Exercise
+	for _, id := range pos {
+		x, err := exercises.Lookup(id)
+		if err != nil {
+			return err
+		}
+		list = append(list, x)
+	}
+	if len(pos) == 0 {
+		if list = ws.Started(); len(list) == 0 {
+			return fmt.Errorf("no exercises in %s; start one with \"learningo lessons exercise LESSON/N\"", *dir)
+		}
+	}
+
+	failed := 0
+	for _, x := range list {
+		res, err := exercises.Check(context.Background(), x, ws.StubPath(x))
+		if err != nil {
+			return err
+		}
+		fmt.Fprint(e.stdout, res)
+		if !res.Passed() {
+			failed++
+		}
+		e.record(*learner, progress.Event{
+			Time:     time.Now(),
+			Kind:     progress.Exercise,
+			Lesson:   x.Lesson,
+			Exercise: x.ID(),
+			Passed:   res.Passed(),
+		})
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %d exercises failed", failed, len(list))
+	}
+	return nil
+}
@@ -0,0 +1,164 @@
+package exercises
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"gbdmp/learningo/playground"
+)
+
+// Result is the outcome of checking an exercise.
+type Result struct {
+	Exercise Exercise
+	// Compiled is false if the stub or the tests did not build; BuildOutput
+	// then holds the compiler messages.
+	Compiled    bool
+	BuildOutput string
+	Tests       []TestResult
+	// TODOs counts the lines of the stub that still contain TODO.
+	TODOs int
+}
+
+// Passed reports whether the exercise compiled and every test passed.
+func (r *Result) Passed() bool {
+	if !r.Compiled || len(r.Tests) == 0 {
+		return false
+	}
+	for _, t := range r.Tests {
+		if !t.Passed {
+			return false
+		}
+	}
+	return true
+}
+
+// TestResult is the outcome of a single hidden test.
+type TestResult struct {
+	Name   string
+	Passed bool
+	Output string // what the test logged
+	Hint   string // hint for a failed test
+}
+
+// Check grades the learner's stub at src against the hidden tests of x.
+// The tests are built in a throwaway module and run in the playground.
+func Check(ctx context.Context, x Exercise, src string) (*Result, error) {
+	code, err := os.ReadFile(src)
+	if err != nil {
+		return nil, err
+	}
+	files := map[string][]byte{
+		"exercise.go":      code,
+		"exercise_test.go": x.tests(),
+	}
+	p, err := playground.Test(ctx, files, playground.DefaultOptions, "-test.v")
+	if err != nil {
+		return nil, err
+	}
+
+	res := &Result{Exercise: x, TODOs: strings.Count(string(code), "TODO"), BuildOutput: p.BuildOutput}
+	if !p.Compiled {
+		return res, nil
+	}
+	var other string
+	res.Tests, other = parseTestOutput(p.Stdout)
+	res.Compiled = len(res.Tests) > 0
+	if !res.Compiled {
+		res.BuildOutput = other + p.Stderr
+	}
+	for i := range res.Tests {
+		if !res.Tests[i].Passed {
+			res.Tests[i].Hint = x.Hints[res.Tests[i].Name]
+			if p.TimedOut {
+				res.Tests[i].Output += "the tests timed out\n"
+			}
+		}
+	}
+	return res, nil
+}
+
+// parseTestOutput returns the results of the top-level tests in the output
+// of a test binary run with -test.v, and any output that does not belong to
+// a test.
+func parseTestOutput(out string) ([]TestResult, string) {
+	var (
+		tests   []TestResult
+		index   = map[string]int{}
+		other   strings.Builder
+		current = -1
+	)
+	test := func(name string) int {
+		name, _, _ = strings.Cut(name, "/")
+		i, ok := index[name]
+		if !ok {
+			i = len(tests)
+			index[name] = i
+			tests = append(tests, TestResult{Name: name})
+		}
+		return i
+	}
+	for _, line := range strings.Split(out, "\n") {
+		trimmed := strings.TrimSpace(line)
+		fields := strings.Fields(trimmed)
+		switch {
+		case len(fields) >= 3 && fields[0] == "===":
+			// === RUN, === CONT, === PAUSE or === NAME
+			current = test(fields[2])
+		case len(fields) >= 3 && fields[0] == "---":
+			// --- PASS: TestX (0.00s), only top-level tests count.
+			name := fields[2]
+			i := test(name)
+			if !strings.Contains(name, "/") {
+				tests[i].Passed = fields[1] == "PASS:"
+			}
+		case trimmed == "" || isSummary(trimmed):
+		case current >= 0:
+			tests[current].Output += trimmed + "\n"
+		default:
+			other.WriteString(line + "\n")
+		}
+	}
+	return tests, other.String()
+}
+
+func isSummary(line string) bool {
+	line = strings.TrimSpace(line)
+	return line == "PASS" || line == "FAIL" || strings.HasPrefix(line, "ok ") || strings.HasPrefix(line, "FAIL\t")
+}
+
+// String summarizes r for the terminal.
+func (r *Result) String() string {
+	var sb strings.Builder
+	switch {
+	case !r.Compiled:
+		fmt.Fprintf(&sb, "FAIL %s: does not compile\n%s", r.Exercise.ID(), indent(r.BuildOutput))
+	case r.Passed():
+		fmt.Fprintf(&sb, "PASS %s: %s\n", r.Exercise.ID(), r.Exercise.Title)
+	default:
+		fmt.Fprintf(&sb, "FAIL %s: %s\n", r.Exercise.ID(), r.Exercise.Title)
+	}
+	for _, t := range r.Tests {
+		if t.Passed {
+			fmt.Fprintf(&sb, "  ok   %s\n", t.Name)
+			continue
+		}
+		fmt.Fprintf(&sb, "  FAIL %s\n%s", t.Name, indent(t.Output))
+		if t.Hint != "" {
+			fmt.Fprintf(&sb, "       hint: %s\n", t.Hint)
+		}
+	}
+	if r.TODOs > 0 && !r.Passed() {
+		fmt.Fprintf(&sb, "  %d TODO left in the stub\n", r.TODOs)
+	}
+	return sb.String()
+}
+
+func indent(s string) string {
+	s = strings.TrimRight(s, "\n")
+	if s == "" {
+		return ""
+	}
+	return "       " + strings.ReplaceAll(s, "\n", "\n       ") + "\n"
+}
@@ -0,0 +1,150 @@
+// Package exercises ships fill-in-the-blank exercises for the lessons and
+// grades them.
+//
+// Every exercise lives in testdata/LESSON/N and consists of a stub with
+// TODO gaps (exercise.go), hidden tests (exercise_test.go.txt) and a
+// description with hints per test (exercise.yaml). Check copies a
+// learner's stub and the hidden tests into a throwaway module and runs
+// go test on them. A reference solution (solution.go) sits next to them;
+// it is not built into the program, only the tests of this package use
+// it.
+package exercises
+
+import (
+	"embed"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+//go:embed testdata/*/*/exercise.go testdata/*/*/exercise_test.go.txt testdata/*/*/exercise.yaml
+var files embed.FS
+
+const (
+	stubFile = "exercise.go"
+	testFile = "exercise_test.go.txt"
+	metaFile = "exercise.yaml"
+)
+
+// Exercise is one exercise of a lesson.
+type Exercise struct {
+	Lesson      string `yaml:"-"`
+	Number      int    `yaml:"-"`
+	Title       string `yaml:"title"`
+	Description string `yaml:"description"`
+	// Hints maps the names of hidden tests to a hint shown when the test
+	// fails.
+	Hints map[string]string `yaml:"hints"`
+}
+
+// ID returns the LESSON/N name of x.
+func (x Exercise) ID() string {
+	return fmt.Sprintf("%s/%d", x.Lesson, x.Number)
+}
+
+func (x Exercise) dir() string {
+	return path.Join("testdata", x.Lesson, strconv.Itoa(x.Number))
+}
+
+// Stub returns the source code handed out to the learner.
+func (x Exercise) Stub() []byte {
+	data, _ := files.ReadFile(path.Join(x.dir(), stubFile))
+	return data
+}
+
+func (x Exercise) tests() []byte {
+	data, _ := files.ReadFile(path.Join(x.dir(), testFile))
+	return data
+}
+
+// All returns every exercise, sorted by lesson and number.
+func All() []Exercise {
+	var list []Exercise
+	lessons, _ := files.ReadDir("testdata")
+	for _, l := range lessons {
+		numbers, _ := files.ReadDir(path.Join("testdata", l.Name()))
+		for _, n := range numbers {
+			x, err := load(l.Name(), n.Name())
+			if err != nil {
+				panic("exercises: " + err.Error())
+			}
+			list = append(list, x)
+		}
+	}
+	sort.Slice(list, func(i, j int) bool {
+		if list[i].Lesson != list[j].Lesson {
+			return list[i].Lesson < list[j].Lesson
+		}
+		return list[i].Number < list[j].Number
+	})
+	return list
+}
+
+func load(lesson, number string) (Exercise, error) {
+	n, err := strconv.Atoi(number)
+	if err != nil {
+		return Exercise{}, fmt.Errorf("%s/%s: exercise directories must be numbered", lesson, number)
+	}
+	x := Exercise{Lesson: lesson, Number: n}
+	data, err := files.ReadFile(path.Join(x.dir(), metaFile))
+	if err != nil {
+		return Exercise{}, err
+	}
+	if err := yaml.Unmarshal(data, &x); err != nil {
+		return Exercise{}, fmt.Errorf("%s: %w", x.ID(), err)
+	}
+	return x, nil
+}
+
+// Lookup returns the exercise with the given LESSON/N id.
+func Lookup(id string) (Exercise, error) {
+	lesson, number, ok := strings.Cut(id, "/")
+	if !ok {
+		return Exercise{}, fmt.Errorf("exercise %q: want LESSON/NUMBER, e.g. loops/3", id)
+	}
+	if _, err := fs.Stat(files, path.Join("testdata", lesson, number)); err != nil {
+		return Exercise{}, fmt.Errorf("no exercise %s", id)
+	}
+	return load(lesson, number)
+}
+
+// Workspace is the directory in which learners work on their exercises.
+// The stub of exercise LESSON/N is kept in LESSON/N/exercise.go below it.
+type Workspace string
+
+// StubPath returns the path of the stub of x in the workspace.
+func (w Workspace) StubPath(x Exercise) string {
+	return filepath.Join(string(w), x.Lesson, strconv.Itoa(x.Number), stubFile)
+}
+
+// Write puts the stub of x into the workspace and returns its path. An
+// existing stub is left alone unless force is set, so that work is not
+// lost by accident.
+func (w Workspace) Write(x Exercise, force bool) (string, error) {
+	p := w.StubPath(x)
+	if _, err := os.Stat(p); err == nil && !force {
+		return p, fmt.Errorf("%s already exists", p)
+	}
+	if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+		return p, err
+	}
+	return p, os.WriteFile(p, x.Stub(), 0o644)
+}
+
+// Started returns the exercises that have a stub in the workspace.
+func (w Workspace) Started() []Exercise {
+	var list []Exercise
+	for _, x := range All() {
+		if _, err := os.Stat(w.StubPath(x)); err == nil {
+			list = append(list, x)
+		}
+	}
+	return list
+}
@@ -0,0 +1,105 @@
+package exercises
+
+import (
+	"context"
+	"path"
+	"path/filepath"
+	"sort"
+	"testing"
+)
+
+const solutionFile = "solution.go"
+
+// TestExercises grades the reference solution and the untouched stub of
+// every exercise the way "learningo lessons check" grades a learner's
+// work: the solution has to pass every hidden test, the stub has to
+// compile and fail with the hint of every test it fails.
+func TestExercises(t *testing.T) {
+	if testing.Short() {
+		t.Skip("builds and runs every exercise twice")
+	}
+	list := All()
+	if len(list) == 0 {
+		t.Fatal("no exercises")
+	}
+	for _, x := range list {
+		x := x
+		t.Run(x.ID(), func(t *testing.T) {
+			t.Parallel()
+			solution := filepath.FromSlash(path.Join(x.dir(), solutionFile))
+			res, err := Check(context.Background(), x, solution)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !res.Passed() {
+				t.Errorf("the solution does not pass:\n%s", res)
+			}
+			checkHints(t, x, res)
+
+			stub := filepath.FromSlash(path.Join(x.dir(), stubFile))
+			res, err = Check(context.Background(), x, stub)
+			if err != nil {
+				t.Fatal(err)
+			}
+			switch {
+			case !res.Compiled:
+				t.Errorf("the stub does not compile:\n%s", res)
+			case res.Passed():
+				t.Errorf("the stub passes without any work")
+			case res.TODOs == 0:
+				t.Errorf("the stub has no TODO")
+			}
+			for _, r := range res.Tests {
+				if !r.Passed && r.Hint == "" {
+					t.Errorf("the stub fails %s, which has no hint", r.Name)
+				}
+			}
+		})
+	}
+}
+
+// checkHints checks that the hints of x are for the tests there are.
+func checkHints(t *testing.T, x Exercise, res *Result) {
+	t.Helper()
+	var tests, hints []string
+	for _, r := range res.Tests {
+		tests = append(tests, r.Name)
+	}
+	for name := range x.Hints {
+		hints = append(hints, name)
+	}
+	sort.Strings(tests)
+	sort.Strings(hints)
+	if len(tests) != len(hints) {
+		t.Errorf("hidden tests %v, hints for %v", tests, hints)
+		return
+	}
+	for i := range tests {
+		if tests[i] != hints[i] {
+			t.Errorf("hidden tests %v, hints for %v", tests, hints)
+			return
+		}
+	}
+}
+
+func TestParseTestOutput(t *testing.T) {
+	out := `=== RUN   TestA
+--- PASS: TestA (0.00s)
+=== RUN   TestB
+=== RUN   TestB/sub
+    x_test.go:5: wrong
+--- FAIL: TestB (0.00s)
+    --- FAIL: TestB/sub (0.00s)
+FAIL
+`
+	tests, other := parseTestOutput(out)
+	if len(tests) != 2 || tests[0] != (TestResult{Name: "TestA", Passed: true}) {
+		t.Fatalf("tests = %+v", tests)
+	}
+	if b := tests[1]; b.Name != "TestB" || b.Passed || b.Output != "x_test.go:5: wrong\n" {
+		t.Errorf("TestB = %+v", b)
+	}
+	if other != "" {
+		t.Errorf("other output = %q, want none", other)
+	}
+}
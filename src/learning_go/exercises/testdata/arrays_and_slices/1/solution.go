@@ -0,0 +1,6 @@
+package exercise
+
+// AppendAll returns names with all of more appended.
+func AppendAll(names []string, more ...string) []string {
+	return append(names, more...)
+}
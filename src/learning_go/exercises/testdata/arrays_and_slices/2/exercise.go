@@ -0,0 +1,7 @@
+package exercise
+
+// Last returns the last element of names and whether there is one.
+func Last(names []string) (string, bool) {
+	// TODO: return the last element; len(names) tells how many there are
+	return "", false
+}
@@ -0,0 +1,9 @@
+package exercise
+
+// Last returns the last element of names and whether there is one.
+func Last(names []string) (string, bool) {
+	if len(names) == 0 {
+		return "", false
+	}
+	return names[len(names)-1], true
+}
@@ -0,0 +1,7 @@
+package exercise
+
+// Reverse returns a reversed copy of names.
+func Reverse(names []string) []string {
+	// TODO: create a new slice with make and fill it from the back
+	return names
+}
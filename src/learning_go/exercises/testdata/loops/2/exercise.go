@@ -0,0 +1,11 @@
+package exercise
+
+// Odd returns the odd numbers from 1 up to but not including max.
+func Odd(max int) []int {
+	var odd []int
+	for i := 0; i < max; i++ {
+		// TODO: continue with the next number if i is even
+		odd = append(odd, i)
+	}
+	return odd
+}
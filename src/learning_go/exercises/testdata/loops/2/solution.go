@@ -0,0 +1,13 @@
+package exercise
+
+// Odd returns the odd numbers from 1 up to but not including max.
+func Odd(max int) []int {
+	var odd []int
+	for i := 0; i < max; i++ {
+		if i%2 == 0 {
+			continue
+		}
+		odd = append(odd, i)
+	}
+	return odd
+}
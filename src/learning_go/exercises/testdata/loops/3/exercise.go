@@ -0,0 +1,11 @@
+package exercise
+
+// CountAdults returns how many people in ages are 18 or older.
+func CountAdults(ages map[string]int) int {
+	adults := 0
+	for name := range ages {
+		// TODO: also take the age from the range clause and count the adults
+		_ = name
+	}
+	return adults
+}
@@ -0,0 +1,12 @@
+package exercise
+
+// CountAdults returns how many people in ages are 18 or older.
+func CountAdults(ages map[string]int) int {
+	adults := 0
+	for _, age := range ages {
+		if age >= 18 {
+			adults++
+		}
+	}
+	return adults
+}
@@ -0,0 +1,8 @@
+package exercise
+
+// CountLetters returns how often each rune occurs in s.
+func CountLetters(s string) map[rune]int {
+	counts := map[rune]int{}
+	// TODO: range over s and increase the count of every rune
+	return counts
+}
@@ -0,0 +1,10 @@
+package exercise
+
+// CountLetters returns how often each rune occurs in s.
+func CountLetters(s string) map[rune]int {
+	counts := map[rune]int{}
+	for _, r := range s {
+		counts[r]++
+	}
+	return counts
+}
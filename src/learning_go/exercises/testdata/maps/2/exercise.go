@@ -0,0 +1,8 @@
+package exercise
+
+// Lookup returns the age of name and whether name is in ages.
+func Lookup(ages map[string]int, name string) (int, bool) {
+	// TODO: use the "comma ok" form of the index expression
+	age := ages[name]
+	return age, age != 0
+}
@@ -0,0 +1,7 @@
+package exercise
+
+// Lookup returns the age of name and whether name is in ages.
+func Lookup(ages map[string]int, name string) (int, bool) {
+	age, ok := ages[name]
+	return age, ok
+}
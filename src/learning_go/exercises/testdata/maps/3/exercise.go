@@ -0,0 +1,9 @@
+package exercise
+
+// Oldest returns the name of the oldest person in ages.
+func Oldest(ages map[string]int) string {
+	oldest := ""
+	// TODO: range over ages and remember the oldest name; map order is random,
+	// so ties have to be decided by comparing the names
+	return oldest
+}
@@ -0,0 +1,12 @@
+package exercise
+
+// Oldest returns the name of the oldest person in ages.
+func Oldest(ages map[string]int) string {
+	oldest := ""
+	for name, age := range ages {
+		if oldest == "" || age > ages[oldest] || (age == ages[oldest] && name < oldest) {
+			oldest = name
+		}
+	}
+	return oldest
+}
@@ -0,0 +1,9 @@
+package exercise
+
+// Answer returns the answer to everything.
+func Answer() int {
+	// TODO: declare the variable answer with "var", the type int and the value 42
+	var answer int
+
+	return answer
+}
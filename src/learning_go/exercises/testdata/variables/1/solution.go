@@ -0,0 +1,8 @@
+package exercise
+
+// Answer returns the answer to everything.
+func Answer() int {
+	var answer int = 42
+
+	return answer
+}
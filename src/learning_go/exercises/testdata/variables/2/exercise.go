@@ -0,0 +1,7 @@
+package exercise
+
+// Swap returns b and a.
+func Swap(a, b string) (string, string) {
+	// TODO: swap a and b with a single assignment
+	return a, b
+}
@@ -0,0 +1,7 @@
+package exercise
+
+// Swap returns b and a.
+func Swap(a, b string) (string, string) {
+	a, b = b, a
+	return a, b
+}
@@ -0,0 +1,8 @@
+package exercise
+
+// Average returns the average of a and b.
+func Average(a, b int) float64 {
+	// TODO: convert before dividing, so that the result keeps its fraction
+	sum := a + b
+	return float64(sum / 2)
+}
@@ -0,0 +1,7 @@
+package exercise
+
+// Average returns the average of a and b.
+func Average(a, b int) float64 {
+	sum := a + b
+	return float64(sum) / 2
+}
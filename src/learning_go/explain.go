@@ -0,0 +1,84 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"gbdmp/learningo/explain"
+)
+
+// runExplain explains an expression given as arguments or on standard input.
+func runExplain(e *env, args []string) error {
+	fs := newFlagSet(e, "explain")
+	format := formatFlag(fs)
+	pos, err := parseArgs(fs, args)
+	if err != nil {
+		return err
+	}
+	src := strings.Join(pos, " ")
+	if len(pos) == 0 || src == "-" {
+		data, err := io.ReadAll(os.Stdin)
+		if err != nil {
+			return err
+		}
+		src = string(data)
+	}
+	x, err := explain.Explain(src)
+	if err != nil {
+		return err
+	}
+
+	t := &table{header: []string{"Expression", "Type", "Value", "Conversion"}, value: x}
+	for _, op := range x.Operands {
+		value := op.Value
+		if op.Exact != "" {
+			value += " (exactly " + op.Exact + ")"
+		}
+		conv := ""
+		switch {
+		case op.ConvertedFrom != "":
+			conv = "implicitly from " + op.ConvertedFrom
+		case op.Untyped:
+			conv = "defaults to " + op.Default
+		}
+		t.add(op.Expr, op.Type, value, conv)
+	}
+	if err := t.write(e.stdout, *format); err != nil || *format != "table" {
+		return err
+	}
+	for _, n := range x.Notes {
+		fmt.Fprintf(e.stdout, "\n%s", wrap(n))
+	}
+	for _, p := range x.Errors {
+		fmt.Fprintf(e.stdout, "\nerror: %s", wrap(p.String()))
+		if p.Why != "" {
+			fmt.Fprintf(e.stdout, "  %s", wrap(p.Why))
+		}
+	}
+	if len(x.Errors) > 0 {
+		return errors.New("does not compile")
+	}
+	return nil
+}
+
+// wrap breaks text into lines of at most 78 characters.
+func wrap(text string) string {
+	var sb strings.Builder
+	n := 0
+	for _, word := range strings.Fields(text) {
+		if n > 0 && n+1+len(word) > 78 {
+			sb.WriteString("\n  ")
+			n = 2
+		} else if n > 0 {
+			sb.WriteByte(' ')
+			n++
+		}
+		sb.WriteString(word)
+		n += len(word)
+	}
+	sb.WriteByte('\n')
+	return sb.String()
+}
@@ -0,0 +1,375 @@
+// Package explain type-checks Go expressions with go/types and tells what
+// the compiler makes of them: the type of every operand, whether it is an
+// untyped constant and what it defaults to, its value, the implicit
+// conversions and why an expression does not compile.
+package explain
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"strings"
+
+	"gbdmp/learningo/playground"
+)
+
+// Operand is an expression and what the type checker found out about it.
+type Operand struct {
+	Expr string `json:"expr"`
+	// Type is the type of the operand where it is used.
+	Type    string `json:"type"`
+	Untyped bool   `json:"untyped"`
+	// Default is the type an untyped constant gets where none is asked
+	// for, as in x := 4.0.
+	Default string `json:"default,omitempty"`
+	// Value is the value of a constant, rounded for printing; Exact is the
+	// exact value if that differs, such as 20/3.
+	Value string `json:"value,omitempty"`
+	Exact string `json:"exact,omitempty"`
+	// ConvertedFrom is the operand's own type if it was converted
+	// implicitly to Type.
+	ConvertedFrom string `json:"converted_from,omitempty"`
+}
+
+// Problem is a type error.
+type Problem struct {
+	Line   int    `json:"line"`
+	Column int    `json:"column"`
+	Msg    string `json:"msg"`
+	Why    string `json:"why,omitempty"`
+}
+
+func (p Problem) String() string {
+	return fmt.Sprintf("%d:%d: %s", p.Line, p.Column, p.Msg)
+}
+
+// Explanation is the result of Explain.
+type Explanation struct {
+	// Expr is the expression that was explained, if the input ended in one.
+	Expr     string    `json:"expr,omitempty"`
+	Operands []Operand `json:"operands"`
+	Errors   []Problem `json:"errors,omitempty"`
+	Notes    []string  `json:"notes,omitempty"`
+}
+
+// Explain explains src, which is an expression, or statements followed by
+// an expression on the last line, such as
+//
+//	a := 1
+//	b := 2.0
+//	a + b
+//
+// Statements without a final expression, or a whole program, are explained
+// expression by expression. The returned error is for input that does not
+// parse; type errors are part of the explanation.
+func Explain(src string) (*Explanation, error) {
+	src = strings.TrimSpace(src)
+	if src == "" {
+		return nil, errors.New("nothing to explain")
+	}
+	prefix, last := "", src
+	if i := strings.LastIndexByte(src, '\n'); i >= 0 {
+		prefix, last = src[:i+1], src[i+1:]
+	}
+	expr, err := parser.ParseExpr(last)
+	if err != nil {
+		expr, prefix = nil, src
+	}
+	prefixLines := strings.Count(prefix, "\n")
+
+	// The expression is also put into the program, so that the packages
+	// it uses are imported.
+	snippet := prefix
+	if expr != nil {
+		snippet += "\n_ = " + last + "\n"
+	}
+	wrapped, line := playground.Wrap(snippet)
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, "main.go", wrapped, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	x := &Explanation{Operands: []Operand{}}
+	info := newInfo()
+	conf := types.Config{
+		Importer: importer.ForCompiler(fset, "source", nil),
+		Error: func(err error) {
+			terr, ok := err.(types.Error)
+			if !ok || terr.Soft && unused(terr.Msg) {
+				return
+			}
+			pos := fset.Position(terr.Pos)
+			if n := line(pos.Line); expr == nil || n <= prefixLines {
+				x.problem(n, pos.Column, terr.Msg)
+			}
+		},
+	}
+	pkg, _ := conf.Check("main", fset, []*ast.File{f}, info)
+
+	if expr == nil {
+		// Explain every expression of the program.
+		var roots []ast.Expr
+		ast.Inspect(f, func(n ast.Node) bool {
+			switch n := n.(type) {
+			case *ast.ImportSpec:
+				return false
+			case ast.Expr:
+				if _, ok := info.Types[n]; ok && interesting(n, info) {
+					roots = append(roots, n)
+					return false
+				}
+			}
+			return true
+		})
+		for _, e := range roots {
+			x.walk(fset, e, info)
+		}
+		return x, nil
+	}
+
+	// Check the expression on its own, in the scope at the end of main, so
+	// that it is not converted to fit an assignment.
+	x.Expr = last
+	body := mainBody(f)
+	if body == nil {
+		return nil, errors.New("cannot find func main")
+	}
+	// CheckExpr needs the expression in the file set of the package.
+	e, err := parser.ParseExprFrom(fset, "", last, 0)
+	if err != nil {
+		return nil, err
+	}
+	einfo := newInfo()
+	if err := types.CheckExpr(fset, pkg, body.Rbrace, e, einfo); err != nil {
+		var terr types.Error
+		if errors.As(err, &terr) {
+			pos := fset.Position(terr.Pos)
+			x.problem(prefixLines+1, pos.Column, terr.Msg)
+		} else {
+			x.problem(prefixLines+1, 0, err.Error())
+		}
+	}
+	x.walk(fset, e, einfo)
+	return x, nil
+}
+
+func newInfo() *types.Info {
+	return &types.Info{
+		Types: map[ast.Expr]types.TypeAndValue{},
+		Uses:  map[*ast.Ident]types.Object{},
+		Defs:  map[*ast.Ident]types.Object{},
+	}
+}
+
+func unused(msg string) bool {
+	return strings.Contains(msg, "declared and not used") || strings.Contains(msg, "imported and not used")
+}
+
+func mainBody(f *ast.File) *ast.BlockStmt {
+	for _, decl := range f.Decls {
+		if fn, ok := decl.(*ast.FuncDecl); ok && fn.Name.Name == "main" && fn.Recv == nil {
+			return fn.Body
+		}
+	}
+	return nil
+}
+
+// interesting reports whether e is worth explaining in a program: a
+// constant, an operation or a conversion.
+func interesting(e ast.Expr, info *types.Info) bool {
+	switch e := e.(type) {
+	case *ast.BasicLit, *ast.BinaryExpr, *ast.UnaryExpr:
+		return true
+	case *ast.Ident:
+		_, ok := info.Uses[e].(*types.Const)
+		return ok && e.Name != "iota"
+	case *ast.CallExpr:
+		return info.Types[e.Fun].IsType()
+	case *ast.ParenExpr:
+		return interesting(e.X, info)
+	}
+	return false
+}
+
+// walk adds e and its operands to x, along with notes on operations.
+func (x *Explanation) walk(fset *token.FileSet, e ast.Expr, info *types.Info) {
+	ast.Inspect(e, func(n ast.Node) bool {
+		e, ok := n.(ast.Expr)
+		if !ok {
+			return true
+		}
+		tv, ok := info.Types[e]
+		if !ok || tv.IsType() || tv.Type == nil {
+			return true
+		}
+		switch tv.Type.(type) {
+		case *types.Signature, *types.Tuple:
+			// Functions and the results of calls are not operands.
+			return true
+		}
+		switch e.(type) {
+		case *ast.ParenExpr:
+			return true
+		case *ast.SelectorExpr:
+			// math.Pi is one operand.
+			x.operand(fset, e, tv, info)
+			return false
+		}
+		if id, ok := e.(*ast.Ident); ok {
+			if _, isPkg := info.Uses[id].(*types.PkgName); isPkg {
+				return false
+			}
+		}
+		x.operand(fset, e, tv, info)
+		if b, ok := e.(*ast.BinaryExpr); ok {
+			x.binaryNotes(fset, b, tv, info)
+		}
+		if c, ok := e.(*ast.CallExpr); ok && info.Types[c.Fun].IsType() && len(c.Args) == 1 {
+			x.note("%s converts %s explicitly to %s.", text(fset, c), text(fset, c.Args[0]), typeString(tv.Type))
+		}
+		return true
+	})
+}
+
+func (x *Explanation) operand(fset *token.FileSet, e ast.Expr, tv types.TypeAndValue, info *types.Info) {
+	op := Operand{Expr: text(fset, e), Type: typeString(tv.Type), Untyped: isUntyped(tv.Type)}
+	if op.Untyped {
+		op.Default = typeString(types.Default(tv.Type))
+	}
+	if tv.Value != nil {
+		op.Value = tv.Value.String()
+		if exact := tv.Value.ExactString(); exact != op.Value && len(exact) <= maxExact {
+			op.Exact = exact
+		}
+	}
+	if own := ownType(e, info); own != nil && !types.Identical(own, tv.Type) {
+		op.ConvertedFrom = typeString(own)
+	}
+	x.Operands = append(x.Operands, op)
+}
+
+// maxExact is the longest exact value that is shown; math.Pi has 63
+// digits.
+const maxExact = 32
+
+// ownType returns the type of a constant before the context converted it,
+// or nil if e is not a constant.
+func ownType(e ast.Expr, info *types.Info) types.Type {
+	switch e := e.(type) {
+	case *ast.BasicLit:
+		switch e.Kind {
+		case token.INT:
+			return types.Typ[types.UntypedInt]
+		case token.FLOAT:
+			return types.Typ[types.UntypedFloat]
+		case token.IMAG:
+			return types.Typ[types.UntypedComplex]
+		case token.CHAR:
+			return types.Typ[types.UntypedRune]
+		case token.STRING:
+			return types.Typ[types.UntypedString]
+		}
+	case *ast.Ident:
+		if c, ok := info.Uses[e].(*types.Const); ok {
+			return c.Type()
+		}
+	case *ast.SelectorExpr:
+		if c, ok := info.Uses[e.Sel].(*types.Const); ok {
+			return c.Type()
+		}
+	}
+	return nil
+}
+
+func (x *Explanation) binaryNotes(fset *token.FileSet, b *ast.BinaryExpr, tv types.TypeAndValue, info *types.Info) {
+	xt, yt := info.Types[b.X].Type, info.Types[b.Y].Type
+	if xt == nil || yt == nil {
+		return
+	}
+	expr := text(fset, b)
+	xown, yown := ownType(b.X, info), ownType(b.Y, info)
+
+	switch {
+	case isUntyped(xt) && isUntyped(yt):
+		if xown != nil && yown != nil && !types.Identical(xown, yown) {
+			x.note("%s mixes an %s and an %s constant: untyped constants of different kinds are converted to the kind that comes later in the list int, rune, float, complex, here %s.",
+				expr, typeString(xown), typeString(yown), typeString(xt))
+		}
+		if tv.Value != nil {
+			value := tv.Value.ExactString()
+			if len(value) > maxExact {
+				value = tv.Value.String()
+			}
+			x.note("%s is a constant expression, evaluated exactly at compile time: %s.", expr, value)
+		}
+	case isUntyped(xown) && !isUntyped(yt):
+		x.note("%s is converted to %s, the type of %s; that is allowed because the constant is representable in %s.", text(fset, b.X), typeString(yt), text(fset, b.Y), typeString(yt))
+	case isUntyped(yown) && !isUntyped(xt):
+		x.note("%s is converted to %s, the type of %s; that is allowed because the constant is representable in %s.", text(fset, b.Y), typeString(xt), text(fset, b.X), typeString(xt))
+	}
+
+	if b.Op == token.QUO {
+		if basic, ok := xt.Underlying().(*types.Basic); ok && basic.Info()&types.IsInteger != 0 {
+			x.note("%s is an integer division: the fraction is cut off (truncated towards zero).", expr)
+		} else if ok && basic.Info()&types.IsFloat != 0 {
+			x.note("%s is a floating-point division.", expr)
+		}
+	}
+}
+
+func (x *Explanation) note(format string, args ...any) {
+	msg := fmt.Sprintf(format, args...)
+	for _, n := range x.Notes {
+		if n == msg {
+			return
+		}
+	}
+	x.Notes = append(x.Notes, msg)
+}
+
+func (x *Explanation) problem(line, col int, msg string) {
+	x.Errors = append(x.Errors, Problem{Line: line, Column: col, Msg: msg, Why: why(msg)})
+}
+
+// why explains the type errors that the lessons run into.
+func why(msg string) string {
+	switch {
+	case strings.Contains(msg, "mismatched types"):
+		return "Go never converts between typed values implicitly, not even between int and float64: convert one operand explicitly, as in float64(a) + b. Only untyped constants adapt to the type of the other operand."
+	case strings.Contains(msg, "overflows"):
+		return "A constant must be representable in the type it is converted to: " + ranges
+	case strings.Contains(msg, "truncated"):
+		return "An untyped float constant converts to an integer type only if it has no fractional part."
+	case strings.Contains(msg, "division by zero"):
+		return "Dividing a constant by the constant zero is a compile-time error; at run time an integer division by zero panics and a floating-point one gives ±Inf or NaN."
+	}
+	return ""
+}
+
+const ranges = "int8 holds -128..127, uint8 0..255, int16 -32768..32767, int32 about ±2.1e9, int64 about ±9.2e18."
+
+func isUntyped(t types.Type) bool {
+	if t == nil {
+		return false
+	}
+	b, ok := t.(*types.Basic)
+	return ok && b.Info()&types.IsUntyped != 0
+}
+
+// typeString writes t with package names rather than paths.
+func typeString(t types.Type) string {
+	return types.TypeString(t, func(p *types.Package) string { return p.Name() })
+}
+
+func text(fset *token.FileSet, e ast.Expr) string {
+	var buf bytes.Buffer
+	format.Node(&buf, fset, e)
+	return buf.String()
+}
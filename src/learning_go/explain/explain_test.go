@@ -0,0 +1,146 @@
+package explain_test
+
+import (
+	"strings"
+	"testing"
+
+	"gbdmp/learningo/explain"
+)
+
+func TestExplain(t *testing.T) {
+	for _, tt := range []struct {
+		src      string
+		operands []explain.Operand // the first operands
+		errors   []string          // line:column: message
+		notes    []string          // the start of every note
+	}{
+		{
+			src: "4.0 == 4",
+			operands: []explain.Operand{
+				{Expr: "4.0 == 4", Type: "untyped bool", Untyped: true, Default: "bool", Value: "true"},
+				{Expr: "4.0", Type: "untyped float", Untyped: true, Default: "float64", Value: "4"},
+				{Expr: "4", Type: "untyped float", Untyped: true, Default: "float64", Value: "4", ConvertedFrom: "untyped int"},
+			},
+			notes: []string{
+				"4.0 == 4 mixes an untyped float and an untyped int constant",
+				"4.0 == 4 is a constant expression, evaluated exactly at compile time: true.",
+			},
+		},
+		{
+			src: "1 << 62",
+			operands: []explain.Operand{
+				{Expr: "1 << 62", Type: "untyped int", Untyped: true, Default: "int", Value: "4611686018427387904"},
+				{Expr: "1", Type: "untyped int", Untyped: true, Default: "int", Value: "1"},
+				{Expr: "62", Type: "untyped int", Untyped: true, Default: "int", Value: "62"},
+			},
+			notes: []string{"1 << 62 is a constant expression, evaluated exactly at compile time: 4611686018427387904."},
+		},
+		{
+			src: "1 << 100",
+			operands: []explain.Operand{
+				{Expr: "1 << 100", Type: "untyped int", Untyped: true, Default: "int", Value: "1267650600228229401496703205376"},
+			},
+			notes: []string{"1 << 100 is a constant expression"},
+		},
+		{
+			src:    "int8(200)",
+			errors: []string{"1:6: constant 200 overflows int8"},
+		},
+		{
+			src:    "x := int8(200)\n_ = x",
+			errors: []string{"1:11: constant 200 overflows int8"},
+		},
+		{
+			// a mixed untyped and typed expression: the constant takes the
+			// type of the variable
+			src: "a := 1.5\na + 2",
+			operands: []explain.Operand{
+				{Expr: "a + 2", Type: "float64"},
+				{Expr: "a", Type: "float64"},
+				{Expr: "2", Type: "float64", Value: "2", ConvertedFrom: "untyped int"},
+			},
+			notes: []string{"2 is converted to float64, the type of a; that is allowed because the constant is representable in float64."},
+		},
+		{
+			src: "x := 7\nx / 2",
+			operands: []explain.Operand{
+				{Expr: "x / 2", Type: "int"},
+			},
+			notes: []string{
+				"2 is converted to int",
+				"x / 2 is an integer division: the fraction is cut off (truncated towards zero).",
+			},
+		},
+		{
+			src:    "var i int = 1\ni + 2.5",
+			errors: []string{"2:5: 2.5 (untyped float constant) truncated to int"},
+		},
+		{
+			src:    "a := 1\nb := 2.0\na + b",
+			errors: []string{"3:1: invalid operation: a + b (mismatched types int and float64)"},
+		},
+		{
+			src:    "1 / 0",
+			errors: []string{"1:5: invalid operation: division by zero"},
+		},
+		{
+			src: "1 + 'a'",
+			operands: []explain.Operand{
+				{Expr: "1 + 'a'", Type: "untyped rune", Untyped: true, Default: "rune", Value: "98"},
+			},
+			notes: []string{"1 + 'a' mixes an untyped int and an untyped rune constant", "1 + 'a' is a constant expression"},
+		},
+		{
+			src: "math.Pi / 2",
+			operands: []explain.Operand{
+				{Expr: "math.Pi / 2", Type: "untyped float", Untyped: true, Default: "float64", Value: "1.5708"},
+				{Expr: "math.Pi", Type: "untyped float", Untyped: true, Default: "float64", Value: "3.14159"},
+			},
+		},
+	} {
+		x, err := explain.Explain(tt.src)
+		if err != nil {
+			t.Errorf("Explain(%q): %v", tt.src, err)
+			continue
+		}
+		if len(x.Operands) < len(tt.operands) {
+			t.Errorf("Explain(%q) has operands %+v, want %+v", tt.src, x.Operands, tt.operands)
+		} else {
+			for i, want := range tt.operands {
+				if x.Operands[i] != want {
+					t.Errorf("Explain(%q): operand %d = %+v, want %+v", tt.src, i, x.Operands[i], want)
+				}
+			}
+		}
+		var errs []string
+		for _, p := range x.Errors {
+			errs = append(errs, p.String())
+			if p.Why == "" {
+				t.Errorf("Explain(%q): no explanation for %s", tt.src, p)
+			}
+		}
+		if strings.Join(errs, "\n") != strings.Join(tt.errors, "\n") {
+			t.Errorf("Explain(%q) errors:\n%s\nwant\n%s", tt.src, strings.Join(errs, "\n"), strings.Join(tt.errors, "\n"))
+		}
+		if tt.notes == nil {
+			continue
+		}
+		if len(x.Notes) != len(tt.notes) {
+			t.Errorf("Explain(%q) notes %q, want %q", tt.src, x.Notes, tt.notes)
+			continue
+		}
+		for i, want := range tt.notes {
+			if !strings.HasPrefix(x.Notes[i], want) {
+				t.Errorf("Explain(%q): note %q, want %q", tt.src, x.Notes[i], want)
+			}
+		}
+	}
+}
+
+func TestExplainErrors(t *testing.T) {
+	for _, src := range []string{"", "  \n", "1 +"} {
+		if x, err := explain.Explain(src); err == nil {
+			t.Errorf("Explain(%q) = %+v, want an error", src, x)
+		}
+	}
+}
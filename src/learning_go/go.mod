@@ -1,3 +1,17 @@
 module gbdmp/learningo
 
 go 1.21.2
+
+require (
+	github.com/mattn/go-runewidth v0.0.13
+	github.com/rivo/uniseg v0.2.0
+	github.com/russross/blackfriday/v2 v2.0.1
+	golang.org/x/exp v0.0.0-20230510235704-dd950f8aeaea
+	golang.org/x/mod v0.12.0
+	gopkg.in/yaml.v3 v3.0.1
+)
+
+require (
+	github.com/pmezard/go-difflib v1.0.0 // indirect
+	github.com/shurcooL/sanitized_anchor_name v1.0.0 // indirect
+)
@@ -0,0 +1,401 @@
+// Package goproxy serves the download cache of a module cache as a module
+// proxy, so that other machines can use it as their GOPROXY.
+//
+// Routes, with MODULE and VERSION case-encoded as in the cache, e.g.
+// github.com/!burnt!sushi/toml:
+//
+//	GET /MODULE/@v/list           the tagged versions, one per line
+//	GET /MODULE/@v/VERSION.info   the version and its time, as JSON
+//	GET /MODULE/@v/VERSION.mod    the go.mod file
+//	GET /MODULE/@v/VERSION.zip    the module zip
+//	GET /MODULE/@latest           the .info of the latest version
+//
+// A module or version the cache does not have is 404 Not Found. A version
+// of which the cache has some files but not the one asked for, like the
+// zip of a version only the go.mod file was needed of, is 410 Gone. Both
+// make the go command try the next proxy in GOPROXY. By default the proxy
+// is read-only and answers from the cache alone; given an upstream proxy,
+// it fetches what the cache is missing from there into the cache first.
+// The version list and the latest version of a module the cache has are
+// always answered from the cache.
+package goproxy
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"gbdmp/learningo/internal/gotool"
+	"gbdmp/learningo/modcache"
+
+	"golang.org/x/mod/module"
+	"golang.org/x/mod/semver"
+)
+
+// Server is an http.Handler serving a module cache. It is safe for
+// concurrent use.
+type Server struct {
+	dir      string // the module cache
+	upstream string // the GOPROXY misses are fetched from, "" if read-only
+}
+
+// NewServer returns a server for the module cache dir. With upstream ""
+// the server is read-only: it answers from the cache alone and never
+// writes to it. Otherwise it fetches what the cache is missing from the
+// proxies in upstream, a GOPROXY list, and adds it to the cache.
+func NewServer(dir, upstream string) *Server {
+	return &Server{dir: dir, upstream: upstream}
+}
+
+// ReadOnly reports whether s never writes to the cache.
+func (s *Server) ReadOnly() bool { return s.upstream == "" }
+
+// errNotFound is the error of a go command that could not find a module
+// or version upstream.
+var errNotFound = errors.New("not found")
+
+// ServeHTTP implements http.Handler.
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		w.Header().Set("Allow", "GET, HEAD")
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	path := strings.TrimPrefix(r.URL.Path, "/")
+	if strings.HasPrefix(path, "sumdb/") {
+		// the go command asks the checksum database itself then
+		http.Error(w, "this proxy does not serve checksum databases", http.StatusNotFound)
+		return
+	}
+
+	var escPath, file string
+	if i := strings.Index(path, "/@v/"); i >= 0 {
+		escPath, file = path[:i], path[i+len("/@v/"):]
+	} else if strings.HasSuffix(path, "/@latest") {
+		escPath = strings.TrimSuffix(path, "/@latest")
+	} else {
+		http.Error(w, "not found", http.StatusNotFound)
+		return
+	}
+	modPath, err := module.UnescapePath(escPath)
+	if err != nil {
+		http.Error(w, "bad request: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	switch ext := filepath.Ext(file); {
+	case file == "":
+		s.latest(w, r, modPath)
+	case file == "list":
+		s.list(w, r, modPath)
+	case ext == ".info" || ext == ".mod" || ext == ".zip":
+		version, err := module.UnescapeVersion(strings.TrimSuffix(file, ext))
+		if err != nil {
+			http.Error(w, "bad request: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+		s.file(w, r, module.Version{Path: modPath, Version: version}, ext)
+	default:
+		http.Error(w, "not found", http.StatusNotFound)
+	}
+}
+
+// file serves the file with the extension ext of mv.
+func (s *Server) file(w http.ResponseWriter, r *http.Request, mv module.Version, ext string) {
+	if err := module.Check(mv.Path, mv.Version); err != nil || module.CanonicalVersion(mv.Version) != mv.Version {
+		// a query like a branch name: only an upstream proxy can resolve
+		// it, and only to the .info of the version it stands for
+		if s.ReadOnly() || ext != ".info" {
+			msg := fmt.Sprintf("%s is not a canonical version", mv)
+			if err != nil {
+				msg = err.Error()
+			}
+			http.Error(w, "not found: "+msg, http.StatusNotFound)
+			return
+		}
+		resolved, err := s.fetch(r.Context(), mv.String())
+		if err != nil {
+			s.fetchError(w, err)
+			return
+		}
+		mv = resolved
+	}
+	path, err := modcache.File(s.dir, mv, ext)
+	if err != nil {
+		http.Error(w, "bad request: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	if _, err := os.Stat(path); errors.Is(err, fs.ErrNotExist) && !s.ReadOnly() {
+		// if upstream does not have it either, the cache answers below
+		if _, err := s.fetch(r.Context(), mv.String()); err != nil && !errors.Is(err, errNotFound) {
+			s.fetchError(w, err)
+			return
+		}
+	}
+	if _, err := os.Stat(path); errors.Is(err, fs.ErrNotExist) {
+		if s.hasVersion(mv) {
+			http.Error(w, fmt.Sprintf("gone: the cache has %s, but not its %s file", mv, ext), http.StatusGone)
+		} else {
+			http.Error(w, fmt.Sprintf("not found: %s is not in the cache", mv), http.StatusNotFound)
+		}
+		return
+	}
+	s.serveFile(w, r, path, contentTypes[ext])
+}
+
+var contentTypes = map[string]string{
+	".info": "application/json",
+	".mod":  "text/plain; charset=utf-8",
+	".zip":  "application/zip",
+}
+
+// list serves the tagged versions of a module in the cache. Only a module
+// the cache does not know is looked up upstream.
+func (s *Server) list(w http.ResponseWriter, r *http.Request, modPath string) {
+	if !s.hasModule(modPath) && !s.ReadOnly() {
+		versions, err := s.fetchList(r.Context(), modPath)
+		if err != nil {
+			s.fetchError(w, err)
+			return
+		}
+		writeList(w, r, versions)
+		return
+	}
+	var versions []string
+	for _, v := range s.versions(modPath) {
+		if !module.IsPseudoVersion(v) && s.has(module.Version{Path: modPath, Version: v}, ".mod") {
+			versions = append(versions, v)
+		}
+	}
+	if versions == nil && !s.hasModule(modPath) {
+		http.Error(w, fmt.Sprintf("not found: %s is not in the cache", modPath), http.StatusNotFound)
+		return
+	}
+	writeList(w, r, versions)
+}
+
+func writeList(w http.ResponseWriter, r *http.Request, versions []string) {
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	if r.Method == http.MethodHead {
+		return
+	}
+	for _, v := range versions {
+		fmt.Fprintln(w, v)
+	}
+}
+
+// latest serves the .info of the latest version of a module in the cache:
+// the highest release, or the highest pre-release if there is none, or
+// the highest pseudo-version if there is no tag at all, as the go command
+// picks it. Only if the cache has no version is it looked up upstream.
+func (s *Server) latest(w http.ResponseWriter, r *http.Request, modPath string) {
+	best, bestRank := "", -1
+	for _, v := range s.versions(modPath) {
+		if !s.has(module.Version{Path: modPath, Version: v}, ".info") {
+			continue
+		}
+		rank := 2
+		if module.IsPseudoVersion(v) {
+			rank = 0
+		} else if semver.Prerelease(v) != "" {
+			rank = 1
+		}
+		if rank > bestRank || rank == bestRank && semver.Compare(v, best) > 0 {
+			best, bestRank = v, rank
+		}
+	}
+	if best == "" && !s.ReadOnly() {
+		mv, err := s.fetch(r.Context(), modPath+"@latest")
+		if err != nil {
+			s.fetchError(w, err)
+			return
+		}
+		s.serveInfo(w, r, mv)
+		return
+	}
+	if best == "" {
+		http.Error(w, fmt.Sprintf("not found: no version of %s is in the cache", modPath), http.StatusNotFound)
+		return
+	}
+	s.serveInfo(w, r, module.Version{Path: modPath, Version: best})
+}
+
+// serveInfo serves the .info file of mv.
+func (s *Server) serveInfo(w http.ResponseWriter, r *http.Request, mv module.Version) {
+	path, err := modcache.File(s.dir, mv, ".info")
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	s.serveFile(w, r, path, contentTypes[".info"])
+}
+
+func (s *Server) serveFile(w http.ResponseWriter, r *http.Request, path, contentType string) {
+	f, err := os.Open(path)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	defer f.Close()
+	fi, err := f.Stat()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", contentType)
+	http.ServeContent(w, r, "", fi.ModTime(), f)
+}
+
+// versions returns the versions of a module the cache has files of, in
+// semver order.
+func (s *Server) versions(modPath string) []string {
+	dir, err := s.atV(modPath)
+	if err != nil {
+		return nil
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil
+	}
+	seen := map[string]bool{}
+	var versions []string
+	for _, e := range entries {
+		ext := filepath.Ext(e.Name())
+		if ext != ".info" && ext != ".mod" && ext != ".zip" {
+			continue
+		}
+		v, err := module.UnescapeVersion(strings.TrimSuffix(e.Name(), ext))
+		if err != nil || seen[v] {
+			continue
+		}
+		seen[v] = true
+		versions = append(versions, v)
+	}
+	sort.Slice(versions, func(i, j int) bool { return semver.Compare(versions[i], versions[j]) < 0 })
+	return versions
+}
+
+// atV returns the directory of the files of a module in the cache.
+func (s *Server) atV(modPath string) (string, error) {
+	escPath, err := module.EscapePath(modPath)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(modcache.DownloadDir(s.dir), filepath.FromSlash(escPath), "@v"), nil
+}
+
+// has reports whether the cache has the file with the extension ext of mv.
+func (s *Server) has(mv module.Version, ext string) bool {
+	path, err := modcache.File(s.dir, mv, ext)
+	if err != nil {
+		return false
+	}
+	_, err = os.Stat(path)
+	return err == nil
+}
+
+// hasVersion reports whether the cache has any file of mv.
+func (s *Server) hasVersion(mv module.Version) bool {
+	return s.has(mv, ".info") || s.has(mv, ".mod") || s.has(mv, ".zip")
+}
+
+// hasModule reports whether the cache knows the module at all.
+func (s *Server) hasModule(modPath string) bool {
+	dir, err := s.atV(modPath)
+	if err != nil {
+		return false
+	}
+	_, err = os.Stat(dir)
+	return err == nil
+}
+
+// fetch downloads the module version query, e.g. "mod@v1.2.3" or
+// "mod@latest", from upstream into the cache with "go mod download" and
+// returns the version it stands for.
+func (s *Server) fetch(ctx context.Context, query string) (module.Version, error) {
+	var out struct {
+		Path, Version, Error string
+	}
+	err := s.goJSON(ctx, &out, "mod", "download", "-json", query)
+	if out.Error != "" {
+		return module.Version{}, upstreamError(out.Error)
+	}
+	if err != nil {
+		return module.Version{}, err
+	}
+	return module.Version{Path: out.Path, Version: out.Version}, nil
+}
+
+// fetchList returns the tagged versions of a module upstream.
+func (s *Server) fetchList(ctx context.Context, modPath string) ([]string, error) {
+	var out struct {
+		Versions []string
+		Error    *struct{ Err string }
+	}
+	err := s.goJSON(ctx, &out, "list", "-m", "-versions", "-json", modPath+"@latest")
+	if out.Error != nil {
+		return nil, upstreamError(out.Error.Err)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return out.Versions, nil
+}
+
+// goJSON runs the go command against the cache and upstream, outside of
+// any module, and decodes its JSON output into v. The output is decoded
+// even if the command fails, as it carries the error; without output,
+// the error is on stderr.
+func (s *Server) goJSON(ctx context.Context, v any, args ...string) error {
+	cmd := exec.CommandContext(ctx, gotool.Path(), args...)
+	cmd.Dir = os.TempDir()
+	cmd.Env = append(os.Environ(),
+		"GOMODCACHE="+s.dir,
+		"GOPROXY="+s.upstream,
+		"GOFLAGS=-mod=mod",
+		"GO111MODULE=on",
+		"GOWORK=off",
+		"GOTOOLCHAIN=local",
+	)
+	out, runErr := cmd.Output()
+	if err := json.Unmarshal(out, v); err != nil {
+		var ee *exec.ExitError
+		if errors.As(runErr, &ee) {
+			return upstreamError(strings.TrimSpace(string(ee.Stderr)))
+		}
+		if runErr != nil {
+			return runErr
+		}
+		return err
+	}
+	return nil
+}
+
+// upstreamError turns the error the go command reported for a module
+// into errNotFound if it means that upstream does not have the module.
+func upstreamError(msg string) error {
+	for _, s := range []string{"not found", "unknown revision", "invalid version", "no matching versions", "no such file or directory", "404", "410"} {
+		if strings.Contains(msg, s) {
+			return fmt.Errorf("%w: %s", errNotFound, msg)
+		}
+	}
+	return errors.New(msg)
+}
+
+// fetchError answers a request that needed upstream and failed: 404 if
+// upstream does not have the module, 502 Bad Gateway otherwise.
+func (s *Server) fetchError(w http.ResponseWriter, err error) {
+	if errors.Is(err, errNotFound) {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	http.Error(w, "upstream: "+err.Error(), http.StatusBadGateway)
+}
@@ -0,0 +1,230 @@
+package goproxy_test
+
+import (
+	"archive/zip"
+	"bytes"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"gbdmp/learningo/goproxy"
+	"gbdmp/learningo/modcache"
+
+	"golang.org/x/mod/module"
+	modzip "golang.org/x/mod/zip"
+)
+
+// addVersion puts the files of mv with the extensions exts into the
+// download directory dl, like the go command or a GOPROXY=file:// tree
+// has them.
+func addVersion(t *testing.T, dl string, mv module.Version, exts ...string) {
+	t.Helper()
+	escPath, _ := module.EscapePath(mv.Path)
+	escVersion, _ := module.EscapeVersion(mv.Version)
+	atv := filepath.Join(dl, filepath.FromSlash(escPath), "@v")
+	if err := os.MkdirAll(atv, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	gomod := "module " + mv.Path + "\n\ngo 1.21\n"
+	for _, ext := range exts {
+		var data []byte
+		switch ext {
+		case ".info":
+			data = []byte(`{"Version":"` + mv.Version + `","Time":"2023-10-13T00:00:00Z"}`)
+		case ".mod":
+			data = []byte(gomod)
+		case ".zip":
+			var buf bytes.Buffer
+			files := []modzip.File{zipFile{"go.mod", gomod}, zipFile{"x.go", "package x\n"}}
+			if err := modzip.Create(&buf, mv, files); err != nil {
+				t.Fatal(err)
+			}
+			data = buf.Bytes()
+		}
+		if err := os.WriteFile(filepath.Join(atv, escVersion+ext), data, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	f, err := os.OpenFile(filepath.Join(atv, "list"), os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0o644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.WriteString(mv.Version + "\n")
+	f.Close()
+}
+
+type zipFile struct{ path, content string }
+
+func (f zipFile) Path() string { return f.path }
+func (f zipFile) Lstat() (os.FileInfo, error) {
+	return fakeInfo{filepath.Base(f.path), int64(len(f.content))}, nil
+}
+func (f zipFile) Open() (io.ReadCloser, error) {
+	return io.NopCloser(strings.NewReader(f.content)), nil
+}
+
+type fakeInfo struct {
+	name string
+	size int64
+}
+
+func (fi fakeInfo) Name() string       { return fi.name }
+func (fi fakeInfo) Size() int64        { return fi.size }
+func (fi fakeInfo) Mode() os.FileMode  { return 0o644 }
+func (fi fakeInfo) ModTime() time.Time { return time.Time{} }
+func (fi fakeInfo) IsDir() bool        { return false }
+func (fi fakeInfo) Sys() any           { return nil }
+
+var (
+	toml   = module.Version{Path: "github.com/BurntSushi/toml", Version: "v1.3.2"}
+	tomlRC = module.Version{Path: "github.com/BurntSushi/toml", Version: "v1.4.0-rc.1"}
+	pseudo = module.Version{Path: "example.com/untagged", Version: "v0.0.0-20231013000000-abcdefabcdef"}
+)
+
+func newCache(t *testing.T) string {
+	dir := filepath.Join(t.TempDir(), "pkg", "mod")
+	dl := modcache.DownloadDir(dir)
+	addVersion(t, dl, toml, ".info", ".mod", ".zip")
+	addVersion(t, dl, tomlRC, ".mod") // only needed for its go.mod
+	addVersion(t, dl, pseudo, ".info", ".mod", ".zip")
+	t.Cleanup(func() { makeWritable(dir) })
+	return dir
+}
+
+// makeWritable lets t.TempDir clean up modules the go command extracted.
+func makeWritable(dir string) {
+	filepath.WalkDir(dir, func(path string, d os.DirEntry, err error) error {
+		if err == nil && d.IsDir() {
+			os.Chmod(path, 0o755)
+		}
+		return nil
+	})
+}
+
+func get(t *testing.T, h http.Handler, method, path string) *httptest.ResponseRecorder {
+	t.Helper()
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(method, path, nil))
+	return w
+}
+
+func TestReadOnly(t *testing.T) {
+	s := goproxy.NewServer(newCache(t), "")
+	if !s.ReadOnly() {
+		t.Fatal("a server without upstream is not read-only")
+	}
+	for _, tt := range []struct {
+		method, path string
+		status       int
+		body         string
+	}{
+		{"GET", "/github.com/!burnt!sushi/toml/@v/list", 200, "v1.3.2\nv1.4.0-rc.1\n"},
+		{"GET", "/github.com/!burnt!sushi/toml/@v/v1.3.2.info", 200, `{"Version":"v1.3.2"`},
+		{"GET", "/github.com/!burnt!sushi/toml/@v/v1.3.2.mod", 200, "module github.com/BurntSushi/toml\n"},
+		{"HEAD", "/github.com/!burnt!sushi/toml/@v/v1.3.2.zip", 200, ""},
+		{"GET", "/github.com/!burnt!sushi/toml/@latest", 200, `{"Version":"v1.3.2"`},
+		{"GET", "/example.com/untagged/@v/list", 200, ""},
+		{"GET", "/example.com/untagged/@latest", 200, `{"Version":"v0.0.0-20231013000000-abcdefabcdef"`},
+
+		// the go.mod file only: 410 for the rest, so that the go command
+		// tries the next proxy
+		{"GET", "/github.com/!burnt!sushi/toml/@v/v1.4.0-rc.1.mod", 200, "module"},
+		{"GET", "/github.com/!burnt!sushi/toml/@v/v1.4.0-rc.1.zip", 410, "gone"},
+		{"GET", "/github.com/!burnt!sushi/toml/@v/v1.4.0-rc.1.info", 410, "gone"},
+		{"GET", "/github.com/!burnt!sushi/toml/@v/v9.9.9.zip", 404, "not found"},
+		{"GET", "/example.com/missing/@v/list", 404, "not found"},
+		{"GET", "/example.com/missing/@latest", 404, "not found"},
+		{"GET", "/github.com/!burnt!sushi/toml/@v/master.info", 404, "not a semantic version"},
+		{"GET", "/github.com/!burnt!sushi/toml/@v/v1.3.info", 404, "not a canonical version"},
+
+		// the cache is case-encoded: an upper-case letter in a path is bad
+		{"GET", "/github.com/BurntSushi/toml/@v/list", 400, "bad request"},
+		{"GET", "/github.com/!burnt!sushi/toml/@v/V1.3.2.mod", 400, "bad request"},
+		{"GET", "/github.com/!burnt!sushi/toml", 404, ""},
+		{"GET", "/github.com/!burnt!sushi/toml/@v/v1.3.2.ziphash", 404, ""},
+		{"GET", "/sumdb/sum.golang.org/supported", 404, "checksum"},
+		{"POST", "/github.com/!burnt!sushi/toml/@v/list", 405, ""},
+	} {
+		w := get(t, s, tt.method, tt.path)
+		if w.Code != tt.status || !strings.Contains(w.Body.String(), tt.body) {
+			t.Errorf("%s %s = %d %q, want %d with %q", tt.method, tt.path, w.Code, w.Body, tt.status, tt.body)
+		}
+	}
+}
+
+func TestServesTheZip(t *testing.T) {
+	dir := newCache(t)
+	w := get(t, goproxy.NewServer(dir, ""), "GET", "/github.com/!burnt!sushi/toml/@v/v1.3.2.zip")
+	if got := w.Header().Get("Content-Type"); got != "application/zip" {
+		t.Errorf("Content-Type = %q", got)
+	}
+	r, err := zip.NewReader(bytes.NewReader(w.Body.Bytes()), int64(w.Body.Len()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(r.File) != 2 || r.File[0].Name != toml.String()+"/go.mod" {
+		t.Errorf("zip has %d files, the first %q", len(r.File), r.File[0].Name)
+	}
+}
+
+// TestCacheFirst checks that the version list and the latest version of
+// a module the cache has come from the cache, not from upstream, which
+// has a newer version here.
+func TestCacheFirst(t *testing.T) {
+	if testing.Short() {
+		t.Skip("may run the go command")
+	}
+	t.Setenv("GOSUMDB", "off")
+	upstream := filepath.Join(t.TempDir(), "upstream")
+	addVersion(t, upstream, toml, ".info", ".mod", ".zip")
+	addVersion(t, upstream, module.Version{Path: toml.Path, Version: "v1.5.0"}, ".info", ".mod", ".zip")
+
+	s := goproxy.NewServer(newCache(t), (&url.URL{Scheme: "file", Path: filepath.ToSlash(upstream)}).String())
+	for _, tt := range []struct {
+		path, body string
+	}{
+		{"/github.com/!burnt!sushi/toml/@v/list", "v1.3.2\nv1.4.0-rc.1\n"},
+		{"/github.com/!burnt!sushi/toml/@latest", `"v1.3.2"`},
+		{"/github.com/!burnt!sushi/toml/@v/v1.3.2.mod", "module"},
+	} {
+		w := get(t, s, "GET", tt.path)
+		if w.Code != 200 || !strings.Contains(w.Body.String(), tt.body) {
+			t.Errorf("GET %s = %d %q, want 200 with %q", tt.path, w.Code, w.Body, tt.body)
+		}
+	}
+}
+
+func TestFill(t *testing.T) {
+	if testing.Short() {
+		t.Skip("runs the go command")
+	}
+	t.Setenv("GOSUMDB", "off")
+	t.Setenv("GONOSUMDB", "")
+	upstream := filepath.Join(t.TempDir(), "upstream")
+	fetched := module.Version{Path: "example.com/fetched", Version: "v1.0.0"}
+	addVersion(t, upstream, fetched, ".info", ".mod", ".zip")
+
+	dir := newCache(t)
+	s := goproxy.NewServer(dir, (&url.URL{Scheme: "file", Path: filepath.ToSlash(upstream)}).String())
+	if s.ReadOnly() {
+		t.Fatal("a server with an upstream is read-only")
+	}
+	if w := get(t, s, "GET", "/example.com/fetched/@v/list"); w.Code != 200 || w.Body.String() != "v1.0.0\n" {
+		t.Errorf("list of a module only upstream has = %d %q", w.Code, w.Body)
+	}
+	if w := get(t, s, "GET", "/example.com/fetched/@v/v1.0.0.zip"); w.Code != 200 {
+		t.Fatalf("zip of a module only upstream has = %d %q", w.Code, w.Body)
+	}
+	if _, err := os.Stat(filepath.Join(modcache.DownloadDir(dir), "example.com", "fetched", "@v", "v1.0.0.zip")); err != nil {
+		t.Errorf("the zip was not added to the cache: %v", err)
+	}
+	if w := get(t, s, "GET", "/example.com/nowhere/@v/v1.0.0.mod"); w.Code != 404 {
+		t.Errorf("a module upstream does not have either = %d %q, want 404", w.Code, w.Body)
+	}
+}
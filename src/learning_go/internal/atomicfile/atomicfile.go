@@ -0,0 +1,59 @@
+// Package atomicfile replaces files atomically and serializes updates of
+// them across processes.
+package atomicfile
+
+import (
+	"os"
+	"path/filepath"
+)
+
+// Write writes a file by writing a temporary file next to it and renaming
+// it into place, so readers see either the old or the new content but
+// never a partial write. The file gets the permissions perm.
+func Write(path string, perm os.FileMode, write func(f *os.File) error) error {
+	dir := filepath.Dir(path)
+	f, err := os.CreateTemp(dir, "."+filepath.Base(path)+".tmp*")
+	if err != nil {
+		return err
+	}
+	tmp := f.Name()
+	defer os.Remove(tmp) // fails harmlessly once the file has been renamed
+
+	if err := write(f); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(tmp, perm); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return err
+	}
+	return syncDir(dir)
+}
+
+// WriteFile is like os.WriteFile but replaces the file atomically.
+func WriteFile(path string, data []byte, perm os.FileMode) error {
+	return Write(path, perm, func(f *os.File) error {
+		_, err := f.Write(data)
+		return err
+	})
+}
+
+// syncDir flushes a directory so that a rename in it survives a crash.
+// Not every platform can sync directories, so errors are ignored.
+func syncDir(dir string) error {
+	d, err := os.Open(dir)
+	if err != nil {
+		return nil
+	}
+	d.Sync()
+	return d.Close()
+}
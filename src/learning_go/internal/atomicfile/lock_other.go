@@ -0,0 +1,9 @@
+//go:build !unix
+
+package atomicfile
+
+// Lock does not lock anything: file locks are only taken on Unix
+// systems. Writes stay atomic, but concurrent updates may get lost.
+func Lock(path string) (unlock func() error, err error) {
+	return func() error { return nil }, nil
+}
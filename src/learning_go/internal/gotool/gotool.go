@@ -0,0 +1,22 @@
+// Package gotool locates the go command.
+package gotool
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+)
+
+// Path returns the go command of the toolchain this program was built
+// with, falling back to "go" from $PATH.
+func Path() string {
+	exe := "go"
+	if runtime.GOOS == "windows" {
+		exe += ".exe"
+	}
+	p := filepath.Join(runtime.GOROOT(), "bin", exe)
+	if _, err := os.Stat(p); err == nil {
+		return p
+	}
+	return "go"
+}
@@ -0,0 +1,64 @@
+// Package modroot finds the gbdmp/learningo module and the checkout it
+// lives in.
+package modroot
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Path is the module path of this module.
+const Path = "gbdmp/learningo"
+
+// Find returns the directory of the gbdmp/learningo module, looking in dir
+// and its parents as well as in src/learning_go below them.
+func Find(dir string) (string, error) {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return "", err
+	}
+	for {
+		for _, candidate := range []string{dir, filepath.Join(dir, "src", "learning_go")} {
+			if isModule(candidate) {
+				return candidate, nil
+			}
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", errors.New("cannot find the " + Path + " module; run from within the go_dev checkout")
+		}
+		dir = parent
+	}
+}
+
+func isModule(dir string) bool {
+	data, err := os.ReadFile(filepath.Join(dir, "go.mod"))
+	if err != nil {
+		return false
+	}
+	sc := bufio.NewScanner(bytes.NewReader(data))
+	for sc.Scan() {
+		if rest, ok := strings.CutPrefix(strings.TrimSpace(sc.Text()), "module"); ok {
+			return strings.Trim(strings.TrimSpace(rest), `"`) == Path
+		}
+	}
+	return false
+}
+
+// Checkout returns the root of the go_dev checkout that holds the module
+// found from dir: the GOPATH directory with bin, pkg and src.
+func Checkout(dir string) (string, error) {
+	mod, err := Find(dir)
+	if err != nil {
+		return "", err
+	}
+	src := filepath.Dir(mod)
+	if filepath.Base(src) != "src" {
+		return "", errors.New(mod + " is not in the src directory of a GOPATH checkout")
+	}
+	return filepath.Dir(src), nil
+}
@@ -0,0 +1,86 @@
+package textdiff_test
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"gbdmp/learningo/internal/textdiff"
+)
+
+// numbers returns the lines 1 to n with the replacements in repl.
+func numbers(n int, repl map[int]string) string {
+	var sb strings.Builder
+	for i := 1; i <= n; i++ {
+		if s, ok := repl[i]; ok {
+			sb.WriteString(s + "\n")
+			continue
+		}
+		fmt.Fprintln(&sb, i)
+	}
+	return sb.String()
+}
+
+// The expected diffs are those of GNU diff -u.
+func TestUnified(t *testing.T) {
+	for _, tt := range []struct {
+		name string
+		a, b string
+		want string
+	}{
+		{"equal", "a\nb\n", "a\nb\n", ""},
+		{"append", "a\nb\n", "a\nb\nc\n", "@@ -1,2 +1,3 @@\n a\n b\n+c\n"},
+		{"prepend", "a\nb\n", "x\na\nb\n", "@@ -1,2 +1,3 @@\n+x\n a\n b\n"},
+		{"from nothing", "", "a\nb\n", "@@ -0,0 +1,2 @@\n+a\n+b\n"},
+		{"to nothing", "a\nb\n", "", "@@ -1,2 +0,0 @@\n-a\n-b\n"},
+		{"single lines", "a\n", "b\n", "@@ -1 +1 @@\n-a\n+b\n"},
+		{"without a final newline", "a\nb", "a\nc", "@@ -1,2 +1,2 @@\n a\n-b\n+c\n"},
+		{
+			// six unchanged lines between two changes: one hunk
+			"joined hunks",
+			numbers(20, nil),
+			numbers(20, map[int]string{5: "five", 12: "twelve"}),
+			"@@ -2,14 +2,14 @@\n 2\n 3\n 4\n-5\n+five\n 6\n 7\n 8\n 9\n 10\n 11\n-12\n+twelve\n 13\n 14\n 15\n",
+		},
+		{
+			// more than six: two hunks
+			"separate hunks",
+			numbers(20, nil),
+			numbers(20, map[int]string{3: "three", 17: "x"}),
+			"@@ -1,6 +1,6 @@\n 1\n 2\n-3\n+three\n 4\n 5\n 6\n" +
+				"@@ -14,7 +14,7 @@\n 14\n 15\n 16\n-17\n+x\n 18\n 19\n 20\n",
+		},
+		{
+			"replaced by blank lines",
+			numbers(10, nil),
+			numbers(10, map[int]string{5: "", 6: ""}),
+			"@@ -2,8 +2,8 @@\n 2\n 3\n 4\n-5\n-6\n+\n+\n 7\n 8\n 9\n",
+		},
+	} {
+		want := tt.want
+		if want != "" {
+			want = "--- old\n+++ new\n" + want
+		}
+		if got := textdiff.Unified("old", "new", tt.a, tt.b); got != want {
+			t.Errorf("%s: Unified =\n%s\nwant\n%s", tt.name, got, want)
+		}
+	}
+}
+
+func TestLines(t *testing.T) {
+	for _, tt := range []struct {
+		s    string
+		want []string
+	}{
+		{"", nil},
+		{"\n", []string{""}},
+		{"a", []string{"a"}},
+		{"a\nb\n", []string{"a", "b"}},
+		{"a\n\nb", []string{"a", "", "b"}},
+	} {
+		got := textdiff.Lines(tt.s)
+		if fmt.Sprintf("%q", got) != fmt.Sprintf("%q", tt.want) || (got == nil) != (tt.want == nil) {
+			t.Errorf("Lines(%q) = %q, want %q", tt.s, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,183 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"gbdmp/learningo/internal/modroot"
+	"gbdmp/learningo/lessons"
+	"gbdmp/learningo/progress"
+)
+
+func runLessons(e *env, args []string) error {
+	if len(args) == 0 {
+		return errUsage
+	}
+	switch args[0] {
+	case "list":
+		return runLessonsList(e, args[1:])
+	case "run":
+		return runLessonsRun(e, args[1:])
+	case "verify":
+		return runLessonsVerify(e, args[1:])
+	case "exercise":
+		return runLessonsExercise(e, args[1:])
+	case "check":
+		return runLessonsCheck(e, args[1:])
+	case "progress":
+		return runLessonsProgress(e, args[1:])
+	case "convert":
+		return runLessonsConvert(e, args[1:])
+	case "literate":
+		return runLessonsLiterate(e, args[1:])
+	case "extract":
+		return runLessonsExtract(e, args[1:])
+	case "site":
+		return runLessonsSite(e, args[1:])
+	}
+	return errUsage
+}
+
+func runLessonsList(e *env, args []string) error {
+	fs := newFlagSet(e, "lessons list")
+	topic := fs.String("topic", "", "only list the lessons of this `topic`")
+	format := formatFlag(fs)
+	pos, err := parseArgs(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(pos) != 0 {
+		return errUsage
+	}
+	list := lessons.All()
+	if *topic != "" {
+		list = lessons.Topic(*topic)
+	}
+
+	type lessonRow struct {
+		Order int    `json:"order"`
+		Name  string `json:"name"`
+		Topic string `json:"topic"`
+		Title string `json:"title"`
+	}
+	t := &table{header: []string{"#", "Name", "Topic", "Title"}}
+	rows := []lessonRow{}
+	for _, l := range list {
+		rows = append(rows, lessonRow{Order: l.Order, Name: l.Name, Topic: l.Topic, Title: l.Title})
+		t.add(strconv.Itoa(l.Order), l.Name, l.Topic, l.Title)
+	}
+	t.value = rows
+	return t.write(e.stdout, *format)
+}
+
+func runLessonsRun(e *env, args []string) error {
+	fs := newFlagSet(e, "lessons run")
+	topic := fs.String("topic", "", "run every lesson of this `topic`")
+	learner := learnerFlag(fs)
+	visual := fs.Bool("visual", false, "run the visual mode of the lessons that have one")
+	pos, err := parseArgs(fs, args)
+	if err != nil {
+		return err
+	}
+
+	var list []lessons.Lesson
+	for _, name := range pos {
+		l, ok := lessons.Lookup(name)
+		if !ok {
+			return fmt.Errorf("unknown lesson %q, see \"learningo lessons list\"", name)
+		}
+		list = append(list, l)
+	}
+	if *topic != "" {
+		for _, l := range lessons.Topic(*topic) {
+			if _, ok := lessons.Visual(l); ok || !*visual {
+				list = append(list, l)
+			}
+		}
+	}
+	if len(list) == 0 {
+		return errUsage
+	}
+	if *visual {
+		for i, l := range list {
+			v, ok := lessons.Visual(l)
+			if !ok {
+				return fmt.Errorf("lesson %s has no visual mode", l.Name)
+			}
+			list[i] = v
+		}
+	}
+	var events []progress.Event
+	for i, l := range list {
+		if len(list) > 1 {
+			if i > 0 {
+				fmt.Fprintln(e.stdout)
+			}
+			fmt.Fprintf(e.stdout, "=== %s: %s\n", l.Name, l.Title)
+		}
+		l.Run(e.stdout)
+		name, _ := strings.CutSuffix(l.Name, ".visual")
+		events = append(events, progress.Event{Time: time.Now(), Kind: progress.Run, Lesson: name})
+	}
+	e.record(*learner, events...)
+	return nil
+}
+
+// runLessonsVerify runs lessons.Verify like the tests of the lessons
+// package do, for the lessons given and with the comparison of choice.
+func runLessonsVerify(e *env, args []string) error {
+	module, err := modroot.Find(".")
+	if err != nil {
+		return err
+	}
+	fs := newFlagSet(e, "lessons verify")
+	dir := fs.String("dir", filepath.Join(module, "lessons", lessons.GoldenDir), "golden file `directory`")
+	update := fs.Bool("update", false, "rewrite the golden files with the current output")
+	compare := fs.String("compare", "", "compare `mode` for every lesson: exact, sorted or set (default: the lesson's own)")
+	pos, err := parseArgs(fs, args)
+	if err != nil {
+		return err
+	}
+	list := lessons.All()
+	if len(pos) > 0 {
+		list = nil
+		for _, name := range pos {
+			l, ok := lessons.Lookup(name)
+			if !ok {
+				return fmt.Errorf("unknown lesson %q", name)
+			}
+			list = append(list, l)
+		}
+	}
+	list = lessons.WithVisual(list)
+
+	failed := 0
+	for _, l := range list {
+		mode := l.Compare
+		if *compare != "" {
+			if mode, err = lessons.ParseCompare(*compare); err != nil {
+				return err
+			}
+		}
+		res, err := lessons.Verify(l, *dir, mode, *update)
+		switch {
+		case err != nil:
+			failed++
+			fmt.Fprintf(e.stdout, "FAIL %s: %v\n", l.Name, err)
+		case res.Updated:
+			fmt.Fprintf(e.stdout, "updated %s\n", lessons.GoldenFile(*dir, l))
+		case res.OK:
+			fmt.Fprintf(e.stdout, "ok   %s (%s)\n", l.Name, mode)
+		default:
+			failed++
+			fmt.Fprintf(e.stdout, "FAIL %s (%s)\n%s", l.Name, mode, res.Diff)
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %d lessons failed", failed, len(list))
+	}
+	return nil
+}
@@ -0,0 +1,167 @@
+package lessons
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"gbdmp/learningo/internal/textdiff"
+	"gbdmp/learningo/people"
+)
+
+// Compare tells how the output of a lesson is compared with its golden
+// file.
+type Compare int
+
+const (
+	// Exact compares the output byte by byte.
+	Exact Compare = iota
+	// Sorted compares the sorted lines of the output, for lessons that
+	// print in map iteration order.
+	Sorted
+	// Set compares the distinct lines of the output, ignoring order and
+	// repetitions.
+	Set
+)
+
+var compareNames = []string{Exact: "exact", Sorted: "sorted", Set: "set"}
+
+func (c Compare) String() string {
+	if int(c) < len(compareNames) {
+		return compareNames[c]
+	}
+	return fmt.Sprintf("Compare(%d)", int(c))
+}
+
+// ParseCompare returns the comparison mode with the given name.
+func ParseCompare(name string) (Compare, error) {
+	for c, n := range compareNames {
+		if n == name {
+			return Compare(c), nil
+		}
+	}
+	return 0, fmt.Errorf("unknown comparison %q, want exact, sorted or set", name)
+}
+
+// GoldenDate is "today" while lessons are captured, so that the ages in
+// the golden files stay the same.
+var GoldenDate = people.NewDate(2023, time.October, 13)
+
+// Capture runs l on GoldenDate and returns its output. A panicking
+// lesson is reported as an error together with the output so far.
+func Capture(l Lesson) (out string, err error) {
+	now := people.Now
+	people.Now = func() time.Time { return GoldenDate.Time() }
+	defer func() { people.Now = now }()
+
+	var buf bytes.Buffer
+	defer func() {
+		if e := recover(); e != nil {
+			out, err = buf.String(), fmt.Errorf("lesson %s panicked: %v", l.Name, e)
+		}
+	}()
+	l.Run(&buf)
+	return buf.String(), nil
+}
+
+// GoldenDir is the directory of the golden files, relative to the
+// directory of this package.
+var GoldenDir = filepath.Join("testdata", "golden")
+
+// WithVisual returns list with the visual mode of every lesson that has
+// one added after it, the lessons that have golden files.
+func WithVisual(list []Lesson) []Lesson {
+	var all []Lesson
+	for _, l := range list {
+		all = append(all, l)
+		if v, ok := Visual(l); ok {
+			all = append(all, v)
+		}
+	}
+	return all
+}
+
+// GoldenFile returns the path of the golden file of l in dir.
+func GoldenFile(dir string, l Lesson) string {
+	return filepath.Join(dir, l.Name+".golden")
+}
+
+// Result is the outcome of verifying one lesson.
+type Result struct {
+	Lesson  Lesson
+	Compare Compare
+	OK      bool
+	Updated bool
+	// Diff is a unified diff from the golden file to the output, in the
+	// normalized form the comparison works on.
+	Diff string
+}
+
+// Verify captures the output of l and compares it with its golden file in
+// dir using mode. With update set, a golden file that does not match is
+// rewritten instead.
+func Verify(l Lesson, dir string, mode Compare, update bool) (Result, error) {
+	res := Result{Lesson: l, Compare: mode}
+	got, err := Capture(l)
+	if err != nil {
+		return res, err
+	}
+	path := GoldenFile(dir, l)
+	if update {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			return res, err
+		}
+		old, err := os.ReadFile(path)
+		if err == nil && mode.Normalize(string(old)) == mode.Normalize(got) {
+			res.OK = true
+			return res, nil
+		}
+		if err := os.WriteFile(path, []byte(got), 0o644); err != nil {
+			return res, err
+		}
+		res.OK, res.Updated = true, true
+		return res, nil
+	}
+
+	want, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return res, fmt.Errorf("no golden file for lesson %s; create it with -update", l.Name)
+	}
+	if err != nil {
+		return res, err
+	}
+	a, b := mode.Normalize(string(want)), mode.Normalize(got)
+	res.OK = a == b
+	if !res.OK {
+		res.Diff = textdiff.Unified(path, l.Name+" output", a, b)
+	}
+	return res, nil
+}
+
+// Normalize brings output into the form in which c compares it.
+func (c Compare) Normalize(s string) string {
+	if c == Exact {
+		return s
+	}
+	lines := textdiff.Lines(s)
+	sort.Strings(lines)
+	if c == Set {
+		uniq := lines[:0]
+		for i, l := range lines {
+			if i == 0 || l != lines[i-1] {
+				uniq = append(uniq, l)
+			}
+		}
+		lines = uniq
+	}
+	if len(lines) == 0 {
+		return ""
+	}
+	return strings.Join(lines, "\n") + "\n"
+}
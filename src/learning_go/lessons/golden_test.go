@@ -0,0 +1,49 @@
+package lessons_test
+
+import (
+	"flag"
+	"testing"
+
+	"gbdmp/learningo/lessons"
+)
+
+var update = flag.Bool("update", false, "rewrite the golden files with the current lesson output")
+
+// TestGolden compares the output of every lesson with its golden file in
+// the lesson's own comparison mode and in the looser modes after it: a
+// lesson that matches exactly also matches sorted and as a set.
+func TestGolden(t *testing.T) {
+	for _, l := range lessons.WithVisual(lessons.All()) {
+		for mode := l.Compare; mode <= lessons.Set; mode++ {
+			l, mode := l, mode
+			t.Run(l.Name+"/"+mode.String(), func(t *testing.T) {
+				res, err := lessons.Verify(l, lessons.GoldenDir, mode, *update && mode == l.Compare)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if !res.OK {
+					t.Errorf("output differs from %s:\n%s", lessons.GoldenFile(lessons.GoldenDir, l), res.Diff)
+				}
+			})
+		}
+	}
+}
+
+func TestNormalize(t *testing.T) {
+	in := "b\na\nb\n"
+	for _, tt := range []struct {
+		mode lessons.Compare
+		want string
+	}{
+		{lessons.Exact, in},
+		{lessons.Sorted, "a\nb\nb\n"},
+		{lessons.Set, "a\nb\n"},
+	} {
+		if got := tt.mode.Normalize(in); got != tt.want {
+			t.Errorf("%s.Normalize(%q) = %q, want %q", tt.mode, in, got, tt.want)
+		}
+	}
+	if got := lessons.Sorted.Normalize(""); got != "" {
+		t.Errorf("Sorted.Normalize(\"\") = %q, want \"\"", got)
+	}
+}
@@ -0,0 +1,121 @@
+package lessons
+
+import (
+	"fmt"
+	"io"
+
+	"gbdmp/learningo/slicetrace"
+)
+
+func init() {
+	Register(Lesson{Name: "arrays_and_slices", Topic: "primitive_types", Title: "Arrays and Slices", Order: 6, Run: learnArraysAndSlices, Visual: visualArraysAndSlices})
+}
+
+func learnArraysAndSlices(w io.Writer) {
+	// creating an array of a fixed length string types including assignment:
+	names := [3]string{"Gerd", "Karolina", "Tim"}
+
+	fmt.Fprintln(w, names)
+
+	// separating declaration from assignment:
+	var names2 [4]string
+	names2[0] = "Gerd"
+	names2[1] = "Karolina"
+	names2[2] = "Tim"
+
+	fmt.Fprintln(w, "is names2[3] asigned: ", names2[3] != "")
+
+	var myInt int
+	fmt.Fprintln(w, myInt)
+
+	// growing or schrinking arrays requires slices
+	// definition of a slice is comparable to an array, but without defining the length:
+	// delaring an empty slice of string values
+	names_slice := []string{}
+
+	// for adding values to the slice we use the append function:
+	names_slice = append(names_slice, "gerd")
+
+	fmt.Fprintln(w, names_slice)
+
+	// multiple append:
+	names_slice = append(names_slice, "karolina", "tim", "helena")
+	fmt.Fprintln(w, names_slice)
+
+	// the make function can be used to allocate a minimum of values:
+	// example:
+	names_minimum := make([]string, 4)
+
+	// this avoids that we need to append values all the time
+	names_minimum[0] = "gerd"
+	names_minimum[1] = "karolina"
+	names_minimum[2] = "tim"
+	names_minimum[3] = "helena"
+
+	fmt.Fprintln(w, "slice assigned with the make function: ", names_minimum)
+
+	names_minimum = append(names_minimum, "chiara")
+
+	fmt.Fprintln(w, "slice assigned with the make function and appended an additional value: ", names_minimum)
+
+	// len is the number of values in the slice, cap the number of values
+	// the backing array has room for. "learningo lessons run -visual
+	// arrays_and_slices" shows when append needs a new array:
+	fmt.Fprintln(w, "len:", len(names_minimum), "cap:", cap(names_minimum))
+}
+
+// visualArraysAndSlices is the lesson above with the slices traced and drawn.
+func visualArraysAndSlices(w io.Writer) {
+	// every append either fits into the capacity of the backing array, or
+	// allocates a bigger array and copies the values over:
+	t := slicetrace.New("[]string{}", []string{})
+	t.Append("gerd")
+	t.Append("karolina", "tim", "helena")
+	t.Append("chiara")
+	t.Write(w)
+	fmt.Fprintln(w)
+
+	// make allocates the array up front, the fifth value does not fit:
+	t = slicetrace.New("make([]string, 4)", make([]string, 4))
+	t.Append("chiara")
+	t.Write(w)
+	fmt.Fprintln(w)
+
+	// appending one value at a time, the capacity doubles:
+	ints := slicetrace.New("[]int(nil)", []int(nil))
+	for i := 1; i <= 9; i++ {
+		ints.Append(i)
+	}
+	ints.Write(w)
+	fmt.Fprintln(w)
+
+	// a sub-slice shares the backing array of the slice it is cut from:
+	arrays := &slicetrace.Arrays{}
+	draw := func(a, b []int) {
+		d := slicetrace.Diagram[int]{Arrays: arrays}
+		d.Add("a", a)
+		d.Add("b", b)
+		d.Write(w)
+		fmt.Fprintln(w)
+	}
+	a := make([]int, 4, 5)
+	for i := range a {
+		a[i] = i + 1
+	}
+	b := a[1:3]
+	fmt.Fprintln(w, "a := make([]int, 4, 5); b := a[1:3]")
+	draw(a, b)
+
+	// appending to b writes into the spare capacity, that is over a[3]:
+	b = append(b, 99)
+	fmt.Fprintln(w, "b = append(b, 99)")
+	draw(a, b)
+
+	// a fills the last free value, the next append moves a to a new array.
+	// from then on a and b no longer see each other's changes:
+	a = append(a, 5)
+	a = append(a, 6)
+	b[0] = 42
+	fmt.Fprintln(w, "a = append(a, 5); a = append(a, 6); b[0] = 42")
+	draw(a, b)
+}
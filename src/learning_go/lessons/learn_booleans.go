@@ -0,0 +1,30 @@
+package lessons
+
+import (
+	"fmt"
+	"io"
+)
+
+func init() {
+	Register(Lesson{Name: "booleans", Topic: "primitive_types", Title: "Booleans", Order: 4, Run: learnBooleans})
+}
+
+func learnBooleans(w io.Writer) {
+	fmt.Fprintln(w, "Greater than: ", 1 > 2)
+	fmt.Fprintln(w, "Less than: ", 1 < 2)
+	fmt.Fprintln(w, "Greater or equal than: ", 1 >= 2)
+
+	// even tough that float and integer are two different types of primitives, the equivalent works:
+	// 4.0 and 4 are untyped constants, so the 4 becomes a float before they are compared. "learningo explain '4.0 == 4'" shows how
+	fmt.Fprintln(w, "Equivalent: ", 4.0 == 4)
+	fmt.Fprintln(w, "Not equivalent: ", 4.0 != 4)
+
+	var err error = nil // Initializing with nil
+
+	// Checking if err is not nil (indicating an error)
+	if err != nil {
+		fmt.Fprintln(w, "Error: ", err)
+	} else {
+		fmt.Fprintln(w, "No error, everything is fine.")
+	}
+}
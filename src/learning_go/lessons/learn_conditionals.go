@@ -0,0 +1,73 @@
+package lessons
+
+import (
+	"fmt"
+	"io"
+
+	"gbdmp/learningo/milestones"
+	"gbdmp/learningo/people"
+)
+
+func init() {
+	Register(Lesson{Name: "conditionals", Topic: "conditionals", Title: "Conditionals", Order: 9, Run: learnConditionals})
+}
+
+func learnConditionals(w io.Writer) {
+	// in go, an array that does not have a numerical index is called a map.
+	// comparable to other programming languages where this is called "associative array" or "dictionary" a map can be created with key/value pairs:
+
+	// the ages are computed from the birthdays kept in the people registry:
+	ages := people.Family().Ages()
+
+	fmt.Fprintln(w, ages)
+
+	// access to a specfic key:
+	fmt.Fprintln(w, ages["Gerd"])
+
+	// the ages at which somebody can vote or retire are kept as rules in the milestones package:
+	rules := milestones.DefaultRules()
+	vote, _ := rules.Find("vote")
+	retire, _ := rules.Find("retire")
+
+	// conditionals example with if, else if and else
+	if ages["Gerd"] < vote.Age {
+		fmt.Fprintln(w, "you cant vote")
+	} else if ages["Gerd"] < retire.Age {
+		fmt.Fprintln(w, "not ready for retirement")
+	} else {
+		fmt.Fprintln(w, "go to retirement")
+	}
+
+	// example with the switch case statement:
+	switch {
+	case ages["Gerd"] < vote.Age:
+		fmt.Fprintln(w, "you cant vote")
+	case ages["Gerd"] < retire.Age:
+		fmt.Fprintln(w, "not ready for retirement")
+	default:
+		fmt.Fprintln(w, "go to retirement")
+	}
+
+	// advanced switch statement over every milestone that has been reached:
+	reached := rules.Reached(ages["Gerd"])
+	if len(reached) == 0 {
+		fmt.Fprintln(w, "there is noting special about the age")
+	}
+	for _, milestone := range reached {
+		switch milestone.Name {
+		case "small-prime":
+			fmt.Fprintln(w, "age is a small prime number")
+		case "drive":
+			fmt.Fprintln(w, "can drive")
+		case "vote":
+			fmt.Fprintln(w, "can vote")
+		case "retire":
+			fmt.Fprintln(w, "can retire now")
+		}
+	}
+
+	// the rules also tell which milestone comes next:
+	if next, age, ok := rules.Next(ages["Gerd"]); ok {
+		fmt.Fprintln(w, "next milestone at", age, ":", next[0])
+	}
+}
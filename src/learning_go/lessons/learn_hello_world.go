@@ -0,0 +1,22 @@
+package lessons
+
+import (
+	"fmt"
+	"io"
+)
+
+/*
+this is a multi-line comment.
+everything between the open slash/star and
+the end star/slash is a comment
+they are not used very much
+*/
+
+func init() {
+	Register(Lesson{Name: "hello_world", Topic: "hello_world", Title: "Hello World", Order: 1, Run: learnHelloWorld})
+}
+
+// main is the primary function of a program, here it is the body of the lesson:
+func learnHelloWorld(w io.Writer) {
+	fmt.Fprintln(w, "Hello World!") // trailing comment
+}
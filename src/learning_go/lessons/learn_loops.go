@@ -0,0 +1,76 @@
+package lessons
+
+import (
+	"fmt"
+	"io"
+
+	"gbdmp/learningo/milestones"
+	"gbdmp/learningo/people"
+)
+
+func init() {
+	Register(Lesson{Name: "loops", Topic: "loops", Title: "Loops", Order: 8, Run: learnLoops, Compare: Sorted})
+}
+
+func learnLoops(w io.Writer) {
+	// the ages are computed from the birthdays kept in the people registry:
+	ages := people.Family().Ages()
+
+	fmt.Fprintln(w, ages)
+
+	// for
+	for name, age := range ages {
+		fmt.Fprintln(w, "name =", name, " is ", age, " years old")
+	}
+
+	// the ages at which somebody can drive, vote or retire are kept as rules in the milestones package:
+	rules := milestones.DefaultRules()
+
+	// iterating of a map using the range operator:
+	for name, age := range ages {
+		reached := rules.Reached(age)
+		if len(reached) == 0 {
+			fmt.Fprintf(w, "there is noting special about the age of %s\n", name)
+		}
+		for _, milestone := range reached {
+			switch milestone.Name {
+			case "small-prime":
+				fmt.Fprintln(w, name, "'s age is a small prime number")
+			case "drive":
+				fmt.Fprintln(w, name, " can drive")
+			case "vote":
+				fmt.Fprintln(w, name, " can vote")
+			case "retire":
+				fmt.Fprintln(w, name, " can retire now")
+			}
+		}
+	}
+
+	// access to a specfic key:
+	fmt.Fprintln(w, ages["Gerd"])
+
+	// traditional C style for loop:
+	for i := 0; i <= 10; i++ {
+		fmt.Fprintln(w, i)
+	}
+
+	// another way doing this:
+	a := 0
+	for a < 10 {
+		fmt.Fprintln(w, "count ", a)
+		a++
+	}
+
+	// continue and break:
+	a = 0
+	for a < 10 {
+		if a%2 == 0 {
+			a++
+			continue
+		} else if a == 5 {
+			break
+		}
+		fmt.Fprintln(w, a, "is unequal")
+		a++
+	}
+}
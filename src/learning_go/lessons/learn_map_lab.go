@@ -0,0 +1,96 @@
+package lessons
+
+import (
+	"fmt"
+	"io"
+	"slices"
+	"sort"
+	"strings"
+
+	"golang.org/x/exp/maps"
+
+	"gbdmp/learningo/people"
+)
+
+func init() {
+	Register(Lesson{Name: "map_lab", Topic: "maps", Title: "Map Lab", Order: 10, Run: learnMapLab})
+}
+
+func learnMapLab(w io.Writer) {
+	// the maps, loops and conditionals lessons use maps without looking at the details.
+	// this lab tries out how maps behave, starting with the ages of the family:
+	ages := people.Family().Ages()
+
+	// go picks a random order every time a map is ranged over, so a program cannot rely on it.
+	// looping 100 times over the same map collects the orders the loop saw:
+	orders := map[string]bool{}
+	for i := 0; i < 100; i++ {
+		var order []string
+		for name := range ages {
+			order = append(order, name)
+		}
+		orders[strings.Join(order, " ")] = true
+	}
+	fmt.Fprintln(w, "more than one order in 100 loops:", len(orders) > 1)
+
+	// fmt sorts the keys when it prints a map, which hides the random order:
+	fmt.Fprintln(w, ages)
+
+	// a missing key gives the zero value of the value type. Chiara is not in the map,
+	// but the result looks like the age of a new born baby:
+	fmt.Fprintln(w, "age of Chiara:", ages["Chiara"])
+
+	// the "comma ok" form tells a missing key from a stored zero value:
+	age, ok := ages["Chiara"]
+	fmt.Fprintln(w, "age of Chiara:", age, "found:", ok)
+	if age, ok := ages["Tim"]; ok {
+		fmt.Fprintln(w, "age of Tim:", age, "found:", ok)
+	}
+
+	// deleting entries while ranging over the map is safe, a deleted entry is not visited anymore.
+	// adding entries is allowed too, but they may or may not be visited by the same loop:
+	adults := people.Family().Ages()
+	for name, age := range adults {
+		if age < 18 {
+			delete(adults, name)
+		}
+	}
+	fmt.Fprintln(w, "adults:", adults)
+
+	// a map that is declared but not made is nil. reading from it works like an empty map:
+	var nobody map[string]int
+	fmt.Fprintln(w, "nil map:", nobody == nil, "len:", len(nobody), "age of Gerd:", nobody["Gerd"])
+
+	// but writing to it panics, maps need make or a literal first:
+	func() {
+		defer func() {
+			fmt.Fprintln(w, "writing to a nil map:", recover())
+		}()
+		nobody["Gerd"] = 56
+	}()
+	nobody = make(map[string]int)
+	nobody["Gerd"] = 56
+	fmt.Fprintln(w, "after make:", nobody)
+
+	// for a fixed order, collect the keys into a slice and sort it:
+	names := make([]string, 0, len(ages))
+	for name := range ages {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		fmt.Fprintln(w, name, ages[name])
+	}
+
+	// the same with maps.Keys from golang.org/x/exp and slices.Sort:
+	keys := maps.Keys(ages)
+	slices.Sort(keys)
+	fmt.Fprintln(w, "sorted keys:", keys)
+
+	// sorting by value, the youngest first. sort.SliceStable keeps the sorted names in order
+	// when two people have the same age:
+	sort.SliceStable(names, func(i, j int) bool { return ages[names[i]] < ages[names[j]] })
+	for _, name := range names {
+		fmt.Fprintln(w, name, ages[name])
+	}
+}
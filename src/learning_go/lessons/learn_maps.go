@@ -0,0 +1,48 @@
+package lessons
+
+import (
+	"fmt"
+	"io"
+
+	"gbdmp/learningo/people"
+)
+
+func init() {
+	Register(Lesson{Name: "maps", Topic: "primitive_types", Title: "Maps", Order: 7, Run: learnMaps})
+}
+
+func learnMaps(w io.Writer) {
+	// in go, an array that does not have a numerical index is called a map.
+	// comparable to other programming languages where this is called "associative array" or "dictionary" a map can be created with key/value pairs:
+
+	// the family members and their birthdays are kept in the people registry:
+	family := people.Family()
+
+	// the declaration of a map needs the type of the key in square brakets and the type of the value behind the square brakets
+	// the values of this map are typed dates, not strings, so they can be compared and used to compute ages:
+	birthdays := map[string]people.Date{}
+	ages := map[string]int{}
+
+	// values are assigned by giving the map and the key in square brakets:
+	for _, person := range family.List() {
+		birthdays[person.Name] = person.Birthday
+		ages[person.Name] = person.Age()
+	}
+	fmt.Fprintln(w, birthdays)
+
+	// birthdays written as DD.MM.YYYY text have to be parsed into dates, which rejects dates that do not exist:
+	helena, err := people.ParseDate("13.10.2005")
+	fmt.Fprintln(w, "parsed: ", helena, err)
+	_, err = people.ParseDate("31.02.2015")
+	fmt.Fprintln(w, "rejected: ", err)
+
+	fmt.Fprintln(w, ages)
+
+	// access to a specfic key. a key that is not in the map gives 0 as well, the map_lab lesson shows how to tell them apart:
+	fmt.Fprintln(w, ages["Gerd"])
+
+	// delete values from a map, giving the map and the key to be deleted:
+	delete(birthdays, "Gerd")
+	fmt.Fprintln(w, birthdays)
+
+}
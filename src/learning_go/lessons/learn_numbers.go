@@ -0,0 +1,30 @@
+package lessons
+
+import (
+	"fmt"
+	"io"
+	"math"
+)
+
+func init() {
+	Register(Lesson{Name: "numbers", Topic: "primitive_types", Title: "Numbers", Order: 3, Run: learnNumbers})
+}
+
+func learnNumbers(w io.Writer) {
+	fmt.Fprintln(w, "Addition: ", 1+3) // the experession 1+3 will be calculated as an experession before it put to the fmt.Println function
+	fmt.Fprintln(w, "Substraction: ", 27-13)
+	fmt.Fprintln(w, "Multiplication: ", 9*11)
+	fmt.Fprintln(w, "Division: ", 20/4)
+
+	// integers vs. floats, "learningo explain 20/3" and "learningo explain 20.0/3" show why the results differ
+	fmt.Fprintln(w, "Important concept in go: types are not going to be converted.\nExample: ")
+	fmt.Fprintln(w, "Division of 20 divided by 3 =  ", 20/3)     // when working with Integers, go will give us an Integer back
+	fmt.Fprintln(w, "Division of 20.0 divided by 3 =  ", 20.0/3) // when working with Floats, go will give us a Float back
+
+	// every type has its limits: "learningo numbers 100+100" runs an expression in all
+	// integer and float types and math/big, int8 wraps around to -56
+	// and "learningo numbers 0.1+0.2" shows the rounding of floats
+
+	// use the math functions:
+	fmt.Fprintln(w, "Exponents: ", math.Pow(7, 3))
+}
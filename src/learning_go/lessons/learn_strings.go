@@ -0,0 +1,33 @@
+package lessons
+
+import (
+	"fmt"
+	"io"
+	"unicode/utf8"
+)
+
+func init() {
+	Register(Lesson{Name: "strings", Topic: "primitive_types", Title: "Strings and Runes", Order: 5, Run: learnStrings})
+}
+
+func learnStrings(w io.Writer) {
+	simple := "Simple string\n" //interpreted string literal, the \n is turned into a newline
+	fmt.Fprintln(w, simple)
+	fmt.Fprintln(w, `
+		this is a multi line \n
+		statement...
+	`)
+	fmt.Fprintln(w, "\u2272")
+	// go does not allow to use double quotes and single quotes interchangablely for string
+	// this is because single quotes are used for "runes". A rune is a single character that could be used in a string
+	fmt.Fprintln(w, 'G') // this is an example of a rune. It will return the corresponding number of the character
+
+	// a string is stored as UTF-8 bytes: len counts the bytes, range and
+	// utf8.RuneCountInString count the runes. a rune can take up to 4 bytes,
+	// "learningo runes 'Grüße ≲'" lists every byte, rune and character:
+	greeting := "Grüße ≲"
+	fmt.Fprintln(w, "bytes:", len(greeting), "runes:", utf8.RuneCountInString(greeting), len([]rune(greeting)))
+	for i, r := range greeting {
+		fmt.Fprintf(w, "byte %d: %c %U\n", i, r, r)
+	}
+}
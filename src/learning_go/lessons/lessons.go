@@ -0,0 +1,130 @@
+// Package lessons holds the lesson programs of the curriculum. Every
+// learn_*.go file registers its lesson under a name and a topic so that
+// lessons can be listed and run one by one.
+package lessons
+
+import (
+	"embed"
+	"fmt"
+	"io"
+	"path"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"sort"
+)
+
+// Lesson is a runnable lesson.
+type Lesson struct {
+	Name  string // unique name, e.g. "maps"
+	Topic string // the group the lesson belongs to, e.g. "primitive_types"
+	Title string
+	// Order is the position of the lesson in the curriculum.
+	Order int
+	// Run writes the output of the lesson to w.
+	Run func(w io.Writer)
+	// Compare is how the output is checked against the golden file.
+	Compare Compare
+	// Visual, if set, writes a variant of the lesson that draws what
+	// happens in memory instead of printing values.
+	Visual func(w io.Writer)
+}
+
+var registry = map[string]Lesson{}
+
+// Register adds a lesson. It panics if the name is taken, which can only
+// be a programming error.
+func Register(l Lesson) {
+	if _, ok := registry[l.Name]; ok {
+		panic("lessons: duplicate lesson " + l.Name)
+	}
+	registry[l.Name] = l
+}
+
+// Lookup returns the lesson with the given name.
+func Lookup(name string) (Lesson, bool) {
+	l, ok := registry[name]
+	return l, ok
+}
+
+// All returns every lesson in curriculum order.
+func All() []Lesson {
+	list := make([]Lesson, 0, len(registry))
+	for _, l := range registry {
+		list = append(list, l)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		if list[i].Order != list[j].Order {
+			return list[i].Order < list[j].Order
+		}
+		return list[i].Name < list[j].Name
+	})
+	return list
+}
+
+// Topic returns the lessons of a topic in curriculum order.
+func Topic(topic string) []Lesson {
+	var list []Lesson
+	for _, l := range All() {
+		if l.Topic == topic {
+			list = append(list, l)
+		}
+	}
+	return list
+}
+
+// Topics returns the topics in curriculum order.
+func Topics() []string {
+	var topics []string
+	seen := map[string]bool{}
+	for _, l := range All() {
+		if !seen[l.Topic] {
+			seen[l.Topic] = true
+			topics = append(topics, l.Topic)
+		}
+	}
+	return topics
+}
+
+//go:embed learn_*.go
+var sources embed.FS
+
+// Source returns the name and the content of the file that defines the
+// body of l. The lesson files are embedded in the program, so this does
+// not depend on where it was built or on -trimpath.
+func Source(l Lesson) (name string, src []byte, err error) {
+	fn := runtime.FuncForPC(reflect.ValueOf(l.Run).Pointer())
+	if fn == nil {
+		return "", nil, fmt.Errorf("lesson %s: cannot find the function it runs", l.Name)
+	}
+	file, _ := fn.FileLine(fn.Entry())
+	name = path.Base(filepath.ToSlash(file))
+	src, err = sources.ReadFile(name)
+	if err != nil {
+		return "", nil, fmt.Errorf("lesson %s: %s is not one of the embedded lesson files", l.Name, name)
+	}
+	return name, src, nil
+}
+
+// Visual returns the visual mode of l as a lesson of its own, named
+// l.Name + ".visual", so that it can be run and verified like any other.
+func Visual(l Lesson) (Lesson, bool) {
+	if l.Visual == nil {
+		return Lesson{}, false
+	}
+	v := l
+	v.Name = l.Name + ".visual"
+	v.Run, v.Visual = l.Visual, nil
+	v.Compare = Exact
+	return v, true
+}
+
+// Run runs the named lesson, writing its output to w.
+func Run(name string, w io.Writer) error {
+	l, ok := Lookup(name)
+	if !ok {
+		return fmt.Errorf("unknown lesson %q", name)
+	}
+	l.Run(w)
+	return nil
+}
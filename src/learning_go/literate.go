@@ -0,0 +1,234 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"gbdmp/learningo/internal/atomicfile"
+	"gbdmp/learningo/internal/modroot"
+	"gbdmp/learningo/lessons"
+	"gbdmp/learningo/literate"
+	"gbdmp/learningo/site"
+)
+
+// literateDir is where the Markdown lessons are kept in the module.
+var literateDir = filepath.Join("lessons", "literate")
+
+// markdownLessons returns the lesson files named in pos, or all lesson files
+// of dir.
+func markdownLessons(dir string, pos []string) ([]string, error) {
+	if len(pos) > 0 {
+		return pos, nil
+	}
+	files, err := filepath.Glob(filepath.Join(dir, "*"+literate.Ext))
+	if err != nil {
+		return nil, err
+	}
+	if len(files) == 0 {
+		return nil, fmt.Errorf("no lessons in %s", dir)
+	}
+	sort.Strings(files)
+	return files, nil
+}
+
+// runLessonsConvert turns registered lessons into Markdown lessons and
+// records their output.
+func runLessonsConvert(e *env, args []string) error {
+	module, err := modroot.Find(".")
+	if err != nil {
+		return err
+	}
+	fs := newFlagSet(e, "lessons convert")
+	dir := fs.String("dir", filepath.Join(module, literateDir), "output `directory`")
+	force := fs.Bool("force", false, "overwrite existing lesson files")
+	pos, err := parseArgs(fs, args)
+	if err != nil {
+		return err
+	}
+	list := lessons.All()
+	if len(pos) > 0 {
+		list = nil
+		for _, name := range pos {
+			l, ok := lessons.Lookup(name)
+			if !ok {
+				return fmt.Errorf("unknown lesson %q", name)
+			}
+			list = append(list, l)
+		}
+	}
+	if err := os.MkdirAll(*dir, 0o755); err != nil {
+		return err
+	}
+
+	ctx := context.Background()
+	for _, l := range list {
+		file := filepath.Join(*dir, l.Name+literate.Ext)
+		if _, err := os.Stat(file); err == nil && !*force {
+			return fmt.Errorf("%s exists, use -force to overwrite it", file)
+		}
+		d, err := literate.Convert(l)
+		if err != nil {
+			return err
+		}
+		res, err := literate.Record(ctx, d, module)
+		if err != nil {
+			return err
+		}
+		if !res.OK() {
+			return fmt.Errorf("converted lesson does not run:\n%s", res)
+		}
+		if err := atomicfile.WriteFile(file, d.Markdown(), 0o644); err != nil {
+			return err
+		}
+		fmt.Fprintf(e.stdout, "wrote %s\n", file)
+	}
+	return nil
+}
+
+// runLessonsLiterate builds and runs Markdown lessons and compares their
+// output blocks, or rewrites them with -record.
+func runLessonsLiterate(e *env, args []string) error {
+	module, err := modroot.Find(".")
+	if err != nil {
+		return err
+	}
+	fs := newFlagSet(e, "lessons literate")
+	dir := fs.String("dir", filepath.Join(module, literateDir), "lesson `directory`")
+	record := fs.Bool("record", false, "rewrite the output blocks with what the programs print")
+	pos, err := parseArgs(fs, args)
+	if err != nil {
+		return err
+	}
+	files, err := markdownLessons(*dir, pos)
+	if err != nil {
+		return err
+	}
+
+	ctx := context.Background()
+	failed := 0
+	for _, file := range files {
+		d, err := literate.ReadFile(file)
+		if err != nil {
+			return err
+		}
+		if *record {
+			res, err := literate.Record(ctx, d, module)
+			if err != nil {
+				return err
+			}
+			if !res.OK() {
+				failed++
+				fmt.Fprint(e.stdout, res)
+				continue
+			}
+			if err := atomicfile.WriteFile(file, d.Markdown(), 0o644); err != nil {
+				return err
+			}
+			fmt.Fprintf(e.stdout, "updated %s\n", file)
+			continue
+		}
+		res, err := literate.Verify(ctx, d, module)
+		if err != nil {
+			return err
+		}
+		if !res.OK() {
+			failed++
+		}
+		fmt.Fprint(e.stdout, res)
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %d lessons failed", failed, len(files))
+	}
+	return nil
+}
+
+// runLessonsExtract writes the program of a Markdown lesson as a module of
+// its own.
+func runLessonsExtract(e *env, args []string) error {
+	fs := newFlagSet(e, "lessons extract")
+	out := fs.String("o", "", "output `directory` (default: the lesson name)")
+	pos, err := parseArgs(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(pos) != 1 {
+		return errUsage
+	}
+	d, err := literate.ReadFile(pos[0])
+	if err != nil {
+		return err
+	}
+	if *out == "" {
+		*out = d.Meta.Name
+	}
+	module, err := modroot.Find(".")
+	if err != nil && d.Uses(modroot.Path) {
+		return err
+	}
+	if err := literate.Extract(d, *out, module); err != nil {
+		return err
+	}
+	fmt.Fprintf(e.stdout, "wrote %s, run it with: cd %s && go run .\n", filepath.Join(*out, "main.go"), *out)
+	return nil
+}
+
+// runLessonsSite generates the static HTML site of the curriculum from the
+// Markdown lessons; registered lessons that have none are converted on the
+// way.
+func runLessonsSite(e *env, args []string) error {
+	module, err := modroot.Find(".")
+	if err != nil {
+		return err
+	}
+	fs := newFlagSet(e, "lessons site")
+	out := fs.String("o", "_site", "output `directory`")
+	dir := fs.String("dir", filepath.Join(module, literateDir), "Markdown lesson `directory`")
+	title := fs.String("title", "Learning Go", "site `title`")
+	pos, err := parseArgs(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(pos) != 0 {
+		return errUsage
+	}
+
+	var docs []*literate.Document
+	have := map[string]bool{}
+	files, err := filepath.Glob(filepath.Join(*dir, "*"+literate.Ext))
+	if err != nil {
+		return err
+	}
+	for _, file := range files {
+		d, err := literate.ReadFile(file)
+		if err != nil {
+			return err
+		}
+		docs = append(docs, d)
+		have[d.Meta.Name] = true
+	}
+	for _, l := range lessons.All() {
+		if have[l.Name] {
+			continue
+		}
+		d, err := literate.Convert(l)
+		if err != nil {
+			return err
+		}
+		if res, err := literate.Record(context.Background(), d, module); err != nil {
+			return err
+		} else if !res.OK() {
+			return fmt.Errorf("lesson %s does not run:\n%s", l.Name, res)
+		}
+		docs = append(docs, d)
+	}
+
+	s := site.New(*title, docs)
+	if err := s.Write(*out); err != nil {
+		return err
+	}
+	fmt.Fprintf(e.stdout, "wrote %d lessons to %s\n", len(s.Lessons), filepath.Join(*out, "index.html"))
+	return nil
+}
@@ -0,0 +1,276 @@
+package literate
+
+import (
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	"gbdmp/learningo/lessons"
+)
+
+// Convert turns the source of a registered lesson into a literate lesson.
+// The comments of the lesson function become prose and the statements
+// between them go blocks, which print to standard output instead of the
+// lesson's writer. Output blocks are left to Record.
+func Convert(l lessons.Lesson) (*Document, error) {
+	file, src, err := lessons.Source(l)
+	if err != nil {
+		return nil, err
+	}
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, file, src, parser.ParseComments)
+	if err != nil {
+		return nil, err
+	}
+	fn := lessonFunc(f, l)
+	if fn == nil {
+		return nil, fmt.Errorf("lesson %s: cannot find its function in %s", l.Name, file)
+	}
+	c := &converter{fset: fset, src: src, w: writerParam(fn)}
+	c.rewrite(fn.Body)
+
+	d := &Document{Meta: Meta{Name: l.Name, Title: l.Title, Topic: l.Topic, Order: l.Order, Today: lessons.GoldenDate}}
+	if l.Compare != lessons.Exact {
+		d.Meta.Compare = l.Compare.String()
+	}
+	if prev := previous(l); prev != "" {
+		d.Meta.Prerequisites = []string{prev}
+	}
+	d.Blocks = append(d.Blocks, Block{Kind: Prose, Text: "# " + l.Title + "\n"})
+
+	// Comments outside of the lesson function, like the introduction of
+	// hello world, come first.
+	var inner []*ast.CommentGroup
+	for _, cg := range f.Comments {
+		switch {
+		case cg.Pos() > fn.Body.Lbrace && cg.End() < fn.Body.Rbrace:
+			inner = append(inner, cg)
+		case cg.End() < fn.Body.Lbrace && !insideDecl(f, cg, fn):
+			d.Blocks = append(d.Blocks, Block{Kind: Prose, Text: cg.Text()})
+		}
+	}
+
+	// The statements of the body are grouped into go blocks, split by the
+	// comments that stand on their own lines.
+	var run []ast.Stmt
+	flush := func() {
+		if len(run) > 0 {
+			d.Blocks = append(d.Blocks, Block{Kind: Code, Text: c.code(run[0], run[len(run)-1])})
+			run = nil
+		}
+	}
+	stmts := fn.Body.List
+	for len(stmts) > 0 || len(inner) > 0 {
+		if len(inner) > 0 && (len(stmts) == 0 || inner[0].Pos() < stmts[0].Pos()) {
+			cg := inner[0]
+			inner = inner[1:]
+			if len(run) > 0 && c.line(cg.Pos()) == c.line(run[len(run)-1].End()) {
+				continue // a trailing comment stays with its code
+			}
+			flush()
+			d.Blocks = append(d.Blocks, Block{Kind: Prose, Text: cg.Text()})
+			continue
+		}
+		s := stmts[0]
+		stmts = stmts[1:]
+		run = append(run, s)
+		for len(inner) > 0 && inner[0].Pos() < s.End() {
+			inner = inner[1:] // comments within a statement stay with it
+		}
+	}
+	flush()
+
+	d.Meta.Imports = c.imports(f)
+	return d, nil
+}
+
+// lessonFunc returns the declaration of the function l runs.
+func lessonFunc(f *ast.File, l lessons.Lesson) *ast.FuncDecl {
+	var name string
+	ast.Inspect(f, func(n ast.Node) bool {
+		kv, ok := n.(*ast.KeyValueExpr)
+		if !ok {
+			return true
+		}
+		if key, ok := kv.Key.(*ast.Ident); ok && key.Name == "Run" {
+			if id, ok := kv.Value.(*ast.Ident); ok {
+				name = id.Name
+			}
+		}
+		return true
+	})
+	for _, decl := range f.Decls {
+		if fn, ok := decl.(*ast.FuncDecl); ok && fn.Name.Name == name && fn.Body != nil {
+			return fn
+		}
+	}
+	return nil
+}
+
+// writerParam returns the name of the io.Writer the lesson prints to.
+func writerParam(fn *ast.FuncDecl) string {
+	for _, field := range fn.Type.Params.List {
+		if sel, ok := field.Type.(*ast.SelectorExpr); ok && sel.Sel.Name == "Writer" && len(field.Names) == 1 {
+			return field.Names[0].Name
+		}
+	}
+	return ""
+}
+
+// insideDecl reports whether cg belongs to a declaration other than the
+// lesson function, or is the package or import section.
+func insideDecl(f *ast.File, cg *ast.CommentGroup, fn *ast.FuncDecl) bool {
+	if cg.End() < f.Name.End() {
+		return true
+	}
+	for _, decl := range f.Decls {
+		if decl == fn {
+			continue
+		}
+		if cg.Pos() >= decl.Pos() && cg.End() <= decl.End() {
+			return true
+		}
+		if d, ok := decl.(*ast.FuncDecl); ok && d.Doc == cg {
+			return true
+		}
+		if d, ok := decl.(*ast.GenDecl); ok && d.Doc == cg {
+			return true
+		}
+	}
+	return false
+}
+
+// previous returns the lesson before l in the curriculum.
+func previous(l lessons.Lesson) string {
+	prev := ""
+	for _, other := range lessons.All() {
+		if other.Name == l.Name {
+			return prev
+		}
+		prev = other.Name
+	}
+	return ""
+}
+
+type converter struct {
+	fset  *token.FileSet
+	src   []byte
+	w     string
+	edits []edit
+	raw   map[int]bool // lines within raw string literals
+	used  map[string]bool
+}
+
+type edit struct {
+	start, end int
+	text       string
+}
+
+func (c *converter) offset(p token.Pos) int { return c.fset.Position(p).Offset }
+func (c *converter) line(p token.Pos) int   { return c.fset.Position(p).Line }
+
+// rewrite plans the edits that make body print to standard output: calls
+// like fmt.Fprintln(w, x) become fmt.Println(x) and other uses of the
+// writer become os.Stdout. It also notes the packages body uses.
+func (c *converter) rewrite(body *ast.BlockStmt) {
+	c.raw = map[int]bool{}
+	c.used = map[string]bool{}
+	skip := map[*ast.Ident]bool{}
+	ast.Inspect(body, func(n ast.Node) bool {
+		switch n := n.(type) {
+		case *ast.BasicLit:
+			if n.Kind == token.STRING && strings.HasPrefix(n.Value, "`") {
+				for l := c.line(n.Pos()) + 1; l <= c.line(n.End()); l++ {
+					c.raw[l] = true
+				}
+			}
+		case *ast.SelectorExpr:
+			if pkg, ok := n.X.(*ast.Ident); ok {
+				c.used[pkg.Name] = true
+			}
+		case *ast.CallExpr:
+			sel, ok := n.Fun.(*ast.SelectorExpr)
+			if !ok || len(n.Args) == 0 {
+				break
+			}
+			pkg, ok := sel.X.(*ast.Ident)
+			w, isW := n.Args[0].(*ast.Ident)
+			if !ok || pkg.Name != "fmt" || !isW || w.Name != c.w || !strings.HasPrefix(sel.Sel.Name, "Fprint") {
+				break
+			}
+			name := "P" + strings.TrimPrefix(sel.Sel.Name, "Fp")
+			end := n.Rparen
+			if len(n.Args) > 1 {
+				end = n.Args[1].Pos()
+			}
+			c.edits = append(c.edits, edit{c.offset(sel.Sel.Pos()), c.offset(end), name + "("})
+			skip[w] = true
+		case *ast.Ident:
+			if n.Name == c.w && !skip[n] {
+				c.edits = append(c.edits, edit{c.offset(n.Pos()), c.offset(n.End()), "os.Stdout"})
+				c.used["os"] = true
+			}
+		}
+		return true
+	})
+	sort.Slice(c.edits, func(i, j int) bool { return c.edits[i].start < c.edits[j].start })
+}
+
+// code returns the source of the statements from first to last, whole
+// lines, edited and taken out of the function body's indentation.
+func (c *converter) code(first, last ast.Stmt) string {
+	start := c.offset(first.Pos())
+	for start > 0 && c.src[start-1] != '\n' {
+		start--
+	}
+	end := c.offset(last.End())
+	for end < len(c.src) && c.src[end] != '\n' {
+		end++
+	}
+
+	var sb strings.Builder
+	pos := start
+	for _, e := range c.edits {
+		if e.start < start || e.end > end {
+			continue
+		}
+		sb.Write(c.src[pos:e.start])
+		sb.WriteString(e.text)
+		pos = e.end
+	}
+	sb.Write(c.src[pos:end])
+
+	lines := strings.Split(sb.String(), "\n")
+	firstLine := c.line(first.Pos())
+	for i, line := range lines {
+		if !c.raw[firstLine+i] {
+			lines[i] = strings.TrimPrefix(line, "\t")
+		}
+	}
+	return strings.Join(lines, "\n") + "\n"
+}
+
+// imports returns the import paths of f that the lesson body uses.
+func (c *converter) imports(f *ast.File) []string {
+	var paths []string
+	for _, spec := range f.Imports {
+		path, _ := strconv.Unquote(spec.Path.Value)
+		name := path[strings.LastIndex(path, "/")+1:]
+		if spec.Name != nil {
+			name = spec.Name.Name
+		}
+		if c.used[name] {
+			paths = append(paths, path)
+		}
+	}
+	if c.used["os"] && !slices.Contains(paths, "os") {
+		paths = append(paths, "os")
+	}
+	sort.Strings(paths)
+	return paths
+}
@@ -0,0 +1,274 @@
+// Package literate reads and writes lessons written as Markdown: prose with
+// fenced Go blocks, each optionally followed by a block with the output it
+// is expected to print.
+//
+// A lesson file starts with YAML front matter:
+//
+//	---
+//	name: maps
+//	title: Maps
+//	topic: primitive_types
+//	prerequisites: [arrays_and_slices]
+//	imports: [fmt]
+//	---
+//
+// The ```go blocks are the statements of one program, in order; a block
+// that starts with a package clause makes the blocks a complete file
+// instead. An ```output block holds what the go block before it prints.
+package literate
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"gbdmp/learningo/lessons"
+	"gbdmp/learningo/people"
+)
+
+// Ext is the file name extension of literate lessons.
+const Ext = ".md"
+
+// Meta is the front matter of a lesson.
+type Meta struct {
+	Name          string   `yaml:"name"`
+	Title         string   `yaml:"title"`
+	Topic         string   `yaml:"topic"`
+	Order         int      `yaml:"order,omitempty"`
+	Prerequisites []string `yaml:"prerequisites,omitempty"`
+	// Imports are the packages the go blocks use.
+	Imports []string `yaml:"imports,omitempty"`
+	// Compare is how output is checked: exact (the default), sorted or set.
+	Compare string `yaml:"compare,omitempty"`
+	// Today pins the date the people package sees while the output is
+	// checked, so that ages do not change from year to year.
+	Today people.Date `yaml:"today,omitempty"`
+}
+
+func (m Meta) compare() (lessons.Compare, error) {
+	if m.Compare == "" {
+		return lessons.Exact, nil
+	}
+	return lessons.ParseCompare(m.Compare)
+}
+
+// Kind is the kind of a block.
+type Kind int
+
+const (
+	Prose Kind = iota
+	Code
+	Output
+)
+
+// Block is a part of a lesson.
+type Block struct {
+	Kind Kind
+	// Text of the block; for code and output without the fences, but
+	// with a final newline.
+	Text string
+	// Line is where the block starts in the file.
+	Line int
+}
+
+// Document is a parsed lesson.
+type Document struct {
+	Meta   Meta
+	Blocks []Block
+}
+
+// ErrSyntax is returned for a malformed lesson file.
+var ErrSyntax = errors.New("malformed lesson")
+
+// ReadFile parses the lesson in file. Its name defaults to the file's base
+// name.
+func ReadFile(file string) (*Document, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	d, err := Parse(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", file, err)
+	}
+	if d.Meta.Name == "" {
+		d.Meta.Name = strings.TrimSuffix(filepath.Base(file), Ext)
+	}
+	return d, nil
+}
+
+// Parse parses a lesson.
+func Parse(data []byte) (*Document, error) {
+	lines := strings.SplitAfter(strings.ReplaceAll(string(data), "\r\n", "\n"), "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	d := &Document{}
+	i := 0
+	if i < len(lines) && strings.TrimSpace(lines[i]) == "---" {
+		end := -1
+		for j := 1; j < len(lines); j++ {
+			if strings.TrimSpace(lines[j]) == "---" {
+				end = j
+				break
+			}
+		}
+		if end < 0 {
+			return nil, fmt.Errorf("%w: front matter is not closed by ---", ErrSyntax)
+		}
+		dec := yaml.NewDecoder(strings.NewReader(strings.Join(lines[1:end], "")))
+		dec.KnownFields(true)
+		if err := dec.Decode(&d.Meta); err != nil && !errors.Is(err, io.EOF) {
+			return nil, fmt.Errorf("%w: front matter: %v", ErrSyntax, err)
+		}
+		if _, err := d.Meta.compare(); err != nil {
+			return nil, fmt.Errorf("%w: front matter: %v", ErrSyntax, err)
+		}
+		i = end + 1
+	}
+
+	var prose strings.Builder
+	proseLine := i + 1
+	flush := func() {
+		text := prose.String()
+		// Blank lines before the text do not count for its line.
+		line := proseLine + len(text) - len(strings.TrimLeft(text, "\n"))
+		if text = strings.Trim(text, "\n"); text != "" {
+			d.Blocks = append(d.Blocks, Block{Kind: Prose, Text: text + "\n", Line: line})
+		}
+		prose.Reset()
+	}
+	for i < len(lines) {
+		fence, info, ok := openFence(lines[i])
+		if !ok {
+			if prose.Len() == 0 {
+				proseLine = i + 1
+			}
+			prose.WriteString(lines[i])
+			i++
+			continue
+		}
+		start := i
+		var body strings.Builder
+		for i++; i < len(lines) && !closesFence(lines[i], fence); i++ {
+			body.WriteString(lines[i])
+		}
+		if i == len(lines) {
+			return nil, fmt.Errorf("%w: line %d: code block is not closed", ErrSyntax, start+1)
+		}
+		i++
+		kind := Prose
+		switch info {
+		case "go":
+			kind = Code
+		case "output":
+			kind = Output
+		}
+		if kind == Prose {
+			// Blocks in other languages are part of the text.
+			if prose.Len() == 0 {
+				proseLine = start + 1
+			}
+			prose.WriteString(strings.Join(lines[start:i], ""))
+			continue
+		}
+		flush()
+		if kind == Output {
+			n := len(d.Blocks)
+			if n == 0 || d.Blocks[n-1].Kind == Output || !hasCode(d.Blocks) {
+				return nil, fmt.Errorf("%w: line %d: output block without a go block before it", ErrSyntax, start+1)
+			}
+		}
+		d.Blocks = append(d.Blocks, Block{Kind: kind, Text: body.String(), Line: start + 1})
+	}
+	flush()
+	return d, nil
+}
+
+func hasCode(blocks []Block) bool {
+	for _, b := range blocks {
+		if b.Kind == Code {
+			return true
+		}
+	}
+	return false
+}
+
+// openFence reports whether line opens a fenced block and returns the fence
+// and the language.
+func openFence(line string) (fence, info string, ok bool) {
+	line = strings.TrimRight(line, "\n")
+	n := len(line) - len(strings.TrimLeft(line, "`"))
+	if n < 3 {
+		return "", "", false
+	}
+	info, _, _ = strings.Cut(strings.TrimSpace(line[n:]), " ")
+	return line[:n], info, true
+}
+
+func closesFence(line, fence string) bool {
+	line = strings.TrimSpace(line)
+	return strings.HasPrefix(line, fence) && strings.Trim(line, "`") == ""
+}
+
+// Markdown formats d as a lesson file.
+func (d *Document) Markdown() []byte {
+	var buf bytes.Buffer
+	buf.WriteString("---\n")
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	enc.Encode(d.Meta)
+	enc.Close()
+	buf.WriteString("---\n")
+	for _, b := range d.Blocks {
+		buf.WriteByte('\n')
+		switch b.Kind {
+		case Prose:
+			buf.WriteString(b.Text)
+		case Code, Output:
+			lang := "go"
+			if b.Kind == Output {
+				lang = "output"
+			}
+			fence := fenceFor(b.Text)
+			fmt.Fprintf(&buf, "%s%s\n%s", fence, lang, b.Text)
+			if !strings.HasSuffix(b.Text, "\n") && b.Text != "" {
+				buf.WriteByte('\n')
+			}
+			buf.WriteString(fence + "\n")
+		}
+	}
+	return buf.Bytes()
+}
+
+// fenceFor returns a fence longer than any run of backticks that starts a
+// line of text.
+func fenceFor(text string) string {
+	fence := "```"
+	sc := bufio.NewScanner(strings.NewReader(text))
+	for sc.Scan() {
+		line := strings.TrimSpace(sc.Text())
+		if n := len(line) - len(strings.TrimLeft(line, "`")); n >= len(fence) {
+			fence = strings.Repeat("`", n+1)
+		}
+	}
+	return fence
+}
+
+// Code returns the go blocks of d.
+func (d *Document) Code() []Block {
+	var code []Block
+	for _, b := range d.Blocks {
+		if b.Kind == Code {
+			code = append(code, b)
+		}
+	}
+	return code
+}
@@ -0,0 +1,210 @@
+package literate_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"gbdmp/learningo/lessons"
+	"gbdmp/learningo/literate"
+)
+
+// lessonDir holds the Markdown lessons of the module.
+var lessonDir = filepath.Join("..", "lessons", "literate")
+
+const hello = "---\nname: hello\ntitle: Hello\ntopic: basics\nimports:\n  - fmt\n---\n\n# Hello\n\nprint twice:\n\n" +
+	"```go\nfmt.Println(\"hello\")\n```\n\n```output\nhello\n```\n\n" +
+	"then a shell block that stays prose:\n\n```sh\n$ go run .\n```\n\n" +
+	"```go\nfmt.Println(\"bye\")\n```\n\n```output\nbye\n```\n"
+
+func TestParse(t *testing.T) {
+	d, err := literate.Parse([]byte(hello))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := (literate.Meta{Name: "hello", Title: "Hello", Topic: "basics", Imports: []string{"fmt"}}); !reflect.DeepEqual(d.Meta, want) {
+		t.Errorf("Meta = %+v, want %+v", d.Meta, want)
+	}
+	want := []literate.Block{
+		{Kind: literate.Prose, Text: "# Hello\n\nprint twice:\n", Line: 9},
+		{Kind: literate.Code, Text: "fmt.Println(\"hello\")\n", Line: 13},
+		{Kind: literate.Output, Text: "hello\n", Line: 17},
+		{Kind: literate.Prose, Text: "then a shell block that stays prose:\n\n```sh\n$ go run .\n```\n", Line: 21},
+		{Kind: literate.Code, Text: "fmt.Println(\"bye\")\n", Line: 27},
+		{Kind: literate.Output, Text: "bye\n", Line: 31},
+	}
+	if !reflect.DeepEqual(d.Blocks, want) {
+		t.Errorf("Blocks =\n%+v\nwant\n%+v", d.Blocks, want)
+	}
+	if got := string(d.Markdown()); got != hello {
+		t.Errorf("Markdown =\n%s\nwant\n%s", got, hello)
+	}
+}
+
+// TestParseLessons checks that the lessons of the module are written the
+// way Markdown writes them, so that -record changes only output blocks.
+func TestParseLessons(t *testing.T) {
+	files, err := filepath.Glob(filepath.Join(lessonDir, "*"+literate.Ext))
+	if err != nil || len(files) == 0 {
+		t.Fatalf("no lessons in %s: %v", lessonDir, err)
+	}
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		d, err := literate.Parse(data)
+		if err != nil {
+			t.Errorf("%s: %v", file, err)
+			continue
+		}
+		if got := d.Markdown(); string(got) != string(data) {
+			t.Errorf("%s does not survive Parse and Markdown:\n%s", file, got)
+		}
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	for _, tt := range []struct {
+		name, text, err string
+	}{
+		{"open front matter", "---\nname: x\n", "front matter is not closed"},
+		{"unknown field", "---\nnmae: x\n---\n", "field nmae not found"},
+		{"unknown compare", "---\ncompare: fuzzy\n---\n", "front matter"},
+		{"open block", "text\n\n```go\nx := 1\n", "line 3: code block is not closed"},
+		{"output first", "```output\nhello\n```\n", "line 1: output block without a go block"},
+		{"two outputs", "```go\nx()\n```\n\n```output\na\n```\n\n```output\nb\n```\n", "line 9: output block without"},
+	} {
+		_, err := literate.Parse([]byte(tt.text))
+		if !errors.Is(err, literate.ErrSyntax) || !strings.Contains(err.Error(), tt.err) {
+			t.Errorf("%s: Parse = %v, want %q", tt.name, err, tt.err)
+		}
+	}
+}
+
+func TestParseLongFence(t *testing.T) {
+	d := &literate.Document{Meta: literate.Meta{Name: "fence"}, Blocks: []literate.Block{
+		{Kind: literate.Code, Text: "s := `\n```\n`\n_ = s\n"},
+	}}
+	md := d.Markdown()
+	if !strings.Contains(string(md), "````go\n") {
+		t.Errorf("Markdown does not use a longer fence:\n%s", md)
+	}
+	back, err := literate.Parse(md)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(back.Blocks) != 1 || back.Blocks[0].Text != d.Blocks[0].Text {
+		t.Errorf("Parse(Markdown) = %+v, want the code back", back.Blocks)
+	}
+}
+
+func TestVerify(t *testing.T) {
+	if testing.Short() {
+		t.Skip("runs the go command")
+	}
+	d, err := literate.Parse([]byte(hello))
+	if err != nil {
+		t.Fatal(err)
+	}
+	res, err := literate.Verify(context.Background(), d, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !res.OK() || len(res.Blocks) != 2 {
+		t.Fatalf("Verify = %s", res)
+	}
+
+	d.Blocks[5].Text = "good bye\n"
+	res, err = literate.Verify(context.Background(), d, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.OK() || !res.Blocks[0].OK || res.Blocks[1].OK {
+		t.Fatalf("Verify of a changed output block = %s", res)
+	}
+	if b := res.Blocks[1]; b.Line != 31 || !strings.Contains(b.Diff, "--- hello.md:31") || !strings.Contains(b.Diff, "-good bye\n+bye\n") {
+		t.Errorf("mismatch on line %d:\n%s", b.Line, b.Diff)
+	}
+	if s := res.String(); !strings.HasPrefix(s, "FAIL hello\n") {
+		t.Errorf("String =\n%s", s)
+	}
+
+	d.Blocks[4].Text = "fmt.Println(bye)\n"
+	if res, err = literate.Verify(context.Background(), d, ""); err != nil || res.Built || !strings.Contains(res.String(), "does not build") {
+		t.Errorf("Verify of a lesson that does not build = %v, %v", res, err)
+	}
+}
+
+func TestRecord(t *testing.T) {
+	if testing.Short() {
+		t.Skip("runs the go command")
+	}
+	d, err := literate.Parse([]byte("---\nname: record\ncompare: sorted\nimports:\n  - fmt\n---\n\n" +
+		"```go\nx := 2\n_ = x\n```\n\n```output\nstale\n```\n\n" +
+		"```go\nfmt.Println(\"b\")\nfmt.Println(\"a\")\n```\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	res, err := literate.Record(context.Background(), d, "")
+	if err != nil || !res.OK() {
+		t.Fatalf("Record = %v, %v", res, err)
+	}
+	// the first block prints nothing and loses its output block, the
+	// second gets one, sorted as the lesson compares
+	var kinds []literate.Kind
+	for _, b := range d.Blocks {
+		kinds = append(kinds, b.Kind)
+	}
+	if want := []literate.Kind{literate.Code, literate.Code, literate.Output}; !reflect.DeepEqual(kinds, want) {
+		t.Fatalf("blocks after Record = %+v", d.Blocks)
+	}
+	if got := d.Blocks[2].Text; got != "a\nb\n" {
+		t.Errorf("recorded output = %q, want %q", got, "a\nb\n")
+	}
+
+	file, err := literate.Parse([]byte("```go\npackage main\n\nfunc main() {}\n```\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := literate.Record(context.Background(), file, ""); err == nil {
+		t.Error("Record of a complete file succeeds")
+	}
+}
+
+// TestConvert checks that converting a lesson gives the checked-in
+// Markdown lesson apart from the output blocks, which Record adds.
+func TestConvert(t *testing.T) {
+	for _, l := range lessons.All() {
+		d, err := literate.Convert(l)
+		if err != nil {
+			t.Errorf("Convert(%s): %v", l.Name, err)
+			continue
+		}
+		want, err := literate.ReadFile(filepath.Join(lessonDir, l.Name+literate.Ext))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(d.Meta, want.Meta) {
+			t.Errorf("%s: Meta = %+v, want %+v", l.Name, d.Meta, want.Meta)
+		}
+		if got, want := withoutOutput(d), withoutOutput(want); got != want {
+			t.Errorf("%s: converted lesson\n%s\nwant\n%s", l.Name, got, want)
+		}
+	}
+}
+
+func withoutOutput(d *literate.Document) string {
+	var sb strings.Builder
+	for _, b := range d.Blocks {
+		if b.Kind != literate.Output {
+			sb.WriteString(b.Text)
+			sb.WriteString("\n")
+		}
+	}
+	return sb.String()
+}
@@ -0,0 +1,112 @@
+package literate
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"strconv"
+	"strings"
+)
+
+// Program returns the source of the program made of the go blocks of d.
+func (d *Document) Program() ([]byte, error) {
+	return d.program(false)
+}
+
+// isFile reports whether the go blocks of d form a complete file rather
+// than the body of main.
+func (d *Document) isFile() bool {
+	for _, b := range d.Code() {
+		for _, line := range strings.Split(b.Text, "\n") {
+			line = strings.TrimSpace(line)
+			if line == "" || strings.HasPrefix(line, "//") {
+				continue
+			}
+			if strings.HasPrefix(line, "package ") {
+				return true
+			}
+			break
+		}
+	}
+	return false
+}
+
+// program assembles the program. With marks, a call of literateMark
+// separates the output of consecutive go blocks, see marks.
+func (d *Document) program(marks bool) ([]byte, error) {
+	code := d.Code()
+	if len(code) == 0 {
+		return nil, fmt.Errorf("%w: lesson %s has no go blocks", ErrSyntax, d.Meta.Name)
+	}
+	var buf bytes.Buffer
+	if d.isFile() {
+		for _, b := range code {
+			buf.WriteString(b.Text)
+			buf.WriteByte('\n')
+		}
+	} else {
+		buf.WriteString("package main\n\n")
+		if len(d.Meta.Imports) > 0 {
+			buf.WriteString("import (\n")
+			for _, path := range d.Meta.Imports {
+				fmt.Fprintf(&buf, "\t%s\n", strconv.Quote(path))
+			}
+			buf.WriteString(")\n\n")
+		}
+		buf.WriteString("func main() {\n")
+		for i, b := range code {
+			if marks && i > 0 {
+				buf.WriteString("literateMark()\n")
+			}
+			buf.WriteString(b.Text)
+		}
+		buf.WriteString("}\n")
+	}
+	src, err := format.Source(buf.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("lesson %s: %w", d.Meta.Name, err)
+	}
+	return src, nil
+}
+
+// mark separates the output of go blocks while a lesson is checked.
+const mark = "\x00literate\x00"
+
+// marks returns the file that goes next to the program of d while it is
+// checked: it defines literateMark and pins the date if d asks for it.
+func (d *Document) marks() []byte {
+	var buf bytes.Buffer
+	buf.WriteString("package main\n\nimport (\n\t\"os\"\n")
+	pin := !d.Meta.Today.IsZero() && d.Uses(peoplePath)
+	if pin {
+		buf.WriteString("\t\"time\"\n\n\t\"" + peoplePath + "\"\n")
+	}
+	buf.WriteString(")\n\n")
+	fmt.Fprintf(&buf, "func literateMark() { os.Stdout.WriteString(%q) }\n", mark)
+	if pin {
+		t := d.Meta.Today
+		fmt.Fprintf(&buf, "\nfunc init() {\n\tpeople.Now = func() time.Time { return time.Date(%d, %d, %d, 0, 0, 0, 0, time.UTC) }\n}\n",
+			t.Year, int(t.Month), t.Day)
+	}
+	return buf.Bytes()
+}
+
+const peoplePath = "gbdmp/learningo/people"
+
+// Uses reports whether the program of d imports path or a package below
+// it.
+func (d *Document) Uses(path string) bool {
+	for _, p := range d.Meta.Imports {
+		if p == path || strings.HasPrefix(p, path+"/") {
+			return true
+		}
+	}
+	if d.isFile() {
+		for _, b := range d.Code() {
+			if strings.Contains(b.Text, `"`+path) {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,201 @@
+package literate
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"gbdmp/learningo/internal/textdiff"
+	"gbdmp/learningo/playground"
+)
+
+// Result is the outcome of checking a lesson.
+type Result struct {
+	Lesson string
+	// Built is false if the program does not compile; BuildOutput then
+	// holds the compiler messages.
+	Built       bool
+	BuildOutput string
+	// RunError is set if the program failed, with what it wrote to
+	// standard error.
+	RunError string
+	// Blocks holds the checked output blocks.
+	Blocks []BlockResult
+}
+
+// BlockResult is the outcome of checking one output block.
+type BlockResult struct {
+	Line int // of the output block
+	OK   bool
+	Diff string
+}
+
+// OK reports whether the program ran and printed what the lesson expects.
+func (r *Result) OK() bool {
+	if !r.Built || r.RunError != "" {
+		return false
+	}
+	for _, b := range r.Blocks {
+		if !b.OK {
+			return false
+		}
+	}
+	return true
+}
+
+// String summarizes r for the terminal.
+func (r *Result) String() string {
+	var sb strings.Builder
+	switch {
+	case !r.Built:
+		fmt.Fprintf(&sb, "FAIL %s: does not build\n%s", r.Lesson, r.BuildOutput)
+	case r.OK():
+		fmt.Fprintf(&sb, "ok   %s (%d output blocks)\n", r.Lesson, len(r.Blocks))
+	default:
+		fmt.Fprintf(&sb, "FAIL %s\n", r.Lesson)
+	}
+	if r.RunError != "" {
+		fmt.Fprintf(&sb, "%s\n", strings.TrimRight(r.RunError, "\n"))
+	}
+	for _, b := range r.Blocks {
+		if !b.OK {
+			sb.WriteString(b.Diff)
+		}
+	}
+	return sb.String()
+}
+
+// Verify builds and runs the program of d and compares its output with the
+// output blocks. Imports of gbdmp/learningo packages are resolved from the
+// module in module, which may be empty if the lesson has none.
+func Verify(ctx context.Context, d *Document, module string) (*Result, error) {
+	res, outputs, err := run(ctx, d, module)
+	if err != nil || !res.Built {
+		return res, err
+	}
+	mode, err := d.Meta.compare()
+	if err != nil {
+		return nil, err
+	}
+
+	check := func(line int, want, got string) {
+		a, b := mode.Normalize(want), mode.Normalize(withNewline(got))
+		br := BlockResult{Line: line, OK: a == b}
+		if !br.OK {
+			br.Diff = textdiff.Unified(fmt.Sprintf("%s.md:%d", d.Meta.Name, line), "output", a, b)
+		}
+		res.Blocks = append(res.Blocks, br)
+	}
+	if d.isFile() {
+		// The output of a complete file cannot be told apart by block.
+		var want strings.Builder
+		line := 0
+		for _, b := range d.Blocks {
+			if b.Kind == Output {
+				want.WriteString(b.Text)
+				if line == 0 {
+					line = b.Line
+				}
+			}
+		}
+		if line > 0 {
+			check(line, want.String(), strings.Join(outputs, ""))
+		}
+		return res, nil
+	}
+	code := -1
+	for _, b := range d.Blocks {
+		switch b.Kind {
+		case Code:
+			code++
+		case Output:
+			got := ""
+			if code < len(outputs) {
+				got = outputs[code]
+			}
+			check(b.Line, b.Text, got)
+		}
+	}
+	return res, nil
+}
+
+// Record runs the program of d and replaces its output blocks by what the
+// go blocks print; a go block that prints nothing gets none. Unless the
+// lesson compares output exactly, it is recorded sorted.
+func Record(ctx context.Context, d *Document, module string) (*Result, error) {
+	if d.isFile() {
+		return nil, fmt.Errorf("lesson %s is a complete file, its output cannot be recorded by block", d.Meta.Name)
+	}
+	mode, err := d.Meta.compare()
+	if err != nil {
+		return nil, err
+	}
+	res, outputs, err := run(ctx, d, module)
+	if err != nil || !res.OK() {
+		return res, err
+	}
+	var blocks []Block
+	code := -1
+	for _, b := range d.Blocks {
+		if b.Kind == Output {
+			continue
+		}
+		blocks = append(blocks, b)
+		if b.Kind == Code {
+			code++
+			if code < len(outputs) && outputs[code] != "" {
+				blocks = append(blocks, Block{Kind: Output, Text: mode.Normalize(withNewline(outputs[code]))})
+			}
+		}
+	}
+	d.Blocks = blocks
+	return res, nil
+}
+
+func withNewline(s string) string {
+	if s != "" && !strings.HasSuffix(s, "\n") {
+		s += "\n"
+	}
+	return s
+}
+
+// Extract writes the program of d to dir as a module of its own, ready for
+// go run.
+func Extract(d *Document, dir, module string) error {
+	src, err := d.Program()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+	return playground.WriteModule(dir, module, map[string][]byte{"main.go": src})
+}
+
+// run builds and runs the marked program of d in the playground and
+// returns the output of each go block.
+func run(ctx context.Context, d *Document, module string) (*Result, []string, error) {
+	src, err := d.program(true)
+	if err != nil {
+		return nil, nil, err
+	}
+	opts := playground.DefaultOptions
+	opts.Module = module
+	p, err := playground.RunFiles(ctx, map[string][]byte{"main.go": src, "zz_literate.go": d.marks()}, opts)
+	if err != nil {
+		return nil, nil, err
+	}
+	res := &Result{Lesson: d.Meta.Name, Built: p.Compiled, BuildOutput: p.BuildOutput}
+	switch {
+	case !p.Compiled:
+		return res, nil, nil
+	case p.TimedOut:
+		res.RunError = fmt.Sprintf("timed out after %v", opts.Timeout)
+	case p.Truncated:
+		res.RunError = "too much output"
+	case p.ExitCode != 0:
+		res.RunError = fmt.Sprintf("%s\n%s", p.Status, p.Stderr)
+	}
+	return res, strings.Split(p.Stdout, mark), nil
+}
@@ -1,7 +1,196 @@
+// Command learningo manages the people, ages and milestones used in the
+// lessons.
+//
+// Usage:
+//
+//	learningo [-store FILE] COMMAND [ARGS]
+//
+// Run "learningo help" for the list of commands.
 package main
 
-import "fmt"
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gbdmp/learningo/people"
+)
+
+// command is a learningo subcommand.
+type command struct {
+	name    string
+	args    string
+	summary string
+	run     func(env *env, args []string) error
+}
+
+// env is what commands get to work with.
+type env struct {
+	stdout    io.Writer
+	stderr    io.Writer
+	storePath string
+}
+
+var commands []*command
+
+func init() {
+	commands = []*command{
+		{"add", "NAME DATE", "add a person born on DATE (DD.MM.YYYY, YYYY-MM-DD or MM/DD/YYYY)", runAdd},
+		{"remove", "NAME", "remove a person", runRemove},
+		{"list", "[-sort age|name|birthday] [-format F]", "list everybody", runList},
+		{"age", "NAME [-on DATE] [-format F]", "show how old somebody is today or on DATE", runAge},
+		{"milestones", "NAME [-on DATE] [-rules FILE] [-jurisdiction J] [-format F]", "show the milestones reached and the next one", runMilestones},
+		{"upcoming", "[-days N] [-leap feb28|mar1|leap-only] [-format F]", "list the birthdays of the next N days", runUpcoming},
+		{"ics", "[-o FILE] [-leap feb28|mar1|leap-only]", "export the birthdays as an iCalendar file", runICS},
+		{"serve", "[-addr HOST:PORT] [-rules FILE] [-leap P] [-play]", "serve the people as a JSON HTTP API", runServe},
+		{"explain", "[-format F] EXPR | -", "explain the types, constants and conversions of a Go expression", runExplain},
+		{"numbers", "[-format F] EXPR | -", "evaluate an expression in every integer, float and math/big type", runNumbers},
+		{"runes", "[-escaped] [-format F] STRING | -", "show the bytes, runes and grapheme clusters of a string", runRunes},
+		{"play", "[-timeout D] [-cpu D] [-mem N] [-json] [FILE]", "run a Go snippet from FILE or standard input in a sandbox", runPlay},
+		{"lessons", "list | run | verify | exercise | check | progress | convert | literate | extract | site (see below)", "work with the lessons and their exercises", runLessons},
+		{"help", "", "show this help", runHelp},
+	}
+}
+
+// errUsage is returned by commands called with bad arguments; main
+// answers it with exit status 2.
+var errUsage = errors.New("usage")
 
 func main() {
-	fmt.Println("foo")
+	os.Exit(run(os.Args[1:], os.Stdout, os.Stderr))
+}
+
+func run(args []string, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("learningo", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	store := fs.String("store", defaultStorePath(), "people `file`: .json, .csv or .log")
+	fs.Usage = func() { usage(stderr) }
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if fs.NArg() == 0 {
+		usage(stderr)
+		return 2
+	}
+
+	name := fs.Arg(0)
+	for _, c := range commands {
+		if c.name != name {
+			continue
+		}
+		err := c.run(&env{stdout: stdout, stderr: stderr, storePath: *store}, fs.Args()[1:])
+		switch {
+		case err == nil:
+			return 0
+		case errors.Is(err, flag.ErrHelp):
+			return 0
+		case errors.Is(err, errUsage):
+			fmt.Fprintf(stderr, "usage: learningo %s %s\n", c.name, c.args)
+			return 2
+		}
+		fmt.Fprintf(stderr, "learningo %s: %v\n", name, err)
+		return 1
+	}
+	fmt.Fprintf(stderr, "learningo: unknown command %q\n", name)
+	usage(stderr)
+	return 2
+}
+
+func usage(w io.Writer) {
+	fmt.Fprintln(w, "usage: learningo [-store FILE] COMMAND [ARGS]")
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "commands:")
+	for _, c := range commands {
+		fmt.Fprintf(w, "  %-11s %s\n", c.name, c.summary)
+	}
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "lessons commands:")
+	fmt.Fprintln(w, "  lessons list [-topic T] [-format F]                 list the lessons")
+	fmt.Fprintln(w, "  lessons run [-topic T] [-visual] [LESSON...]        run lessons")
+	fmt.Fprintln(w, "  lessons verify [-update] [-compare M] [LESSON...]   compare lesson output with the golden files")
+	fmt.Fprintln(w, "  lessons exercise [-dir D] [-force] [LESSON/N]       list exercises or write the stub of one")
+	fmt.Fprintln(w, "  lessons check [-dir D] [LESSON/N...]                grade exercises")
+	fmt.Fprintln(w, "  lessons progress [-learner L] [-all] [-format F]    show completion, streak and the next lesson")
+	fmt.Fprintln(w, "  lessons convert [-dir D] [-force] [LESSON...]       turn lessons into Markdown lessons")
+	fmt.Fprintln(w, "  lessons literate [-dir D] [-record] [FILE...]       build and run Markdown lessons, check their output")
+	fmt.Fprintln(w, "  lessons extract [-o DIR] FILE                       write the program of a Markdown lesson")
+	fmt.Fprintln(w, "  lessons site [-o DIR] [-dir D] [-title T]           generate the lessons as a static HTML site")
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "Output formats (-format) are table, json and csv.")
+	fmt.Fprintln(w, "The store defaults to $LEARNINGO_STORE or people.json in the user config directory;")
+	fmt.Fprintln(w, "a store that does not exist yet is empty and is created by the first change to it.")
+}
+
+func runHelp(e *env, args []string) error {
+	usage(e.stdout)
+	return nil
+}
+
+// parseArgs parses flags that may appear before, between or after the
+// positional arguments and returns the positional arguments.
+func parseArgs(fs *flag.FlagSet, args []string) ([]string, error) {
+	var pos []string
+	for {
+		if err := fs.Parse(args); err != nil {
+			return nil, err
+		}
+		if fs.NArg() == 0 {
+			return pos, nil
+		}
+		pos = append(pos, fs.Arg(0))
+		args = fs.Args()[1:]
+	}
+}
+
+func newFlagSet(e *env, name string) *flag.FlagSet {
+	fs := flag.NewFlagSet("learningo "+name, flag.ContinueOnError)
+	fs.SetOutput(e.stderr)
+	return fs
+}
+
+func defaultStorePath() string {
+	if p := os.Getenv("LEARNINGO_STORE"); p != "" {
+		return p
+	}
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "people.json"
+	}
+	return filepath.Join(dir, "learningo", "people.json")
+}
+
+// openRegistry opens the registry in the store file, picking the backend
+// from the file extension. A store that does not exist yet holds nobody;
+// it and its directory are only created when the registry changes.
+func (e *env) openRegistry() (*people.Registry, error) {
+	var s people.Store
+	switch strings.ToLower(filepath.Ext(e.storePath)) {
+	case ".json":
+		s = people.NewJSONStore(e.storePath)
+	case ".csv":
+		s = people.NewCSVStore(e.storePath)
+	case ".log":
+		s = people.NewLogStore(e.storePath)
+	default:
+		return nil, fmt.Errorf("%s: unknown store type, want .json, .csv or .log", e.storePath)
+	}
+	return people.Open(mkdirStore{s, filepath.Dir(e.storePath)})
+}
+
+// mkdirStore creates the directory of the store file before saving, so
+// that commands which only read leave no trace behind.
+type mkdirStore struct {
+	people.Store
+	dir string
+}
+
+func (s mkdirStore) Save(list []people.Person) error {
+	if err := os.MkdirAll(s.dir, 0o755); err != nil {
+		return err
+	}
+	return s.Store.Save(list)
 }
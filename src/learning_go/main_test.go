@@ -0,0 +1,117 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"gbdmp/learningo/people"
+)
+
+// pinToday makes 13.10.2023 today for the rest of the test.
+func pinToday(t *testing.T) {
+	now := people.Now
+	people.Now = func() time.Time { return time.Date(2023, time.October, 13, 12, 0, 0, 0, time.UTC) }
+	t.Cleanup(func() { people.Now = now })
+}
+
+func TestPeopleCommands(t *testing.T) {
+	pinToday(t)
+	dir := filepath.Join(t.TempDir(), "learningo")
+	store := filepath.Join(dir, "people.csv")
+	for _, tt := range []struct {
+		args   []string
+		code   int
+		stdout string // all of standard output, unless it starts with "..."
+		stderr string // part of standard error
+	}{
+		{[]string{"list", "-format", "csv"}, 0, "Name;Birthday;Age\n", ""},
+		{[]string{"add", "Tim", "06.06.2017"}, 0, "", ""},
+		{[]string{"add", "Leap", "2004-02-29"}, 0, "", ""},
+		{[]string{"add", "Tim", "01.01.2000"}, 1, "", "person already exists: Tim"},
+		{[]string{"add", "X\x81", "01.01.2000"}, 1, "", "invalid name"},
+		{[]string{"add", "", "01.01.2000"}, 1, "", "person has no name"},
+		{[]string{"add", "Ada", "31.02.2000"}, 1, "", "out of range"},
+		{[]string{"add", "Ada"}, 2, "", "usage: learningo add NAME DATE"},
+		{[]string{"list", "-format", "csv"}, 0, "Name;Birthday;Age\nLeap;29.02.2004;19\nTim;06.06.2017;6\n", ""},
+		{[]string{"list", "-sort", "age", "-format", "csv"}, 0, "Name;Birthday;Age\nTim;06.06.2017;6\nLeap;29.02.2004;19\n", ""},
+		{[]string{"list", "-sort", "shoe-size"}, 1, "", "unknown sort key"},
+		{[]string{"list", "-format", "json"}, 0, "...\"name\": \"Leap\",\n    \"birthday\": \"2004-02-29\",\n    \"age\": 19", ""},
+		{[]string{"age", "Tim", "-format", "csv"}, 0, "Name;Birthday;On;Age\nTim;06.06.2017;13.10.2023;6\n", ""},
+		{[]string{"age", "-on", "05.06.2027", "Tim", "-format", "csv"}, 0, "Name;Birthday;On;Age\nTim;06.06.2017;05.06.2027;9\n", ""},
+		{[]string{"age", "Nobody"}, 1, "", "person not found: Nobody"},
+		{[]string{"age"}, 2, "", "usage: learningo age"},
+		{[]string{"upcoming", "-from", "01.02.2025", "-days", "40", "-leap", "mar1", "-format", "csv"}, 0, "Date;Name;Turns;In days\n01.03.2025;Leap;21;28\n", ""},
+		{[]string{"upcoming", "-from", "01.02.2025", "-days", "40", "-leap", "leap-only", "-format", "csv"}, 0, "Date;Name;Turns;In days\n", ""},
+		{[]string{"upcoming", "-days", "200", "-format", "csv"}, 0, "Date;Name;Turns;In days\n29.02.2024;Leap;20;139\n", ""},
+		{[]string{"upcoming", "-days", "-1"}, 2, "", "usage: learningo upcoming"},
+		{[]string{"upcoming", "-leap", "never"}, 1, "", "unknown leap day policy"},
+		{[]string{"ics", "-leap", "mar1"}, 0, "...UID:birthday-leap-20040229@learningo\r\nDTSTAMP:20231013T120000Z\r\nDTSTART;VALUE=DATE:20040229\r\nDURATION:P1D\r\nRRULE:FREQ=YEARLY;BYYEARDAY=60\r\n", ""},
+		{[]string{"ics", "extra"}, 2, "", "usage: learningo ics"},
+		{[]string{"remove", "Leap"}, 0, "", ""},
+		{[]string{"remove", "Leap"}, 1, "", "person not found"},
+		{[]string{"shout"}, 2, "", `unknown command "shout"`},
+	} {
+		var stdout, stderr bytes.Buffer
+		code := run(append([]string{"-store", store}, tt.args...), &stdout, &stderr)
+		out := stdout.String()
+		var outOK bool
+		if want, ok := strings.CutPrefix(tt.stdout, "..."); ok {
+			outOK = strings.Contains(out, want)
+		} else {
+			outOK = out == tt.stdout
+		}
+		if code != tt.code || !outOK || !strings.Contains(stderr.String(), tt.stderr) {
+			t.Errorf("learningo %q = %d\nstdout: %q\nstderr: %q\nwant %d, %q, %q", tt.args, code, out, stderr.String(), tt.code, tt.stdout, tt.stderr)
+		}
+		if tt.args[0] == "list" && tt.code == 0 && stdout.Len() == len("Name;Birthday;Age\n") {
+			// nothing was added yet: reading leaves no trace
+			if _, err := os.Stat(dir); !os.IsNotExist(err) {
+				t.Errorf("list of an empty store created %s", dir)
+			}
+		}
+	}
+}
+
+func TestICSFile(t *testing.T) {
+	pinToday(t)
+	store := filepath.Join(t.TempDir(), "people.json")
+	ics := filepath.Join(t.TempDir(), "birthdays.ics")
+	var stdout, stderr bytes.Buffer
+	if code := run([]string{"-store", store, "add", "Ada", "10.12.1815"}, &stdout, &stderr); code != 0 {
+		t.Fatalf("add = %d: %s", code, &stderr)
+	}
+	if code := run([]string{"-store", store, "ics", "-o", ics}, &stdout, &stderr); code != 0 || stdout.Len() != 0 {
+		t.Fatalf("ics -o = %d, %q: %s", code, &stdout, &stderr)
+	}
+	data, err := os.ReadFile(ics)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(string(data), "BEGIN:VCALENDAR\r\n") || !strings.Contains(string(data), "SUMMARY:Ada's birthday\r\n") {
+		t.Errorf("ics file =\n%s", data)
+	}
+}
+
+func TestLoadInvalidName(t *testing.T) {
+	store := filepath.Join(t.TempDir(), "people.csv")
+	if err := os.WriteFile(store, []byte("Name;Birthday\nX\x81;01.01.2000\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	for _, args := range [][]string{{"list"}, {"ics"}, {"upcoming"}} {
+		var stdout, stderr bytes.Buffer
+		if code := run(append([]string{"-store", store}, args...), &stdout, &stderr); code != 1 || !strings.Contains(stderr.String(), "line 2: invalid name") {
+			t.Errorf("learningo %s with a bad name in the store = %d: %q", args, code, &stderr)
+		}
+	}
+}
+
+func TestUnknownStore(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	if code := run([]string{"-store", "people.txt", "list"}, &stdout, &stderr); code != 1 || !strings.Contains(stderr.String(), "unknown store type") {
+		t.Errorf("list with a .txt store = %d: %q", code, &stderr)
+	}
+}
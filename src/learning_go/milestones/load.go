@@ -0,0 +1,148 @@
+package milestones
+
+import (
+	"bytes"
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Format is the encoding of a rule file.
+type Format string
+
+const (
+	JSON Format = "json"
+	YAML Format = "yaml"
+)
+
+// RuleSet holds the rules of several jurisdictions.
+type RuleSet struct {
+	// Default names the jurisdiction used when none is asked for.
+	Default       string           `json:"default,omitempty" yaml:"default,omitempty"`
+	Jurisdictions map[string]Rules `json:"jurisdictions" yaml:"jurisdictions"`
+}
+
+// DefaultJurisdiction is the jurisdiction of the built-in rule set that
+// holds the milestones of the loops and conditionals lessons.
+const DefaultJurisdiction = "lessons"
+
+//go:embed default.yaml
+var defaultRules []byte
+
+// Default returns the built-in rule set.
+func Default() *RuleSet {
+	s, err := Parse(defaultRules, YAML)
+	if err != nil {
+		panic("milestones: bad default rules: " + err.Error())
+	}
+	return s
+}
+
+// DefaultRules returns the rules of DefaultJurisdiction.
+func DefaultRules() Rules {
+	rules, err := Default().Rules(DefaultJurisdiction)
+	if err != nil {
+		panic(err)
+	}
+	return rules
+}
+
+// Load reads a rule set from a .json, .yaml or .yml file.
+func Load(path string) (*RuleSet, error) {
+	var format Format
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json":
+		format = JSON
+	case ".yaml", ".yml":
+		format = YAML
+	default:
+		return nil, fmt.Errorf("milestones: %s: unknown rule file type, want .json, .yaml or .yml", path)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	s, err := Parse(data, format)
+	if err != nil {
+		return nil, fmt.Errorf("milestones: %s: %w", path, err)
+	}
+	return s, nil
+}
+
+// Parse decodes and validates a rule set.
+func Parse(data []byte, format Format) (*RuleSet, error) {
+	var s RuleSet
+	switch format {
+	case JSON:
+		dec := json.NewDecoder(bytes.NewReader(data))
+		dec.DisallowUnknownFields()
+		if err := dec.Decode(&s); err != nil {
+			return nil, err
+		}
+	case YAML:
+		dec := yaml.NewDecoder(bytes.NewReader(data))
+		dec.KnownFields(true)
+		if err := dec.Decode(&s); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("unknown format %q", format)
+	}
+	if err := s.validate(); err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
+
+func (s *RuleSet) validate() error {
+	if len(s.Jurisdictions) == 0 {
+		return fmt.Errorf("no jurisdictions")
+	}
+	if s.Default != "" {
+		if _, ok := s.Jurisdictions[s.Default]; !ok {
+			return fmt.Errorf("default jurisdiction %q is not defined", s.Default)
+		}
+	}
+	for name, rules := range s.Jurisdictions {
+		seen := map[string]bool{}
+		for _, r := range rules {
+			if err := r.validate(); err != nil {
+				return fmt.Errorf("jurisdiction %s: %w", name, err)
+			}
+			if seen[r.Name] {
+				return fmt.Errorf("jurisdiction %s: duplicate rule %s", name, r.Name)
+			}
+			seen[r.Name] = true
+		}
+	}
+	return nil
+}
+
+// Rules returns the rules of the named jurisdiction, or of the default
+// jurisdiction if name is empty.
+func (s *RuleSet) Rules(name string) (Rules, error) {
+	if name == "" {
+		name = s.Default
+	}
+	rules, ok := s.Jurisdictions[name]
+	if !ok {
+		return nil, fmt.Errorf("milestones: unknown jurisdiction %q", name)
+	}
+	return rules, nil
+}
+
+// Names returns the sorted names of all jurisdictions.
+func (s *RuleSet) Names() []string {
+	names := make([]string, 0, len(s.Jurisdictions))
+	for name := range s.Jurisdictions {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,29 @@
+package milestones
+
+import "gbdmp/learningo/people"
+
+// Report lists the milestones of a person on a given day.
+type Report struct {
+	Person  people.Person
+	On      people.Date
+	Age     int
+	Reached Rules
+	Next    *Upcoming
+}
+
+// Upcoming describes the next milestones of a person.
+type Upcoming struct {
+	Age   int
+	Date  people.Date
+	Rules Rules
+}
+
+// Evaluate applies rs to p on the given day.
+func (rs Rules) Evaluate(p people.Person, on people.Date) Report {
+	age := p.AgeOn(on)
+	rep := Report{Person: p, On: on, Age: age, Reached: rs.Reached(age)}
+	if next, at, ok := rs.Next(age); ok {
+		rep.Next = &Upcoming{Age: at, Date: p.Birthday.AddYears(at), Rules: next}
+	}
+	return rep
+}
@@ -0,0 +1,154 @@
+// Package milestones reports which age milestones (driving, voting,
+// drinking, retirement, ...) somebody has reached and which comes next.
+//
+// Rules are grouped by jurisdiction and can be loaded from YAML or JSON
+// files; Default returns the built-in rule set.
+package milestones
+
+import (
+	"fmt"
+	"sort"
+)
+
+// Match tells how a rule compares an age with its threshold.
+type Match string
+
+const (
+	// AtLeast rules are reached at their age and stay reached.
+	AtLeast Match = "at-least"
+	// Exactly rules only hold in the years listed in the rule.
+	Exactly Match = "exactly"
+)
+
+// Rule is a single milestone.
+type Rule struct {
+	Name  string `json:"name" yaml:"name"`
+	Label string `json:"label,omitempty" yaml:"label,omitempty"`
+	// Age is the threshold of an at-least rule. An exactly rule may use
+	// Age for a single year or Ages for several.
+	Age   int   `json:"age,omitempty" yaml:"age,omitempty"`
+	Ages  []int `json:"ages,omitempty" yaml:"ages,omitempty"`
+	Match Match `json:"match,omitempty" yaml:"match,omitempty"`
+}
+
+// String returns the label of r, or its name if it has none.
+func (r Rule) String() string {
+	if r.Label != "" {
+		return r.Label
+	}
+	return r.Name
+}
+
+// Holds reports whether r holds at the given age.
+func (r Rule) Holds(age int) bool {
+	if r.Match == Exactly {
+		for _, a := range r.thresholds() {
+			if a == age {
+				return true
+			}
+		}
+		return false
+	}
+	return age >= r.Age
+}
+
+// Since returns the age at which r, holding at the given age, was reached:
+// the threshold of an at-least rule, or age itself for an exactly rule.
+func (r Rule) Since(age int) int {
+	if r.Match == Exactly {
+		return age
+	}
+	return r.Age
+}
+
+// next returns the first age after age at which r starts to hold.
+func (r Rule) next(age int) (int, bool) {
+	for _, a := range r.thresholds() {
+		if a > age {
+			return a, true
+		}
+	}
+	return 0, false
+}
+
+func (r Rule) thresholds() []int {
+	if r.Match != Exactly {
+		return []int{r.Age}
+	}
+	ages := append([]int(nil), r.Ages...)
+	if r.Age > 0 {
+		ages = append(ages, r.Age)
+	}
+	sort.Ints(ages)
+	return ages
+}
+
+func (r Rule) validate() error {
+	if r.Name == "" {
+		return fmt.Errorf("rule without a name")
+	}
+	switch r.Match {
+	case "", AtLeast:
+		if len(r.Ages) > 0 {
+			return fmt.Errorf("rule %s: ages can only be used with match %q", r.Name, Exactly)
+		}
+		if r.Age <= 0 {
+			return fmt.Errorf("rule %s: age must be positive", r.Name)
+		}
+	case Exactly:
+		if r.Age <= 0 && len(r.Ages) == 0 {
+			return fmt.Errorf("rule %s: no age given", r.Name)
+		}
+		for _, a := range r.Ages {
+			if a <= 0 {
+				return fmt.Errorf("rule %s: age must be positive", r.Name)
+			}
+		}
+	default:
+		return fmt.Errorf("rule %s: unknown match %q, want %q or %q", r.Name, r.Match, AtLeast, Exactly)
+	}
+	return nil
+}
+
+// Rules are the milestones of one jurisdiction.
+type Rules []Rule
+
+// Find returns the rule with the given name.
+func (rs Rules) Find(name string) (Rule, bool) {
+	for _, r := range rs {
+		if r.Name == name {
+			return r, true
+		}
+	}
+	return Rule{}, false
+}
+
+// Reached returns the rules that hold at the given age, in rule order.
+func (rs Rules) Reached(age int) Rules {
+	var reached Rules
+	for _, r := range rs {
+		if r.Holds(age) {
+			reached = append(reached, r)
+		}
+	}
+	return reached
+}
+
+// Next returns the rules that start to hold next after the given age
+// together with the age at which they do. It returns false if there are no
+// milestones left.
+func (rs Rules) Next(age int) (Rules, int, bool) {
+	var next Rules
+	at := 0
+	for _, r := range rs {
+		a, ok := r.next(age)
+		switch {
+		case !ok:
+		case len(next) == 0 || a < at:
+			next, at = Rules{r}, a
+		case a == at:
+			next = append(next, r)
+		}
+	}
+	return next, at, len(next) > 0
+}
@@ -0,0 +1,137 @@
+package milestones_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"gbdmp/learningo/milestones"
+	"gbdmp/learningo/people"
+)
+
+// names returns the names of rs separated by blanks.
+func names(rs milestones.Rules) string {
+	var s []string
+	for _, r := range rs {
+		s = append(s, r.Name)
+	}
+	return strings.Join(s, " ")
+}
+
+func TestNext(t *testing.T) {
+	rules := milestones.DefaultRules()
+	for _, tt := range []struct {
+		age  int
+		next string
+		at   int
+		ok   bool
+	}{
+		{0, "small-prime", 1, true},
+		{3, "small-prime", 5, true},
+		{13, "drive", 16, true},
+		{16, "small-prime", 17, true},
+		{17, "vote", 18, true},
+		{19, "retire", 67, true},
+		{67, "", 0, false},
+	} {
+		next, at, ok := rules.Next(tt.age)
+		if names(next) != tt.next || at != tt.at || ok != tt.ok {
+			t.Errorf("Next(%d) = %q, %d, %v, want %q, %d, %v", tt.age, names(next), at, ok, tt.next, tt.at, tt.ok)
+		}
+	}
+
+	// rules reached in the same year come together, in rule order
+	rules, err := milestones.Default().Rules("DE")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if next, at, _ := rules.Next(16); names(next) != "drive vote" || at != 18 {
+		t.Errorf("DE Next(16) = %q, %d, want \"drive vote\", 18", names(next), at)
+	}
+}
+
+func TestReached(t *testing.T) {
+	rules := milestones.DefaultRules()
+	for _, tt := range []struct {
+		age  int
+		want string
+	}{
+		{0, ""},
+		{7, "small-prime"},
+		{16, "drive"},
+		{17, "small-prime drive"},
+		{18, "drive vote"},
+		{70, "drive vote retire"},
+	} {
+		if got := names(rules.Reached(tt.age)); got != tt.want {
+			t.Errorf("Reached(%d) = %q, want %q", tt.age, got, tt.want)
+		}
+	}
+}
+
+func TestJurisdictions(t *testing.T) {
+	s := milestones.Default()
+	if got, want := strings.Join(s.Names(), " "), "DE UK US lessons"; got != want {
+		t.Errorf("Names = %q, want %q", got, want)
+	}
+	for _, tt := range []struct {
+		name  string
+		drive int
+	}{
+		{"", 16}, // the default: lessons
+		{milestones.DefaultJurisdiction, 16},
+		{"DE", 18},
+		{"UK", 17},
+	} {
+		rules, err := s.Rules(tt.name)
+		if err != nil {
+			t.Errorf("Rules(%q): %v", tt.name, err)
+			continue
+		}
+		if r, ok := rules.Find("drive"); !ok || r.Age != tt.drive {
+			t.Errorf("Rules(%q) drive at %d, want %d", tt.name, r.Age, tt.drive)
+		}
+	}
+	if _, err := s.Rules("XX"); err == nil || !strings.Contains(err.Error(), `"XX"`) {
+		t.Errorf("Rules of an unknown jurisdiction = %v, want an error", err)
+	}
+	if _, err := (&milestones.RuleSet{Jurisdictions: s.Jurisdictions}).Rules(""); err == nil {
+		t.Error("Rules(\"\") of a rule set without a default succeeds")
+	}
+}
+
+func TestParse(t *testing.T) {
+	for _, tt := range []struct {
+		data string
+		err  string
+	}{
+		{`{"jurisdictions": {"x": [{"name": "a", "age": 3}]}}`, ""},
+		{`{"jurisdictions": {}}`, "no jurisdictions"},
+		{`{"default": "y", "jurisdictions": {"x": [{"name": "a", "age": 3}]}}`, `"y" is not defined`},
+		{`{"jurisdictions": {"x": [{"name": "a", "age": 3}, {"name": "a", "age": 4}]}}`, "duplicate rule a"},
+		{`{"jurisdictions": {"x": [{"name": "a"}]}}`, "age must be positive"},
+		{`{"jurisdictions": {"x": [{"name": "a", "ages": [3]}]}}`, "ages can only be used"},
+		{`{"jurisdictions": {"x": [{"name": "a", "match": "exactly"}]}}`, "no age given"},
+		{`{"jurisdictions": {"x": [{"name": "a", "age": 3, "match": "most"}]}}`, "unknown match"},
+		{`{"jurisdictions": {"x": [{"name": "a", "age": 3, "color": "red"}]}}`, "unknown field"},
+	} {
+		_, err := milestones.Parse([]byte(tt.data), milestones.JSON)
+		switch {
+		case tt.err == "" && err != nil:
+			t.Errorf("Parse(%s): %v", tt.data, err)
+		case tt.err != "" && (err == nil || !strings.Contains(err.Error(), tt.err)):
+			t.Errorf("Parse(%s) = %v, want an error with %q", tt.data, err, tt.err)
+		}
+	}
+}
+
+func TestEvaluate(t *testing.T) {
+	p := people.Person{Name: "Helena", Birthday: people.NewDate(2005, time.October, 13)}
+	rep := milestones.DefaultRules().Evaluate(p, people.NewDate(2023, time.October, 12))
+	if rep.Age != 17 || names(rep.Reached) != "small-prime drive" {
+		t.Errorf("Evaluate = age %d, reached %q", rep.Age, names(rep.Reached))
+	}
+	if rep.Next == nil || rep.Next.Age != 18 || rep.Next.Date != people.NewDate(2023, time.October, 13) || names(rep.Next.Rules) != "vote" {
+		t.Errorf("Evaluate next = %+v, want vote at 18 on 13.10.2023", rep.Next)
+	}
+}
@@ -0,0 +1,15 @@
+package modcache
+
+import (
+	"io/fs"
+	"syscall"
+	"time"
+)
+
+// accessTime returns when the file of fi was last read.
+func accessTime(fi fs.FileInfo) time.Time {
+	if st, ok := fi.Sys().(*syscall.Stat_t); ok {
+		return time.Unix(st.Atim.Unix())
+	}
+	return time.Time{}
+}
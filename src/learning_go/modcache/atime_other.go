@@ -0,0 +1,14 @@
+//go:build !linux && !darwin
+
+package modcache
+
+import (
+	"io/fs"
+	"time"
+)
+
+// accessTime returns the zero time: the access time is not read on this
+// system, and the modification time has to do.
+func accessTime(fi fs.FileInfo) time.Time {
+	return time.Time{}
+}
@@ -0,0 +1,195 @@
+package modcache
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"gbdmp/learningo/internal/gotool"
+
+	"golang.org/x/mod/module"
+)
+
+// OfflineEnv returns the environment for running the go command against
+// the module cache of gopath alone: GOPROXY=off makes every module come
+// from pkg/mod, GOFLAGS=-mod=mod lets the go command fill in missing
+// requirements and go.sum lines from there, and the checksum database
+// is not asked.
+func OfflineEnv(gopath string) []string {
+	return append(os.Environ(),
+		"GOPATH="+gopath,
+		"GOMODCACHE="+Dir(gopath),
+		"GOFLAGS=-mod=mod",
+		"GOPROXY=off",
+		"GOSUMDB=off",
+		"GOWORK=off",
+		"GOTOOLCHAIN=local",
+	)
+}
+
+// Root is a manifest the module versions in the cache are needed for: a
+// go.mod file of the checkout or a pinned tool.
+type Root struct {
+	Name string // e.g. "src/learning_go/go.mod" or "tools.lock gotags"
+	Dir  string // the directory of the go.mod file
+}
+
+// FindGoMods returns the go.mod files below dir, relative to it. It does
+// not descend into testdata, vendor and hidden directories, or dir/pkg
+// with the module cache.
+func FindGoMods(dir string) ([]string, error) {
+	var mods []string
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		name := d.Name()
+		if d.IsDir() && path != dir {
+			if name == "testdata" || name == "vendor" || name[0] == '.' || name[0] == '_' ||
+				path == filepath.Join(dir, "pkg") {
+				return filepath.SkipDir
+			}
+		}
+		if !d.IsDir() && name == "go.mod" {
+			rel, err := filepath.Rel(dir, path)
+			if err != nil {
+				return err
+			}
+			mods = append(mods, rel)
+		}
+		return nil
+	})
+	return mods, err
+}
+
+// A Reference is the way a root requires a module version: the chain of
+// requirements from the root down to the version.
+type Reference struct {
+	Root  string           `json:"root"`
+	Chain []module.Version `json:"chain"` // starts with a requirement of the root, ends with the version
+}
+
+// String formats r as "root > mod@v > ...".
+func (r Reference) String() string {
+	s := r.Root
+	for _, mv := range r.Chain {
+		s += " > " + mv.String()
+	}
+	return s
+}
+
+// Graph is what the module graphs of a set of roots reference.
+type Graph struct {
+	refs map[module.Version][]Reference
+}
+
+// Reach loads the module graph of each root with "go mod graph", offline
+// against the module cache of gopath. That is the graph the go command
+// loads, pruned the way the go versions of the modules ask for: every
+// version in it counts as referenced, not just the ones minimal version
+// selection picks, as the go command needs the go.mod files of all of
+// them.
+func Reach(ctx context.Context, gopath string, roots []Root) (*Graph, error) {
+	g := &Graph{refs: map[module.Version][]Reference{}}
+	for _, root := range roots {
+		cmd := exec.CommandContext(ctx, gotool.Path(), "mod", "graph")
+		cmd.Dir = root.Dir
+		cmd.Env = OfflineEnv(gopath)
+		var stderr bytes.Buffer
+		cmd.Stderr = &stderr
+		out, err := cmd.Output()
+		if err != nil {
+			return nil, fmt.Errorf("%s: go mod graph: %v\n%s", root.Name, err, stderr.Bytes())
+		}
+		main, edges, err := parseGraph(out)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %v", root.Name, err)
+		}
+
+		// breadth first, so that every chain is a shortest one
+		chains := map[module.Version][]module.Version{}
+		queue := []module.Version{main}
+		for len(queue) > 0 {
+			from := queue[0]
+			queue = queue[1:]
+			for _, to := range edges[from] {
+				if _, seen := chains[to]; seen || to == main {
+					continue
+				}
+				chains[to] = append(append([]module.Version(nil), chains[from]...), to)
+				queue = append(queue, to)
+			}
+		}
+		for mv, chain := range chains {
+			g.refs[mv] = append(g.refs[mv], Reference{Root: root.Name, Chain: chain})
+		}
+	}
+	return g, nil
+}
+
+// parseGraph parses the output of "go mod graph" into the main module and
+// the requirements of each module version. The go and toolchain
+// pseudo-modules are left out. A main module without requirements prints
+// nothing, and its path stays empty.
+func parseGraph(out []byte) (module.Version, map[module.Version][]module.Version, error) {
+	var main module.Version
+	edges := map[module.Version][]module.Version{}
+	sc := bufio.NewScanner(bytes.NewReader(out))
+	for sc.Scan() {
+		f := strings.Fields(sc.Text())
+		if len(f) != 2 {
+			return main, nil, fmt.Errorf("bad line in go mod graph output: %q", sc.Text())
+		}
+		from, to := parseNode(f[0]), parseNode(f[1])
+		if from.Version == "" && main.Path == "" {
+			main = from
+		}
+		if pseudo(from) || pseudo(to) {
+			continue
+		}
+		edges[from] = append(edges[from], to)
+	}
+	return main, edges, sc.Err()
+}
+
+func parseNode(s string) module.Version {
+	path, version, _ := strings.Cut(s, "@")
+	return module.Version{Path: path, Version: version}
+}
+
+// pseudo reports whether mv is the go or toolchain node of the graph.
+func pseudo(mv module.Version) bool {
+	return mv.Path == "go" || mv.Path == "toolchain"
+}
+
+// Why returns how the roots reach mv, nil if they do not.
+func (g *Graph) Why(mv module.Version) []Reference {
+	refs := g.refs[mv]
+	sort.Slice(refs, func(i, j int) bool { return refs[i].Root < refs[j].Root })
+	return refs
+}
+
+// Referenced reports whether any root reaches mv.
+func (g *Graph) Referenced(mv module.Version) bool {
+	return len(g.refs[mv]) > 0
+}
+
+// Unreferenced returns the versions of mods that no root reaches.
+func (g *Graph) Unreferenced(mods []*Module) []*Version {
+	var vs []*Version
+	for _, m := range mods {
+		for _, v := range m.Versions {
+			if !g.Referenced(v.Module()) {
+				vs = append(vs, v)
+			}
+		}
+	}
+	return vs
+}
@@ -0,0 +1,341 @@
+// Package modcache reads and trims the module cache of a GOPATH: the
+// downloads in pkg/mod/cache/download and the modules extracted from them
+// in pkg/mod.
+package modcache
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"gbdmp/learningo/internal/atomicfile"
+
+	"golang.org/x/mod/module"
+	"golang.org/x/mod/semver"
+)
+
+// Dir returns the module cache of the GOPATH gopath.
+func Dir(gopath string) string {
+	return filepath.Join(gopath, "pkg", "mod")
+}
+
+// DownloadDir returns the download cache in the module cache dir, the
+// directory a GOPROXY=file:// URL can point at.
+func DownloadDir(dir string) string {
+	return filepath.Join(dir, "cache", "download")
+}
+
+// File returns the file with the extension ext, e.g. ".zip", of the
+// module version mv in the download cache of dir.
+func File(dir string, mv module.Version, ext string) (string, error) {
+	escPath, err := module.EscapePath(mv.Path)
+	if err != nil {
+		return "", err
+	}
+	escVersion, err := module.EscapeVersion(mv.Version)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(DownloadDir(dir), filepath.FromSlash(escPath), "@v", escVersion+ext), nil
+}
+
+// Version is a module version in the cache.
+type Version struct {
+	Path    string   `json:"path"`
+	Version string   `json:"version"`
+	Files   []string `json:"files"`         // the files in cache/download
+	Dir     string   `json:"dir,omitempty"` // the extracted module, "" if there is none
+	Size    int64    `json:"size"`          // of the files and the extracted module
+	// LastUsed is when a file of the version was last read or written,
+	// as far as the file system keeps track of access times.
+	LastUsed time.Time `json:"lastUsed"`
+}
+
+// Module returns the module version of v.
+func (v *Version) Module() module.Version {
+	return module.Version{Path: v.Path, Version: v.Version}
+}
+
+// HasZip reports whether the zip of the version is cached, not just its
+// go.mod file.
+func (v *Version) HasZip() bool {
+	return v.file(".zip") != ""
+}
+
+// file returns the file of v in cache/download with the extension ext,
+// "" if there is none.
+func (v *Version) file(ext string) string {
+	for _, f := range v.Files {
+		if filepath.Ext(f) == ext {
+			return f
+		}
+	}
+	return ""
+}
+
+// Module is a module with the versions of it in the cache.
+type Module struct {
+	Path     string     `json:"path"`
+	Versions []*Version `json:"versions"` // in semver order
+}
+
+// Size returns the size of all versions of m.
+func (m *Module) Size() int64 {
+	var n int64
+	for _, v := range m.Versions {
+		n += v.Size
+	}
+	return n
+}
+
+// downloadExts are the extensions of the files the go command keeps per
+// version in cache/download.
+var downloadExts = []string{".info", ".mod", ".zip", ".ziphash", ".lock", ".partial"}
+
+// List returns the modules in the module cache dir, sorted by path.
+func List(dir string) ([]*Module, error) {
+	versions := map[module.Version]*Version{}
+	get := func(path, version string) *Version {
+		mv := module.Version{Path: path, Version: version}
+		v := versions[mv]
+		if v == nil {
+			v = &Version{Path: path, Version: version}
+			versions[mv] = v
+		}
+		return v
+	}
+
+	download := DownloadDir(dir)
+	err := filepath.WalkDir(download, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if path == filepath.Join(download, "sumdb") {
+			return filepath.SkipDir
+		}
+		if !d.IsDir() || d.Name() != "@v" {
+			return nil
+		}
+		rel, err := filepath.Rel(download, filepath.Dir(path))
+		if err != nil {
+			return err
+		}
+		modPath, err := module.UnescapePath(filepath.ToSlash(rel))
+		if err != nil {
+			return nil // not a module the go command wrote
+		}
+		entries, err := os.ReadDir(path)
+		if err != nil {
+			return err
+		}
+		for _, e := range entries {
+			for _, ext := range downloadExts {
+				if !strings.HasSuffix(e.Name(), ext) {
+					continue
+				}
+				version, err := module.UnescapeVersion(strings.TrimSuffix(e.Name(), ext))
+				if err != nil {
+					break
+				}
+				v := get(modPath, version)
+				if err := v.add(filepath.Join(path, e.Name())); err != nil {
+					return err
+				}
+				break
+			}
+		}
+		return filepath.SkipDir
+	})
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return nil, err
+	}
+
+	// the extracted modules are directories path@version below dir
+	err = filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.IsDir() || path == dir {
+			return nil
+		}
+		if path == filepath.Join(dir, "cache") {
+			return filepath.SkipDir
+		}
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		escPath, escVersion, ok := strings.Cut(filepath.ToSlash(rel), "@")
+		if !ok {
+			return nil
+		}
+		modPath, err1 := module.UnescapePath(escPath)
+		version, err2 := module.UnescapeVersion(escVersion)
+		if err1 == nil && err2 == nil {
+			v := get(modPath, version)
+			v.Dir = path
+			if err := v.addDir(path); err != nil {
+				return err
+			}
+		}
+		return filepath.SkipDir
+	})
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return nil, err
+	}
+
+	byPath := map[string]*Module{}
+	var mods []*Module
+	for _, v := range versions {
+		m := byPath[v.Path]
+		if m == nil {
+			m = &Module{Path: v.Path}
+			byPath[v.Path] = m
+			mods = append(mods, m)
+		}
+		m.Versions = append(m.Versions, v)
+	}
+	sort.Slice(mods, func(i, j int) bool { return mods[i].Path < mods[j].Path })
+	for _, m := range mods {
+		sort.Slice(m.Versions, func(i, j int) bool {
+			return semver.Compare(m.Versions[i].Version, m.Versions[j].Version) < 0
+		})
+	}
+	return mods, nil
+}
+
+// add adds a file of cache/download to v.
+func (v *Version) add(path string) error {
+	fi, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+	v.Files = append(v.Files, path)
+	v.Size += fi.Size()
+	v.used(fi)
+	return nil
+}
+
+// addDir adds the extracted module in dir to v.
+func (v *Version) addDir(dir string) error {
+	return filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		fi, err := d.Info()
+		if err != nil {
+			return err
+		}
+		if path == dir {
+			v.used(fi)
+		}
+		if fi.Mode().IsRegular() {
+			v.Size += fi.Size()
+		}
+		return nil
+	})
+}
+
+// used moves LastUsed up to the access or modification time of fi.
+func (v *Version) used(fi fs.FileInfo) {
+	for _, t := range []time.Time{fi.ModTime(), accessTime(fi)} {
+		if t.After(v.LastUsed) {
+			v.LastUsed = t
+		}
+	}
+}
+
+// Remove removes the files and the extracted module of v from the module
+// cache dir, drops v from the version list of its module and removes the
+// directories below dir that are left empty. The go command extracts
+// modules read-only, so their directories are made writable first.
+func Remove(dir string, v *Version) error {
+	if v.Dir != "" {
+		err := filepath.WalkDir(v.Dir, func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if d.IsDir() {
+				return os.Chmod(path, 0o755)
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		if err := os.RemoveAll(v.Dir); err != nil {
+			return err
+		}
+		removeEmpty(filepath.Dir(v.Dir), dir)
+	}
+	if len(v.Files) == 0 {
+		return nil
+	}
+	for _, f := range v.Files {
+		if err := os.Remove(f); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return err
+		}
+	}
+	atv := filepath.Dir(v.Files[0])
+	if err := dropFromList(filepath.Join(atv, "list"), v.Version); err != nil {
+		return err
+	}
+	entries, err := os.ReadDir(atv)
+	if err != nil {
+		return err
+	}
+	for _, e := range entries {
+		if e.Name() != "list" && e.Name() != "list.lock" {
+			return nil
+		}
+	}
+	// no version is left: the list goes, too
+	if err := os.RemoveAll(atv); err != nil {
+		return err
+	}
+	removeEmpty(filepath.Dir(atv), DownloadDir(dir))
+	return nil
+}
+
+// dropFromList removes version from the version list file at path, the
+// one a GOPROXY=file:// URL answers @v/list with.
+func dropFromList(path, version string) error {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	var keep []string
+	for _, line := range strings.Split(string(data), "\n") {
+		if line != "" && line != version {
+			keep = append(keep, line)
+		}
+	}
+	list := ""
+	if len(keep) > 0 {
+		list = strings.Join(keep, "\n") + "\n"
+	}
+	return atomicfile.WriteFile(path, []byte(list), 0o644)
+}
+
+// removeEmpty removes dir and its parents up to but not including stop as
+// long as they are empty. os.Remove refuses directories that are not,
+// which ends it, and so does a dir that is not below stop.
+func removeEmpty(dir, stop string) {
+	for {
+		rel, err := filepath.Rel(stop, dir)
+		if err != nil || rel == "." || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			return
+		}
+		if os.Remove(dir) != nil {
+			return
+		}
+		dir = filepath.Dir(dir)
+	}
+}
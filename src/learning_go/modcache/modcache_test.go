@@ -0,0 +1,197 @@
+package modcache_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"gbdmp/learningo/modcache"
+
+	"golang.org/x/mod/module"
+)
+
+// writeFile writes a file below dir, creating its directory.
+func writeFile(t *testing.T, dir, name, content string) string {
+	t.Helper()
+	path := filepath.Join(dir, filepath.FromSlash(name))
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+// fakeVersion puts files for mv into the download cache of dir and, with
+// extract set, an extracted module with read-only directories like the go
+// command leaves them.
+func fakeVersion(t *testing.T, dir string, mv module.Version, extract bool, exts ...string) {
+	t.Helper()
+	escPath, _ := module.EscapePath(mv.Path)
+	escVersion, _ := module.EscapeVersion(mv.Version)
+	atv := filepath.Join(modcache.DownloadDir(dir), filepath.FromSlash(escPath), "@v")
+	for _, ext := range exts {
+		writeFile(t, atv, escVersion+ext, mv.String()+ext)
+	}
+	list, _ := os.ReadFile(filepath.Join(atv, "list"))
+	writeFile(t, atv, "list", string(list)+mv.Version+"\n")
+	if extract {
+		mod := filepath.Join(dir, filepath.FromSlash(escPath)+"@"+escVersion)
+		writeFile(t, mod, "go.mod", "module "+mv.Path+"\n")
+		writeFile(t, mod, "sub/x.go", "package sub\n")
+		for _, d := range []string{filepath.Join(mod, "sub"), mod} {
+			if err := os.Chmod(d, 0o555); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+}
+
+func fakeCache(t *testing.T) string {
+	dir := filepath.Join(t.TempDir(), "pkg", "mod")
+	fakeVersion(t, dir, module.Version{Path: "github.com/BurntSushi/toml", Version: "v1.10.0"}, true, ".info", ".mod", ".zip", ".ziphash")
+	fakeVersion(t, dir, module.Version{Path: "github.com/BurntSushi/toml", Version: "v1.2.0"}, false, ".info", ".mod")
+	fakeVersion(t, dir, module.Version{Path: "github.com/BurntSushi/toml", Version: "v1.0.0"}, true, ".mod", ".zip")
+	fakeVersion(t, dir, module.Version{Path: "golang.org/x/mod", Version: "v0.12.0"}, true, ".info", ".mod", ".zip")
+	writeFile(t, modcache.DownloadDir(dir), "sumdb/sum.golang.org/lookup/golang.org/x/mod@v0.12.0", "record")
+	t.Cleanup(func() { makeWritable(dir) })
+	return dir
+}
+
+// makeWritable lets t.TempDir clean up extracted modules.
+func makeWritable(dir string) {
+	filepath.WalkDir(dir, func(path string, d os.DirEntry, err error) error {
+		if err == nil && d.IsDir() {
+			os.Chmod(path, 0o755)
+		}
+		return nil
+	})
+}
+
+func TestList(t *testing.T) {
+	dir := fakeCache(t)
+	mods, err := modcache.List(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, m := range mods {
+		for _, v := range m.Versions {
+			got = append(got, v.Module().String())
+		}
+	}
+	want := []string{
+		"github.com/BurntSushi/toml@v1.0.0",
+		"github.com/BurntSushi/toml@v1.2.0",
+		"github.com/BurntSushi/toml@v1.10.0",
+		"golang.org/x/mod@v0.12.0",
+	}
+	if strings.Join(got, " ") != strings.Join(want, " ") {
+		t.Fatalf("List = %v, want %v", got, want)
+	}
+
+	toml := mods[0].Versions
+	if !toml[0].HasZip() || toml[1].HasZip() {
+		t.Errorf("HasZip of v1.0.0, v1.2.0 = %v, %v, want true, false", toml[0].HasZip(), toml[1].HasZip())
+	}
+	if want := filepath.Join(dir, "github.com", "!burnt!sushi", "toml@v1.10.0"); toml[2].Dir != want {
+		t.Errorf("Dir of v1.10.0 = %q, want %q", toml[2].Dir, want)
+	}
+	if toml[1].Dir != "" {
+		t.Errorf("Dir of v1.2.0 = %q, want none", toml[1].Dir)
+	}
+	if len(toml[2].Files) != 4 {
+		t.Errorf("files of v1.10.0 = %v, want 4", toml[2].Files)
+	}
+	// the files hold their own names, the extracted module two small files
+	want10 := int64(len("github.com/BurntSushi/toml@v1.10.0")*4+len(".info.mod.zip.ziphash")) +
+		int64(len("module github.com/BurntSushi/toml\n")+len("package sub\n"))
+	if toml[2].Size != want10 {
+		t.Errorf("Size of v1.10.0 = %d, want %d", toml[2].Size, want10)
+	}
+	if mods[0].Size() != toml[0].Size+toml[1].Size+toml[2].Size {
+		t.Errorf("Module.Size = %d, want the sum of its versions", mods[0].Size())
+	}
+	if toml[2].LastUsed.IsZero() {
+		t.Error("LastUsed of v1.10.0 is zero")
+	}
+}
+
+func TestListMissing(t *testing.T) {
+	mods, err := modcache.List(filepath.Join(t.TempDir(), "nothing"))
+	if err != nil || len(mods) != 0 {
+		t.Errorf("List of a missing cache = %v, %v, want nothing", mods, err)
+	}
+}
+
+func TestRemove(t *testing.T) {
+	dir := fakeCache(t)
+	mods, err := modcache.List(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	toml := mods[0].Versions
+	if err := modcache.Remove(dir, toml[2]); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(toml[2].Dir); !os.IsNotExist(err) {
+		t.Errorf("extracted module still there: %v", err)
+	}
+	atv := filepath.Dir(toml[0].Files[0])
+	list, err := os.ReadFile(filepath.Join(atv, "list"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := string(list), "v1.2.0\nv1.0.0\n"; got != want {
+		t.Errorf("list after removing v1.10.0 = %q, want %q", got, want)
+	}
+
+	if err := modcache.Remove(dir, toml[1]); err != nil {
+		t.Fatal(err)
+	}
+	if err := modcache.Remove(dir, toml[0]); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(filepath.Join(modcache.DownloadDir(dir), "github.com")); !os.IsNotExist(err) {
+		t.Errorf("empty directories of the last version are still there: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "github.com")); !os.IsNotExist(err) {
+		t.Errorf("empty directories of the extracted modules are still there: %v", err)
+	}
+
+	mods, err = modcache.List(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(mods) != 1 || mods[0].Path != "golang.org/x/mod" {
+		t.Errorf("List after removing toml = %v, want golang.org/x/mod", mods)
+	}
+}
+
+func TestRemoveStopsAtTheCache(t *testing.T) {
+	gopath := t.TempDir()
+	dir := modcache.Dir(gopath)
+	mv := module.Version{Path: "example.com/only", Version: "v1.0.0"}
+	fakeVersion(t, dir, mv, true, ".mod", ".zip")
+	t.Cleanup(func() { makeWritable(dir) })
+	mods, err := modcache.List(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := modcache.Remove(dir, mods[0].Versions[0]); err != nil {
+		t.Fatal(err)
+	}
+	// the cache is empty now, but it and everything above it stay
+	for _, d := range []string{modcache.DownloadDir(dir), dir, gopath} {
+		if _, err := os.Stat(d); err != nil {
+			t.Errorf("%s was removed: %v", d, err)
+		}
+	}
+	for _, d := range []string{filepath.Join(modcache.DownloadDir(dir), "example.com"), filepath.Join(dir, "example.com")} {
+		if _, err := os.Stat(d); !os.IsNotExist(err) {
+			t.Errorf("%s is still there", d)
+		}
+	}
+}
@@ -0,0 +1,161 @@
+package modcache
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"os"
+	"strings"
+
+	"golang.org/x/mod/sumdb/dirhash"
+)
+
+// KnownSums are the h1: hashes of module versions recorded somewhere, by
+// their go.sum key: "path version" for the zip and "path version/go.mod"
+// for the go.mod file.
+type KnownSums map[string][]Record
+
+// Record is a hash of a module version and where it is recorded, e.g.
+// "src/learning_go/go.sum".
+type Record struct {
+	Source string `json:"source"`
+	Sum    string `json:"sum"`
+}
+
+// Add records sum of the go.sum key "path version" from source.
+func (k KnownSums) Add(source, path, version, sum string) {
+	key := path + " " + version
+	for _, r := range k[key] {
+		if r == (Record{source, sum}) {
+			return
+		}
+	}
+	k[key] = append(k[key], Record{source, sum})
+}
+
+// AddLines records the go.sum lines from source, skipping lines that are
+// not "path version hash".
+func (k KnownSums) AddLines(source string, lines []string) {
+	for _, line := range lines {
+		if f := strings.Fields(line); len(f) == 3 {
+			k.Add(source, f[0], f[1], f[2])
+		}
+	}
+}
+
+// ReadGoSum records the lines of the go.sum file at path from source.
+func (k KnownSums) ReadGoSum(source, path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	var lines []string
+	sc := bufio.NewScanner(bytes.NewReader(data))
+	for sc.Scan() {
+		lines = append(lines, sc.Text())
+	}
+	k.AddLines(source, lines)
+	return sc.Err()
+}
+
+// A Check compares a hash recomputed from the cache with a record of it.
+type Check struct {
+	File   string `json:"file"` // "zip", "dir" for the extracted module or "go.mod"
+	Source string `json:"source"`
+	Want   string `json:"want"`
+	Got    string `json:"got"`
+}
+
+// OK reports whether the hashes match.
+func (c Check) OK() bool { return c.Want == c.Got }
+
+// Verification is the result of Verify for a module version.
+type Verification struct {
+	Path    string  `json:"path"`
+	Version string  `json:"version"`
+	Checks  []Check `json:"checks,omitempty"`
+	// Errors are what makes the version fail besides a check, like a zip
+	// that cannot be read.
+	Errors []string `json:"errors,omitempty"`
+	// Notes are what could not be checked.
+	Notes []string `json:"notes,omitempty"`
+}
+
+// Status of a verification.
+const (
+	Verified   = "ok"
+	Unverified = "unverified" // nothing to compare with
+	Mismatch   = "MISMATCH"
+)
+
+// Status returns Mismatch if a check failed or there are errors, Verified
+// if there is a check and Unverified otherwise.
+func (v *Verification) Status() string {
+	if len(v.Errors) > 0 {
+		return Mismatch
+	}
+	for _, c := range v.Checks {
+		if !c.OK() {
+			return Mismatch
+		}
+	}
+	if len(v.Checks) == 0 {
+		return Unverified
+	}
+	return Verified
+}
+
+// Verify recomputes the h1: hashes of the zip, the extracted module and
+// the go.mod file of v, as far as they are cached, and compares them with
+// the .ziphash file of the zip and the records in known.
+func Verify(v *Version, known KnownSums) *Verification {
+	res := &Verification{Path: v.Path, Version: v.Version}
+	mv := v.Module()
+	// the zip and the module extracted from it have the same hash
+	zipRecords := known[v.Path+" "+v.Version]
+	if ziphash := v.file(".ziphash"); ziphash != "" {
+		want, err := os.ReadFile(ziphash)
+		if err != nil {
+			res.Errors = append(res.Errors, "ziphash: "+err.Error())
+		} else {
+			zipRecords = append([]Record{{".ziphash", strings.TrimSpace(string(want))}}, zipRecords...)
+		}
+	}
+
+	if zip := v.file(".zip"); zip != "" {
+		got, err := dirhash.HashZip(zip, dirhash.Hash1)
+		if err != nil {
+			res.Errors = append(res.Errors, "zip: "+err.Error())
+		} else {
+			res.compare("zip", got, zipRecords)
+		}
+	}
+	if v.Dir != "" {
+		got, err := dirhash.HashDir(v.Dir, mv.String(), dirhash.Hash1)
+		if err != nil {
+			res.Errors = append(res.Errors, "dir: "+err.Error())
+		} else {
+			res.compare("dir", got, zipRecords)
+		}
+	}
+	if mod := v.file(".mod"); mod != "" {
+		got, err := dirhash.Hash1([]string{"go.mod"}, func(string) (io.ReadCloser, error) {
+			return os.Open(mod)
+		})
+		if err != nil {
+			res.Errors = append(res.Errors, "go.mod: "+err.Error())
+		} else {
+			res.compare("go.mod", got, known[v.Path+" "+v.Version+"/go.mod"])
+		}
+	}
+	return res
+}
+
+func (res *Verification) compare(file, got string, records []Record) {
+	if len(records) == 0 {
+		res.Notes = append(res.Notes, file+": no record to compare with")
+	}
+	for _, r := range records {
+		res.Checks = append(res.Checks, Check{File: file, Source: r.Source, Want: r.Sum, Got: got})
+	}
+}
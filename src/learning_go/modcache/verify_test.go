@@ -0,0 +1,187 @@
+package modcache_test
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"gbdmp/learningo/modcache"
+
+	"golang.org/x/mod/module"
+	"golang.org/x/mod/sumdb/dirhash"
+	modzip "golang.org/x/mod/zip"
+)
+
+// realVersion puts a zip, its .ziphash, the go.mod file and the extracted
+// module of mv into the cache dir the way the go command does and returns
+// the go.sum lines of mv.
+func realVersion(t *testing.T, dir string, mv module.Version) []string {
+	t.Helper()
+	src := t.TempDir()
+	gomod := "module " + mv.Path + "\n\ngo 1.21\n"
+	writeFile(t, src, "go.mod", gomod)
+	writeFile(t, src, "x.go", "package x\n")
+
+	escPath, _ := module.EscapePath(mv.Path)
+	atv := filepath.Join(modcache.DownloadDir(dir), filepath.FromSlash(escPath), "@v")
+	zip := writeFile(t, atv, mv.Version+".zip", "")
+	f, err := os.Create(zip)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := modzip.CreateFromDir(f, mv, src); err != nil {
+		t.Fatal(err)
+	}
+	if err := f.Close(); err != nil {
+		t.Fatal(err)
+	}
+	zipSum, err := dirhash.HashZip(zip, dirhash.Hash1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	writeFile(t, atv, mv.Version+".ziphash", zipSum)
+	mod := writeFile(t, atv, mv.Version+".mod", gomod)
+	modSum, err := dirhash.Hash1([]string{"go.mod"}, func(string) (io.ReadCloser, error) { return os.Open(mod) })
+	if err != nil {
+		t.Fatal(err)
+	}
+	writeFile(t, atv, "list", mv.Version+"\n")
+	if err := modzip.Unzip(filepath.Join(dir, filepath.FromSlash(escPath)+"@"+mv.Version), mv, zip); err != nil {
+		t.Fatal(err)
+	}
+	return []string{
+		mv.Path + " " + mv.Version + " " + zipSum,
+		mv.Path + " " + mv.Version + "/go.mod " + modSum,
+	}
+}
+
+// version returns the only cached version in dir.
+func version(t *testing.T, dir string) *modcache.Version {
+	t.Helper()
+	mods, err := modcache.List(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(mods) != 1 || len(mods[0].Versions) != 1 {
+		t.Fatalf("List = %v, want one version", mods)
+	}
+	return mods[0].Versions[0]
+}
+
+var verified = module.Version{Path: "example.com/verified", Version: "v1.0.0"}
+
+func TestVerify(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "pkg", "mod")
+	t.Cleanup(func() { makeWritable(dir) })
+	gosum := realVersion(t, dir, verified)
+	v := version(t, dir)
+
+	known := modcache.KnownSums{}
+	known.AddLines("go.sum", gosum)
+	res := modcache.Verify(v, known)
+	if res.Status() != modcache.Verified || len(res.Errors) != 0 || len(res.Notes) != 0 {
+		t.Fatalf("Verify = %+v, want %s", res, modcache.Verified)
+	}
+	var checks []string
+	for _, c := range res.Checks {
+		checks = append(checks, c.File+" "+c.Source)
+	}
+	if got, want := strings.Join(checks, ", "), "zip .ziphash, zip go.sum, dir .ziphash, dir go.sum, go.mod go.sum"; got != want {
+		t.Errorf("checks = %s, want %s", got, want)
+	}
+
+	// without go.sum lines only the .ziphash file is compared with
+	res = modcache.Verify(v, modcache.KnownSums{})
+	if res.Status() != modcache.Verified || len(res.Checks) != 2 || len(res.Notes) != 1 || !strings.HasPrefix(res.Notes[0], "go.mod:") {
+		t.Errorf("Verify without go.sum = %+v", res)
+	}
+}
+
+func TestVerifyUnverified(t *testing.T) {
+	dir := fakeCache(t)
+	mods, err := modcache.List(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// v1.2.0 of toml has only its .info and .mod files
+	res := modcache.Verify(mods[0].Versions[1], modcache.KnownSums{})
+	if res.Status() != modcache.Unverified || len(res.Checks) != 0 || len(res.Notes) != 1 {
+		t.Errorf("Verify = %+v, want %s", res, modcache.Unverified)
+	}
+}
+
+func TestVerifyMismatch(t *testing.T) {
+	for _, tt := range []struct {
+		name   string
+		change func(t *testing.T, dir string, v *modcache.Version)
+		check  string // the check that fails, "" for an error
+	}{
+		{"changed module", func(t *testing.T, dir string, v *modcache.Version) {
+			makeWritable(v.Dir)
+			writeFile(t, v.Dir, "x.go", "package x // changed\n")
+		}, "dir"},
+		{"added file", func(t *testing.T, dir string, v *modcache.Version) {
+			makeWritable(v.Dir)
+			writeFile(t, v.Dir, "y.go", "package x\n")
+		}, "dir"},
+		{"changed go.mod", func(t *testing.T, dir string, v *modcache.Version) {
+			writeFile(t, filepath.Dir(v.Files[0]), verified.Version+".mod", "module example.com/other\n")
+		}, "go.mod"},
+		{"changed ziphash", func(t *testing.T, dir string, v *modcache.Version) {
+			writeFile(t, filepath.Dir(v.Files[0]), verified.Version+".ziphash", "h1:AAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAA=")
+		}, "zip"},
+		{"broken zip", func(t *testing.T, dir string, v *modcache.Version) {
+			writeFile(t, filepath.Dir(v.Files[0]), verified.Version+".zip", "not a zip")
+		}, ""},
+	} {
+		dir := filepath.Join(t.TempDir(), "pkg", "mod")
+		t.Cleanup(func() { makeWritable(dir) })
+		known := modcache.KnownSums{}
+		known.AddLines("go.sum", realVersion(t, dir, verified))
+		v := version(t, dir)
+		tt.change(t, dir, v)
+
+		res := modcache.Verify(v, known)
+		if res.Status() != modcache.Mismatch {
+			t.Errorf("%s: Verify = %+v, want %s", tt.name, res, modcache.Mismatch)
+			continue
+		}
+		var failed []string
+		for _, c := range res.Checks {
+			if !c.OK() {
+				failed = append(failed, c.File)
+			}
+		}
+		if tt.check == "" {
+			if len(res.Errors) == 0 {
+				t.Errorf("%s: Verify = %+v, want an error", tt.name, res)
+			}
+		} else if len(failed) == 0 || failed[0] != tt.check {
+			t.Errorf("%s: failed checks %v, want %s", tt.name, failed, tt.check)
+		}
+	}
+}
+
+func TestKnownSums(t *testing.T) {
+	gosum := writeFile(t, t.TempDir(), "go.sum", "example.com/a v1.0.0 h1:abc=\n"+
+		"example.com/a v1.0.0/go.mod h1:def=\n"+
+		"example.com/a v1.0.0 h1:abc=\n"+
+		"not a go.sum line\n")
+	known := modcache.KnownSums{}
+	if err := known.ReadGoSum("a/go.sum", gosum); err != nil {
+		t.Fatal(err)
+	}
+	known.Add("b/go.sum", "example.com/a", "v1.0.0", "h1:abc=")
+	if len(known) != 2 {
+		t.Errorf("known = %v, want two keys", known)
+	}
+	want := []modcache.Record{{"a/go.sum", "h1:abc="}, {"b/go.sum", "h1:abc="}}
+	if got := known["example.com/a v1.0.0"]; len(got) != 2 || got[0] != want[0] || got[1] != want[1] {
+		t.Errorf("records of the zip = %v, want %v", got, want)
+	}
+	if err := known.ReadGoSum("x", filepath.Join(t.TempDir(), "missing")); err == nil {
+		t.Error("ReadGoSum of a missing file succeeds")
+	}
+}
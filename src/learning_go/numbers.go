@@ -0,0 +1,87 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"gbdmp/learningo/numbers"
+)
+
+// runNumbers evaluates an expression in every numeric type.
+func runNumbers(e *env, args []string) error {
+	fs := newFlagSet(e, "numbers")
+	format := formatFlag(fs)
+	pos, err := parseArgs(fs, args)
+	if err != nil {
+		return err
+	}
+	src := strings.Join(pos, " ")
+	if len(pos) == 0 || src == "-" {
+		data, err := io.ReadAll(os.Stdin)
+		if err != nil {
+			return err
+		}
+		src = strings.TrimSpace(string(data))
+	}
+	ev, err := numbers.Eval(src)
+	if err != nil {
+		return err
+	}
+
+	type resultRow struct {
+		numbers.Result
+		Formats map[string]string `json:"formats,omitempty"`
+	}
+	rows := []resultRow{}
+	t := &table{header: append([]string{"Type"}, numbers.Verbs...)}
+	for _, r := range ev.Results {
+		row := resultRow{Result: r}
+		cells := []string{r.Type}
+		switch {
+		case r.Panic != "":
+			cells = append(cells, "panic: "+r.Panic)
+		case r.Err != "":
+			cells = append(cells, "error: "+r.Err)
+		default:
+			row.Formats = map[string]string{}
+			for _, verb := range numbers.Verbs {
+				row.Formats[verb] = r.Format(verb)
+				cells = append(cells, r.Format(verb))
+			}
+		}
+		rows = append(rows, row)
+		t.add(cells...)
+	}
+	t.value = struct {
+		Expr    string      `json:"expr"`
+		Exact   string      `json:"exact,omitempty"`
+		Results []resultRow `json:"results"`
+	}{ev.Expr, ev.Exact, rows}
+	if err := t.write(e.stdout, *format); err != nil || *format != "table" {
+		return err
+	}
+
+	if ev.Exact != "" {
+		fmt.Fprintf(e.stdout, "\nexact result: %s\n", ev.Exact)
+	}
+	// types with the same note share a line
+	var notes []string
+	types := map[string][]string{}
+	for _, r := range ev.Results {
+		for _, n := range r.Notes {
+			if _, ok := types[n]; !ok {
+				notes = append(notes, n)
+			}
+			types[n] = append(types[n], r.Type)
+		}
+	}
+	if len(notes) > 0 {
+		fmt.Fprintln(e.stdout)
+	}
+	for _, n := range notes {
+		fmt.Fprint(e.stdout, wrap(strings.Join(types[n], ", ")+": "+n))
+	}
+	return nil
+}
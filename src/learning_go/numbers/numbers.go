@@ -0,0 +1,626 @@
+// Package numbers evaluates an arithmetic expression in each of Go's
+// numeric types, as if every number in it were a variable of that type:
+// the operations happen at run time, so integers wrap around, integer
+// division truncates, floats round and dividing by zero panics or gives an
+// infinity instead of failing to compile. Next to the builtin types the
+// expression is evaluated with math/big's Int, Rat and Float, and every
+// result is compared with the exact one.
+package numbers
+
+import (
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// ErrExpr is returned for expressions that are not arithmetic on number
+// literals.
+var ErrExpr = errors.New("not an arithmetic expression")
+
+// Types are the types an expression is evaluated in, in this order.
+var Types = []string{
+	"int8", "int16", "int32", "int64", "int",
+	"uint8", "uint16", "uint32", "uint64", "uint",
+	"float32", "float64",
+	"big.Int", "big.Rat", "big.Float",
+}
+
+// Verbs are the formatting verbs results are shown with.
+var Verbs = []string{"%v", "%g", "%e", "%b"}
+
+// FloatPrec is the precision in bits of the big.Float evaluation, the
+// default of big.ParseFloat.
+const FloatPrec = 64
+
+// Result is an expression evaluated in one type.
+type Result struct {
+	Type  string   `json:"type"`
+	Value any      `json:"-"`               // the result, nil if there is none
+	Panic string   `json:"panic,omitempty"` // the run-time panic the evaluation ends in
+	Err   string   `json:"error,omitempty"` // why the expression cannot be evaluated in the type
+	Notes []string `json:"notes,omitempty"`
+}
+
+// Format prints the value of r with a verb, e.g. "%b". It returns "" if
+// there is no value, and "-" if the type of the value has no use for the
+// verb, where fmt would print something like %!g(int8=-56).
+func (r Result) Format(verb string) string {
+	if r.Value == nil {
+		return ""
+	}
+	if !supports(r.Value, verb) {
+		return "-"
+	}
+	return fmt.Sprintf(verb, r.Value)
+}
+
+// supports reports whether fmt prints v with verb. A big.Rat is not a
+// fmt.Formatter and only prints with %v and %s; fmt would print its fields
+// for other verbs.
+func supports(v any, verb string) bool {
+	if verb == "" {
+		return false
+	}
+	c := verb[len(verb)-1]
+	switch v.(type) {
+	case float32, float64, *big.Float:
+		return strings.IndexByte("vbeEfFgGxX", c) >= 0
+	case *big.Rat:
+		return c == 'v' || c == 's'
+	}
+	// the integer types and big.Int
+	return strings.IndexByte("vbdoOxXc", c) >= 0
+}
+
+// Evaluation is an expression evaluated in every type.
+type Evaluation struct {
+	Expr string `json:"expr"`
+	// Exact is the mathematically exact result, empty when there is none
+	// because of a division by zero.
+	Exact   string   `json:"exact,omitempty"`
+	Results []Result `json:"results"`
+}
+
+// Eval evaluates expr, an expression of integer and floating-point
+// literals, parentheses, unary + and -, and the binary operators + - * /
+// % << and >>.
+func Eval(expr string) (*Evaluation, error) {
+	e, err := parser.ParseExpr(expr)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrExpr, err)
+	}
+	if err := check(e); err != nil {
+		return nil, err
+	}
+	ev := &Evaluation{Expr: expr}
+	exact, exactErr := evalRat(e, false)
+	if exactErr == nil {
+		ev.Exact = ratString(exact)
+	}
+	for _, typ := range Types {
+		r := Result{Type: typ}
+		func() {
+			defer func() {
+				if p := recover(); p != nil {
+					r.Value, r.Panic = nil, fmt.Sprint(p)
+				}
+			}()
+			evaluators[typ](e, &r)
+		}()
+		if r.Value != nil && exactErr == nil {
+			r.Notes = append(r.Notes, compare(&r, e, exact)...)
+		}
+		ev.Results = append(ev.Results, r)
+	}
+	return ev, nil
+}
+
+// check reports the parts of e that Eval does not handle.
+func check(e ast.Expr) error {
+	var err error
+	ast.Inspect(e, func(n ast.Node) bool {
+		if err != nil || n == nil {
+			return false
+		}
+		switch n := n.(type) {
+		case *ast.BasicLit:
+			if n.Kind != token.INT && n.Kind != token.FLOAT {
+				err = fmt.Errorf("%w: %s is not a number", ErrExpr, n.Value)
+			}
+		case *ast.ParenExpr:
+		case *ast.UnaryExpr:
+			if n.Op != token.ADD && n.Op != token.SUB {
+				err = fmt.Errorf("%w: unary %s is not supported", ErrExpr, n.Op)
+			}
+		case *ast.BinaryExpr:
+			switch n.Op {
+			case token.ADD, token.SUB, token.MUL, token.QUO, token.REM, token.SHL, token.SHR:
+			default:
+				err = fmt.Errorf("%w: operator %s is not supported", ErrExpr, n.Op)
+			}
+		default:
+			err = fmt.Errorf("%w: %T is not supported", ErrExpr, n)
+		}
+		return err == nil
+	})
+	return err
+}
+
+// literal returns the exact value of a number literal.
+func literal(lit *ast.BasicLit) *big.Rat {
+	s := strings.ReplaceAll(lit.Value, "_", "")
+	if lit.Kind == token.INT {
+		i, ok := new(big.Int).SetString(s, 0)
+		if ok {
+			return new(big.Rat).SetInt(i)
+		}
+	}
+	r, ok := new(big.Rat).SetString(s)
+	if !ok {
+		// hexadecimal floats
+		f, _, err := big.ParseFloat(s, 0, 1000, big.ToNearestEven)
+		if err != nil {
+			panic("numbers: bad literal " + lit.Value)
+		}
+		r, _ = f.Rat(nil)
+	}
+	return r
+}
+
+var errDivZero = errors.New("division by zero")
+
+// evalRat evaluates e exactly. With trunc set, literals and quotients are
+// truncated to integers, as integer types do.
+func evalRat(e ast.Expr, trunc bool) (*big.Rat, error) {
+	switch e := e.(type) {
+	case *ast.BasicLit:
+		r := literal(e)
+		if trunc {
+			r = truncRat(r)
+		}
+		return r, nil
+	case *ast.ParenExpr:
+		return evalRat(e.X, trunc)
+	case *ast.UnaryExpr:
+		x, err := evalRat(e.X, trunc)
+		if err == nil && e.Op == token.SUB {
+			x.Neg(x)
+		}
+		return x, err
+	case *ast.BinaryExpr:
+		x, err := evalRat(e.X, trunc)
+		if err != nil {
+			return nil, err
+		}
+		y, err := evalRat(e.Y, trunc)
+		if err != nil {
+			return nil, err
+		}
+		switch e.Op {
+		case token.ADD:
+			return x.Add(x, y), nil
+		case token.SUB:
+			return x.Sub(x, y), nil
+		case token.MUL:
+			return x.Mul(x, y), nil
+		case token.QUO, token.REM:
+			if y.Sign() == 0 {
+				return nil, errDivZero
+			}
+			q := new(big.Rat).Quo(x, y)
+			if e.Op == token.QUO {
+				if trunc {
+					q = truncRat(q)
+				}
+				return q, nil
+			}
+			if !x.IsInt() || !y.IsInt() {
+				return nil, errors.New("% on fractions")
+			}
+			return x.Sub(x, y.Mul(y, truncRat(q))), nil
+		case token.SHL, token.SHR:
+			if !x.IsInt() || !y.IsInt() || y.Sign() < 0 || y.Num().BitLen() > 16 {
+				return nil, errors.New("bad shift")
+			}
+			n := uint(y.Num().Uint64())
+			if e.Op == token.SHL {
+				return x.SetInt(new(big.Int).Lsh(x.Num(), n)), nil
+			}
+			return x.SetInt(new(big.Int).Rsh(x.Num(), n)), nil
+		}
+	}
+	panic(fmt.Sprintf("numbers: unexpected %T", e))
+}
+
+// truncRat truncates r towards zero.
+func truncRat(r *big.Rat) *big.Rat {
+	return new(big.Rat).SetInt(new(big.Int).Quo(r.Num(), r.Denom()))
+}
+
+// ratString prints r as an integer, a decimal fraction if it has a finite
+// one, or a fraction.
+func ratString(r *big.Rat) string {
+	if r.IsInt() {
+		return r.Num().String()
+	}
+	d := new(big.Int).Set(r.Denom())
+	digits := 0
+	for _, p := range []int64{2, 5} {
+		bp := big.NewInt(p)
+		m := new(big.Int)
+		for n := 0; ; n++ {
+			q, rem := new(big.Int).QuoRem(d, bp, m)
+			if rem.Sign() != 0 {
+				digits = max(digits, n)
+				break
+			}
+			d = q
+		}
+	}
+	if d.Cmp(big.NewInt(1)) != 0 || digits > 40 {
+		return r.RatString()
+	}
+	return r.FloatString(digits)
+}
+
+// compare notes how the result differs from the exact one.
+func compare(r *Result, e ast.Expr, exact *big.Rat) []string {
+	switch v := r.Value.(type) {
+	case float32, float64, *big.Float:
+		var f *big.Float
+		switch v := v.(type) {
+		case float32:
+			if math.IsNaN(float64(v)) {
+				return nil
+			}
+			f = big.NewFloat(float64(v))
+		case float64:
+			if math.IsNaN(v) {
+				return nil
+			}
+			f = big.NewFloat(v)
+		case *big.Float:
+			f = v
+		}
+		if f.IsInf() {
+			return []string{"overflowed: the exact result is " + ratString(exact)}
+		}
+		got, _ := f.Rat(nil)
+		if got.Cmp(exact) != 0 {
+			return []string{"rounded: the exact result is " + ratString(exact)}
+		}
+	case *big.Rat:
+	default:
+		got, ok := new(big.Rat).SetString(fmt.Sprint(v))
+		if !ok {
+			return nil
+		}
+		ints, err := evalRat(e, true)
+		if err != nil {
+			return nil
+		}
+		for p, ok := e.(*ast.ParenExpr); ok; p, ok = e.(*ast.ParenExpr) {
+			e = p.X
+		}
+		// a lone literal that wraps around has a note of its own
+		if _, lit := e.(*ast.BasicLit); got.Cmp(ints) != 0 && !lit {
+			return []string{fmt.Sprintf("wrapped around: the result in integers is %s", ratString(ints))}
+		}
+		if ints.Cmp(exact) != 0 {
+			return []string{"truncated: the exact result is " + ratString(exact)}
+		}
+	}
+	return nil
+}
+
+var evaluators = map[string]func(ast.Expr, *Result){
+	"int8":      evalInto[int8],
+	"int16":     evalInto[int16],
+	"int32":     evalInto[int32],
+	"int64":     evalInto[int64],
+	"int":       evalInto[int],
+	"uint8":     evalInto[uint8],
+	"uint16":    evalInto[uint16],
+	"uint32":    evalInto[uint32],
+	"uint64":    evalInto[uint64],
+	"uint":      evalInto[uint],
+	"float32":   evalIntoFloat[float32],
+	"float64":   evalIntoFloat[float64],
+	"big.Int":   evalBigInt,
+	"big.Rat":   evalBigRat,
+	"big.Float": evalBigFloat,
+}
+
+type integer interface {
+	~int | ~int8 | ~int16 | ~int32 | ~int64 | ~uint | ~uint8 | ~uint16 | ~uint32 | ~uint64
+}
+
+type float interface {
+	~float32 | ~float64
+}
+
+func evalInto[T integer](e ast.Expr, r *Result) {
+	r.Value = evalInt[T](e, r)
+}
+
+// evalInt evaluates e with values of type T. A literal is stored the way
+// converting an integer variable would: a fraction is truncated and an
+// integer that does not fit wraps around.
+func evalInt[T integer](e ast.Expr, r *Result) T {
+	switch e := e.(type) {
+	case *ast.BasicLit:
+		exact := literal(e)
+		i := new(big.Int).Quo(exact.Num(), exact.Denom())
+		if !exact.IsInt() {
+			r.Notes = append(r.Notes, fmt.Sprintf("%s is truncated to %s", e.Value, i))
+		}
+		low := new(big.Int).And(i, new(big.Int).SetUint64(math.MaxUint64))
+		v := T(low.Uint64())
+		if fmt.Sprint(v) != i.String() {
+			r.Notes = append(r.Notes, fmt.Sprintf("%s does not fit in %s, it wraps around to %v", i, r.Type, v))
+		}
+		return v
+	case *ast.ParenExpr:
+		return evalInt[T](e.X, r)
+	case *ast.UnaryExpr:
+		x := evalInt[T](e.X, r)
+		if e.Op == token.SUB {
+			return -x
+		}
+		return x
+	case *ast.BinaryExpr:
+		x, y := evalInt[T](e.X, r), evalInt[T](e.Y, r)
+		switch e.Op {
+		case token.ADD:
+			return x + y
+		case token.SUB:
+			return x - y
+		case token.MUL:
+			return x * y
+		case token.QUO:
+			return x / y
+		case token.REM:
+			return x % y
+		case token.SHL:
+			return x << y
+		case token.SHR:
+			return x >> y
+		}
+	}
+	panic(fmt.Sprintf("numbers: unexpected %T", e))
+}
+
+func evalIntoFloat[T float](e ast.Expr, r *Result) {
+	v, err := evalFloat[T](e, r)
+	if err != nil {
+		r.Err = err.Error()
+		return
+	}
+	r.Value = v
+}
+
+// evalFloat evaluates e with values of type T, a literal being rounded to
+// the nearest T.
+func evalFloat[T float](e ast.Expr, r *Result) (T, error) {
+	switch e := e.(type) {
+	case *ast.BasicLit:
+		bits := 64
+		if r.Type == "float32" {
+			bits = 32
+		}
+		f, err := strconv.ParseFloat(strings.ReplaceAll(e.Value, "_", ""), bits)
+		switch {
+		case errors.Is(err, strconv.ErrRange):
+			r.Notes = append(r.Notes, fmt.Sprintf("%s is out of the range of %s, it becomes %v", e.Value, r.Type, f))
+		case err != nil:
+			return 0, err
+		}
+		v := T(f)
+		if exact, got := literal(e), new(big.Rat); !math.IsInf(f, 0) && got.SetFloat64(float64(v)).Cmp(exact) != 0 {
+			r.Notes = append(r.Notes, fmt.Sprintf("%s is not exact in %s, it is stored as %.20g", e.Value, r.Type, v))
+		}
+		return v, nil
+	case *ast.ParenExpr:
+		return evalFloat[T](e.X, r)
+	case *ast.UnaryExpr:
+		x, err := evalFloat[T](e.X, r)
+		if e.Op == token.SUB {
+			return -x, err
+		}
+		return x, err
+	case *ast.BinaryExpr:
+		x, err := evalFloat[T](e.X, r)
+		if err != nil {
+			return 0, err
+		}
+		y, err := evalFloat[T](e.Y, r)
+		if err != nil {
+			return 0, err
+		}
+		switch e.Op {
+		case token.ADD:
+			return x + y, nil
+		case token.SUB:
+			return x - y, nil
+		case token.MUL:
+			return x * y, nil
+		case token.QUO:
+			if y == 0 {
+				r.Notes = append(r.Notes, fmt.Sprintf("%v / 0 is %v, floats do not panic", x, x/y))
+			}
+			return x / y, nil
+		}
+		return 0, fmt.Errorf("operator %s is not defined on %s", e.Op, r.Type)
+	}
+	panic(fmt.Sprintf("numbers: unexpected %T", e))
+}
+
+func evalBigInt(e ast.Expr, r *Result) {
+	v, err := bigInt(e, r)
+	if err != nil {
+		r.Err = err.Error()
+		return
+	}
+	r.Value = v
+}
+
+// bigInt evaluates e with big.Int, which truncates like the integer types
+// but never wraps around.
+func bigInt(e ast.Expr, r *Result) (*big.Int, error) {
+	switch e := e.(type) {
+	case *ast.BasicLit:
+		exact := literal(e)
+		i := new(big.Int).Quo(exact.Num(), exact.Denom())
+		if !exact.IsInt() {
+			r.Notes = append(r.Notes, fmt.Sprintf("%s is truncated to %s", e.Value, i))
+		}
+		return i, nil
+	case *ast.ParenExpr:
+		return bigInt(e.X, r)
+	case *ast.UnaryExpr:
+		x, err := bigInt(e.X, r)
+		if err == nil && e.Op == token.SUB {
+			x.Neg(x)
+		}
+		return x, err
+	case *ast.BinaryExpr:
+		x, err := bigInt(e.X, r)
+		if err != nil {
+			return nil, err
+		}
+		y, err := bigInt(e.Y, r)
+		if err != nil {
+			return nil, err
+		}
+		switch e.Op {
+		case token.ADD:
+			return x.Add(x, y), nil
+		case token.SUB:
+			return x.Sub(x, y), nil
+		case token.MUL:
+			return x.Mul(x, y), nil
+		case token.QUO:
+			return x.Quo(x, y), nil
+		case token.REM:
+			return x.Rem(x, y), nil
+		case token.SHL, token.SHR:
+			if y.Sign() < 0 || !y.IsUint64() || y.Uint64() > 1<<16 {
+				return nil, fmt.Errorf("shift count %s is out of range", y)
+			}
+			if e.Op == token.SHL {
+				return x.Lsh(x, uint(y.Uint64())), nil
+			}
+			return x.Rsh(x, uint(y.Uint64())), nil
+		}
+	}
+	panic(fmt.Sprintf("numbers: unexpected %T", e))
+}
+
+func evalBigRat(e ast.Expr, r *Result) {
+	v, err := bigRat(e)
+	if err != nil {
+		r.Err = err.Error()
+		return
+	}
+	r.Value = v
+	r.Notes = append(r.Notes, "big.Rat only prints with %v and %s, FloatString gives decimals")
+}
+
+// bigRat evaluates e with big.Rat, which is exact.
+func bigRat(e ast.Expr) (*big.Rat, error) {
+	switch e := e.(type) {
+	case *ast.BasicLit:
+		return literal(e), nil
+	case *ast.ParenExpr:
+		return bigRat(e.X)
+	case *ast.UnaryExpr:
+		x, err := bigRat(e.X)
+		if err == nil && e.Op == token.SUB {
+			x.Neg(x)
+		}
+		return x, err
+	case *ast.BinaryExpr:
+		x, err := bigRat(e.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := bigRat(e.Y)
+		if err != nil {
+			return nil, err
+		}
+		switch e.Op {
+		case token.ADD:
+			return x.Add(x, y), nil
+		case token.SUB:
+			return x.Sub(x, y), nil
+		case token.MUL:
+			return x.Mul(x, y), nil
+		case token.QUO:
+			return x.Quo(x, y), nil
+		}
+		return nil, fmt.Errorf("big.Rat has no operator %s", e.Op)
+	}
+	panic(fmt.Sprintf("numbers: unexpected %T", e))
+}
+
+func evalBigFloat(e ast.Expr, r *Result) {
+	v, err := bigFloat(e, r)
+	if err != nil {
+		r.Err = err.Error()
+		return
+	}
+	r.Value = v
+}
+
+// bigFloat evaluates e with big.Float values of FloatPrec bits.
+func bigFloat(e ast.Expr, r *Result) (*big.Float, error) {
+	switch e := e.(type) {
+	case *ast.BasicLit:
+		f, _, err := big.ParseFloat(strings.ReplaceAll(e.Value, "_", ""), 0, FloatPrec, big.ToNearestEven)
+		if err != nil {
+			return nil, err
+		}
+		if exact, _ := f.Rat(nil); exact.Cmp(literal(e)) != 0 {
+			r.Notes = append(r.Notes, fmt.Sprintf("%s is not exact in %d bits, it is stored as %.25g", e.Value, FloatPrec, f))
+		}
+		return f, nil
+	case *ast.ParenExpr:
+		return bigFloat(e.X, r)
+	case *ast.UnaryExpr:
+		x, err := bigFloat(e.X, r)
+		if err == nil && e.Op == token.SUB {
+			x.Neg(x)
+		}
+		return x, err
+	case *ast.BinaryExpr:
+		x, err := bigFloat(e.X, r)
+		if err != nil {
+			return nil, err
+		}
+		y, err := bigFloat(e.Y, r)
+		if err != nil {
+			return nil, err
+		}
+		switch e.Op {
+		case token.ADD:
+			return x.Add(x, y), nil
+		case token.SUB:
+			return x.Sub(x, y), nil
+		case token.MUL:
+			return x.Mul(x, y), nil
+		case token.QUO:
+			if y.Sign() == 0 && x.Sign() != 0 {
+				r.Notes = append(r.Notes, fmt.Sprintf("%v / 0 is %v", x, new(big.Float).Quo(x, y)))
+			}
+			return x.Quo(x, y), nil
+		}
+		return nil, fmt.Errorf("big.Float has no operator %s", e.Op)
+	}
+	panic(fmt.Sprintf("numbers: unexpected %T", e))
+}
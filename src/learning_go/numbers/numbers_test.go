@@ -0,0 +1,173 @@
+package numbers_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"gbdmp/learningo/numbers"
+)
+
+func eval(t *testing.T, expr string) *numbers.Evaluation {
+	t.Helper()
+	ev, err := numbers.Eval(expr)
+	if err != nil {
+		t.Fatalf("Eval(%q): %v", expr, err)
+	}
+	if len(ev.Results) != len(numbers.Types) {
+		t.Fatalf("Eval(%q) has %d results, want one per type", expr, len(ev.Results))
+	}
+	return ev
+}
+
+func result(ev *numbers.Evaluation, typ string) numbers.Result {
+	for _, r := range ev.Results {
+		if r.Type == typ {
+			return r
+		}
+	}
+	return numbers.Result{}
+}
+
+func hasNote(r numbers.Result, prefix string) bool {
+	for _, n := range r.Notes {
+		if strings.HasPrefix(n, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+func TestEval(t *testing.T) {
+	for _, tt := range []struct {
+		expr, exact string
+		typ, value  string // value as printed with %v
+		note        string // the start of a note the result has, if any
+	}{
+		// wraparound
+		{"100 + 100", "200", "int8", "-56", "wrapped around: the result in integers is 200"},
+		{"100 + 100", "200", "uint8", "200", ""},
+		{"100 + 100", "200", "int16", "200", ""},
+		{"200", "200", "int8", "-56", "200 does not fit in int8, it wraps around to -56"},
+		{"0 - 1", "-1", "uint8", "255", "wrapped around"},
+		{"-128 - 1", "-129", "int8", "127", "wrapped around"},
+		// integer division truncates towards zero
+		{"7 / 2", "3.5", "int", "3", "truncated: the exact result is 3.5"},
+		{"(0 - 7) / 2", "-3.5", "int", "-3", "truncated"},
+		{"7 / 2", "3.5", "big.Int", "3", "truncated"},
+		{"7 / 2", "3.5", "float64", "3.5", ""},
+		{"7 / 2", "3.5", "big.Rat", "7/2", ""},
+		{"7 % 3", "1", "int", "1", ""},
+		{"2.5 * 2", "5", "int", "4", "2.5 is truncated to 2"},
+		// 0.1 + 0.2
+		{"0.1 + 0.2", "0.3", "float64", "0.30000000000000004", "rounded: the exact result is 0.3"},
+		{"0.1 + 0.2", "0.3", "float32", "0.3", "rounded: the exact result is 0.3"},
+		{"0.1 + 0.2", "0.3", "big.Float", "0.3", "rounded: the exact result is 0.3"},
+		{"0.1 + 0.2", "0.3", "float64", "0.30000000000000004", "0.1 is not exact in float64"},
+		{"0.1 + 0.2", "0.3", "big.Rat", "3/10", ""},
+		{"0.5 + 0.25", "0.75", "float32", "0.75", ""},
+		// shifts
+		{"1 << 7", "128", "int8", "-128", "wrapped around"},
+		{"1 << 70", "1180591620717411303424", "int64", "0", "wrapped around"},
+		{"1 << 70", "1180591620717411303424", "big.Int", "1180591620717411303424", ""},
+		{"-8 >> 1", "-4", "int8", "-4", ""},
+		// floats overflow to infinity
+		{"1e38 * 10", "1000000000000000000000000000000000000000", "float32", "+Inf", "overflowed"},
+		{"1e38 * 10", "1000000000000000000000000000000000000000", "float64", "1e+39", "rounded"},
+	} {
+		ev := eval(t, tt.expr)
+		if ev.Exact != tt.exact {
+			t.Errorf("Eval(%q).Exact = %q, want %q", tt.expr, ev.Exact, tt.exact)
+		}
+		r := result(ev, tt.typ)
+		if got := r.Format("%v"); got != tt.value {
+			t.Errorf("%s in %s = %q, want %q (%+v)", tt.expr, tt.typ, got, tt.value, r)
+		}
+		if tt.note != "" && !hasNote(r, tt.note) {
+			t.Errorf("%s in %s has notes %q, want one starting with %q", tt.expr, tt.typ, r.Notes, tt.note)
+		}
+		if tt.note == "" && strings.Join(r.Notes, "") != "" && tt.typ != "big.Rat" {
+			t.Errorf("%s in %s has notes %q, want none", tt.expr, tt.typ, r.Notes)
+		}
+	}
+}
+
+func TestEvalDivisionByZero(t *testing.T) {
+	ev := eval(t, "1 / 0")
+	if ev.Exact != "" {
+		t.Errorf("Exact = %q, want none", ev.Exact)
+	}
+	for _, tt := range []struct {
+		typ, value, panic, note string
+	}{
+		{"int8", "", "runtime error: integer divide by zero", ""},
+		{"uint", "", "runtime error: integer divide by zero", ""},
+		{"big.Int", "", "division by zero", ""},
+		{"big.Rat", "", "division by zero", ""},
+		{"float32", "+Inf", "", "1 / 0 is +Inf, floats do not panic"},
+		{"float64", "+Inf", "", "1 / 0 is +Inf, floats do not panic"},
+		{"big.Float", "+Inf", "", "1 / 0 is +Inf"},
+	} {
+		r := result(ev, tt.typ)
+		if r.Format("%v") != tt.value || r.Panic != tt.panic || (tt.note != "" && !hasNote(r, tt.note)) {
+			t.Errorf("1 / 0 in %s = %+v, want %q, panic %q, note %q", tt.typ, r, tt.value, tt.panic, tt.note)
+		}
+	}
+	if r := result(eval(t, "-1.0 / 0"), "float64"); r.Format("%v") != "-Inf" {
+		t.Errorf("-1.0 / 0 in float64 = %q, want -Inf", r.Format("%v"))
+	}
+}
+
+func TestEvalShiftOverflow(t *testing.T) {
+	ev := eval(t, "1 << 100000")
+	for _, tt := range []struct {
+		typ, value, panic, err string
+	}{
+		// the shift count itself wraps around to a negative number
+		{"int8", "", "runtime error: negative shift amount", ""},
+		{"int16", "", "runtime error: negative shift amount", ""},
+		{"int64", "0", "", ""},
+		{"uint8", "0", "", ""},
+		{"big.Int", "", "", "shift count 100000 is out of range"},
+		{"float64", "", "", "operator << is not defined on float64"},
+		{"big.Rat", "", "", "big.Rat has no operator <<"},
+	} {
+		r := result(ev, tt.typ)
+		if r.Format("%v") != tt.value || r.Panic != tt.panic || r.Err != tt.err {
+			t.Errorf("1 << 100000 in %s = %+v, want %q, panic %q, error %q", tt.typ, r, tt.value, tt.panic, tt.err)
+		}
+	}
+}
+
+func TestEvalErrors(t *testing.T) {
+	for _, expr := range []string{"", "1 +", "x + 1", `"a" + "b"`, "1 & 2", "^1", "f(1)", "1 == 1"} {
+		if _, err := numbers.Eval(expr); !errors.Is(err, numbers.ErrExpr) {
+			t.Errorf("Eval(%q) = %v, want ErrExpr", expr, err)
+		}
+	}
+}
+
+func TestFormat(t *testing.T) {
+	ev := eval(t, "200")
+	for _, tt := range []struct {
+		typ  string
+		want map[string]string
+	}{
+		{"int8", map[string]string{"%v": "-56", "%g": "-", "%e": "-", "%b": "-111000"}},
+		{"uint", map[string]string{"%v": "200", "%g": "-", "%e": "-", "%b": "11001000", "%x": "c8"}},
+		{"big.Int", map[string]string{"%v": "200", "%g": "-", "%e": "-", "%b": "11001000"}},
+		{"big.Rat", map[string]string{"%v": "200/1", "%g": "-", "%e": "-", "%b": "-"}},
+		{"float64", map[string]string{"%v": "200", "%g": "200", "%e": "2.000000e+02", "%b": "7036874417766400p-45"}},
+		{"big.Float", map[string]string{"%v": "200", "%g": "200", "%e": "2.000000e+02"}},
+	} {
+		r := result(ev, tt.typ)
+		for verb, want := range tt.want {
+			if got := r.Format(verb); got != want {
+				t.Errorf("Format(%s) of 200 in %s = %q, want %q", verb, tt.typ, got, want)
+			}
+		}
+	}
+	if got := (numbers.Result{Type: "int"}).Format("%v"); got != "" {
+		t.Errorf("Format without a value = %q, want nothing", got)
+	}
+}
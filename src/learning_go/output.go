@@ -0,0 +1,51 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+)
+
+// table is command output that can be written as an aligned text table,
+// as CSV or as JSON. JSON output encodes value rather than the rows so it
+// keeps proper types.
+type table struct {
+	header []string
+	rows   [][]string
+	value  any
+}
+
+func (t *table) add(cells ...string) {
+	t.rows = append(t.rows, cells)
+}
+
+func formatFlag(fs *flag.FlagSet) *string {
+	return fs.String("format", "table", "output `format`: table, json or csv")
+}
+
+func (t *table) write(w io.Writer, format string) error {
+	switch format {
+	case "table":
+		tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(tw, strings.Join(t.header, "\t"))
+		for _, row := range t.rows {
+			fmt.Fprintln(tw, strings.Join(row, "\t"))
+		}
+		return tw.Flush()
+	case "csv":
+		cw := csv.NewWriter(w)
+		cw.Comma = ';'
+		cw.Write(t.header)
+		cw.WriteAll(t.rows)
+		return cw.Error()
+	case "json":
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(t.value)
+	}
+	return fmt.Errorf("unknown format %q, want table, json or csv", format)
+}
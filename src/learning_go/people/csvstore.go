@@ -0,0 +1,96 @@
+package people
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"strings"
+
+	"gbdmp/learningo/internal/atomicfile"
+)
+
+// CSVStore keeps people in a semicolon separated file with a
+// Name;Birthday header and birthdays written as DD.MM.YYYY.
+type CSVStore struct {
+	Path string
+}
+
+// NewCSVStore returns a store backed by the CSV file at path.
+func NewCSVStore(path string) *CSVStore {
+	return &CSVStore{Path: path}
+}
+
+// Load implements Store.
+func (s *CSVStore) Load() ([]Person, error) {
+	f, err := os.Open(s.Path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	list, err := ReadCSV(f)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", s.Path, err)
+	}
+	return list, nil
+}
+
+// Save implements Store.
+func (s *CSVStore) Save(people []Person) error {
+	return atomicfile.Write(s.Path, 0o644, func(f *os.File) error {
+		return WriteCSV(f, people)
+	})
+}
+
+// ReadCSV reads people from semicolon separated Name;Birthday records. The
+// header line is optional and birthdays may use any format ParseDate
+// accepts.
+func ReadCSV(r io.Reader) ([]Person, error) {
+	cr := csv.NewReader(r)
+	cr.Comma = ';'
+	cr.FieldsPerRecord = 2
+	cr.TrimLeadingSpace = true
+
+	var list []Person
+	for first := true; ; first = false {
+		rec, err := cr.Read()
+		if err == io.EOF {
+			return list, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		if first && strings.EqualFold(rec[0], "name") && strings.EqualFold(rec[1], "birthday") {
+			continue
+		}
+		name := strings.TrimSpace(rec[0])
+		if err := CheckName(name); err != nil {
+			line, _ := cr.FieldPos(0)
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		d, err := ParseDate(rec[1])
+		if err != nil {
+			line, _ := cr.FieldPos(1)
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		list = append(list, Person{Name: name, Birthday: d})
+	}
+}
+
+// WriteCSV writes people as semicolon separated Name;Birthday records
+// with a header line.
+func WriteCSV(w io.Writer, people []Person) error {
+	cw := csv.NewWriter(w)
+	cw.Comma = ';'
+	cw.Write([]string{"Name", "Birthday"})
+	for _, p := range people {
+		cw.Write([]string{p.Name, p.Birthday.String()})
+	}
+	cw.Flush()
+	return cw.Error()
+}
@@ -0,0 +1,103 @@
+package people
+
+import (
+	"fmt"
+	"time"
+)
+
+// Now returns the current time. It is a variable so that tools and lessons
+// can pin "today" to a fixed date.
+var Now = time.Now
+
+// Date is a calendar date without a time of day or time zone.
+type Date struct {
+	Year  int
+	Month time.Month
+	Day   int
+}
+
+// NewDate returns the date for the given year, month and day.
+func NewDate(year int, month time.Month, day int) Date {
+	return Date{Year: year, Month: month, Day: day}
+}
+
+// DateOf returns the calendar date of t in t's location.
+func DateOf(t time.Time) Date {
+	y, m, d := t.Date()
+	return Date{Year: y, Month: m, Day: d}
+}
+
+// Today returns the current date in the local time zone.
+func Today() Date {
+	return DateOf(Now())
+}
+
+// Time returns midnight UTC at the start of d.
+func (d Date) Time() time.Time {
+	return time.Date(d.Year, d.Month, d.Day, 0, 0, 0, 0, time.UTC)
+}
+
+// AddYears returns the date n years after d. 29 February moves to 1 March
+// in years that are not leap years, which is also the day AgeOn counts as
+// the birthday in those years.
+func (d Date) AddYears(n int) Date {
+	return DateOf(time.Date(d.Year+n, d.Month, d.Day, 0, 0, 0, 0, time.UTC))
+}
+
+// IsZero reports whether d is the zero date.
+func (d Date) IsZero() bool {
+	return d == Date{}
+}
+
+// Before reports whether d lies before e.
+func (d Date) Before(e Date) bool {
+	return d.Compare(e) < 0
+}
+
+// After reports whether d lies after e.
+func (d Date) After(e Date) bool {
+	return d.Compare(e) > 0
+}
+
+// Compare returns -1, 0 or +1 depending on whether d is before, equal to
+// or after e.
+func (d Date) Compare(e Date) int {
+	switch {
+	case d.Year != e.Year:
+		return cmp(d.Year, e.Year)
+	case d.Month != e.Month:
+		return cmp(int(d.Month), int(e.Month))
+	default:
+		return cmp(d.Day, e.Day)
+	}
+}
+
+// String formats d as DD.MM.YYYY, the format used throughout the lessons.
+func (d Date) String() string {
+	return fmt.Sprintf("%02d.%02d.%04d", d.Day, d.Month, d.Year)
+}
+
+// MarshalText formats d as an ISO 8601 date, YYYY-MM-DD.
+func (d Date) MarshalText() ([]byte, error) {
+	return []byte(fmt.Sprintf("%04d-%02d-%02d", d.Year, d.Month, d.Day)), nil
+}
+
+// UnmarshalText parses any date accepted by ParseDate.
+func (d *Date) UnmarshalText(text []byte) error {
+	parsed, err := ParseDate(string(text))
+	if err != nil {
+		return err
+	}
+	*d = parsed
+	return nil
+}
+
+func cmp(a, b int) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,50 @@
+package people
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+
+	"gbdmp/learningo/internal/atomicfile"
+)
+
+// JSONStore keeps people in a JSON file holding an array of
+// {"name": ..., "birthday": "YYYY-MM-DD"} objects.
+type JSONStore struct {
+	Path string
+}
+
+// NewJSONStore returns a store backed by the JSON file at path.
+func NewJSONStore(path string) *JSONStore {
+	return &JSONStore{Path: path}
+}
+
+// Load implements Store.
+func (s *JSONStore) Load() ([]Person, error) {
+	data, err := os.ReadFile(s.Path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var list []Person
+	if err := json.Unmarshal(data, &list); err != nil {
+		return nil, fmt.Errorf("%s: %w", s.Path, err)
+	}
+	return list, nil
+}
+
+// Save implements Store.
+func (s *JSONStore) Save(people []Person) error {
+	data, err := json.MarshalIndent(people, "", "  ")
+	if err != nil {
+		return err
+	}
+	return atomicfile.Write(s.Path, 0o644, func(f *os.File) error {
+		_, err := f.Write(append(data, '\n'))
+		return err
+	})
+}
@@ -0,0 +1,235 @@
+package people
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+
+	"gbdmp/learningo/internal/atomicfile"
+)
+
+// LogStore keeps people in an append-only log of JSON lines, one per
+// change. Saving appends only what changed since the last load or save
+// to the end of the log and syncs it; Compact atomically rewrites the log
+// as one entry per person. Both hold the lock of atomicfile.Lock on the
+// log while they write it.
+type LogStore struct {
+	Path string
+	// CompactAfter is the number of superseded entries after which Save
+	// compacts the log. Zero disables automatic compaction.
+	CompactAfter int
+
+	state   map[string]Person // people as of the end of the log
+	entries int               // entries in the log
+}
+
+// NewLogStore returns a store backed by the log file at path that
+// compacts itself once more than 100 entries are superseded.
+func NewLogStore(path string) *LogStore {
+	return &LogStore{Path: path, CompactAfter: 100}
+}
+
+type logEntry struct {
+	Op     string  `json:"op"` // "put" or "delete"
+	Person *Person `json:"person,omitempty"`
+	Name   string  `json:"name,omitempty"`
+}
+
+// Load implements Store. A last line without a trailing newline is the
+// remains of an append that was cut off, and is ignored.
+func (s *LogStore) Load() ([]Person, error) {
+	s.state = map[string]Person{}
+	s.entries = 0
+
+	data, err := s.read()
+	if err != nil {
+		return nil, err
+	}
+	sc := bufio.NewScanner(bytes.NewReader(data))
+	for line := 1; sc.Scan(); line++ {
+		var e logEntry
+		if err := json.Unmarshal(sc.Bytes(), &e); err != nil {
+			return nil, fmt.Errorf("%s:%d: %w", s.Path, line, err)
+		}
+		switch {
+		case e.Op == "put" && e.Person != nil:
+			s.state[e.Person.Name] = *e.Person
+		case e.Op == "delete":
+			delete(s.state, e.Name)
+		default:
+			return nil, fmt.Errorf("%s:%d: bad log entry", s.Path, line)
+		}
+		s.entries++
+	}
+	if err := sc.Err(); err != nil {
+		return nil, err
+	}
+	return s.list(), nil
+}
+
+// Save implements Store.
+func (s *LogStore) Save(people []Person) (err error) {
+	if s.state == nil {
+		if _, err := s.Load(); err != nil {
+			return err
+		}
+	}
+	next := make(map[string]Person, len(people))
+	for _, p := range people {
+		next[p.Name] = p
+	}
+
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	n := 0
+	for _, p := range s.list() {
+		if _, ok := next[p.Name]; !ok {
+			enc.Encode(logEntry{Op: "delete", Name: p.Name})
+			n++
+		}
+	}
+	for _, p := range people {
+		if old, ok := s.state[p.Name]; !ok || old != p {
+			p := p
+			enc.Encode(logEntry{Op: "put", Person: &p})
+			n++
+		}
+	}
+	if n == 0 {
+		return nil
+	}
+	unlock, err := atomicfile.Lock(s.Path)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if uerr := unlock(); err == nil {
+			err = uerr
+		}
+	}()
+
+	if s.CompactAfter > 0 && s.entries+n-len(next) > s.CompactAfter {
+		prev := s.state
+		s.state = next
+		if err := s.compact(); err != nil {
+			s.state = prev
+			return err
+		}
+		return nil
+	}
+	if err := s.append(buf.Bytes()); err != nil {
+		return err
+	}
+	s.state = next
+	s.entries += n
+	return nil
+}
+
+// append writes entries to the end of the log and syncs it. A torn last
+// line is cut off first, so the entries never end up glued to it in the
+// middle of the log.
+func (s *LogStore) append(entries []byte) error {
+	f, err := os.OpenFile(s.Path, os.O_RDWR|os.O_APPEND|os.O_CREATE, 0o644)
+	if err != nil {
+		return err
+	}
+	if err := s.dropTornLine(f); err != nil {
+		f.Close()
+		return err
+	}
+	if _, err := f.Write(entries); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// dropTornLine truncates f after its last newline if it does not end in
+// one. Only then does it read more than the last byte.
+func (s *LogStore) dropTornLine(f *os.File) error {
+	fi, err := f.Stat()
+	if err != nil || fi.Size() == 0 {
+		return err
+	}
+	last := make([]byte, 1)
+	if _, err := f.ReadAt(last, fi.Size()-1); err != nil {
+		return err
+	}
+	if last[0] == '\n' {
+		return nil
+	}
+	data, err := s.read()
+	if err != nil {
+		return err
+	}
+	return f.Truncate(int64(len(data)))
+}
+
+// Compact atomically rewrites the log with a single entry per person.
+func (s *LogStore) Compact() (err error) {
+	if s.state == nil {
+		if _, err := s.Load(); err != nil {
+			return err
+		}
+	}
+	unlock, err := atomicfile.Lock(s.Path)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if uerr := unlock(); err == nil {
+			err = uerr
+		}
+	}()
+	return s.compact()
+}
+
+// compact is Compact for a caller holding the lock.
+func (s *LogStore) compact() error {
+	list := s.list()
+	err := atomicfile.Write(s.Path, 0o644, func(f *os.File) error {
+		w := bufio.NewWriter(f)
+		enc := json.NewEncoder(w)
+		for i := range list {
+			if err := enc.Encode(logEntry{Op: "put", Person: &list[i]}); err != nil {
+				return err
+			}
+		}
+		return w.Flush()
+	})
+	if err != nil {
+		return err
+	}
+	s.entries = len(list)
+	return nil
+}
+
+// read returns the log up to its last newline, nil if there is no log.
+func (s *LogStore) read() ([]byte, error) {
+	data, err := os.ReadFile(s.Path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return data[:bytes.LastIndexByte(data, '\n')+1], nil
+}
+
+// list returns the people in s.state sorted by name.
+func (s *LogStore) list() []Person {
+	list := make([]Person, 0, len(s.state))
+	for _, p := range s.state {
+		list = append(list, p)
+	}
+	sortByName(list)
+	return list
+}
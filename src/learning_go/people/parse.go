@@ -0,0 +1,166 @@
+package people
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Locale decides how dates written with slashes are read. Dates written
+// with dots (DD.MM.YYYY) or dashes (ISO 8601, YYYY-MM-DD) are unambiguous
+// and read the same way in every locale.
+type Locale int
+
+const (
+	// LocaleAuto accepts a slash date only if just one reading of it is a
+	// valid date, e.g. 13/06/2017 or 06/13/2017, and rejects 06/07/2017.
+	LocaleAuto Locale = iota
+	// LocaleEU reads slash dates as DD/MM/YYYY.
+	LocaleEU
+	// LocaleUS reads slash dates as MM/DD/YYYY.
+	LocaleUS
+)
+
+// Field names the part of a date that failed to parse.
+type Field string
+
+const (
+	FieldLayout Field = "layout"
+	FieldDay    Field = "day"
+	FieldMonth  Field = "month"
+	FieldYear   Field = "year"
+)
+
+var (
+	// ErrSyntax reports a field that is not a number or a string that does
+	// not look like a date at all.
+	ErrSyntax = errors.New("invalid syntax")
+	// ErrRange reports a field whose value does not exist in the calendar,
+	// like month 13 or 31 February.
+	ErrRange = errors.New("out of range")
+	// ErrAmbiguous reports a slash date that reads as two different valid
+	// dates; pass LocaleEU or LocaleUS to pick one.
+	ErrAmbiguous = errors.New("ambiguous date")
+)
+
+// ParseError describes a date that could not be parsed.
+type ParseError struct {
+	Input string // the complete input
+	Field Field  // the offending field
+	Value string // the text of the offending field
+	Err   error  // ErrSyntax, ErrRange or ErrAmbiguous
+}
+
+func (e *ParseError) Error() string {
+	if e.Field == FieldLayout {
+		return fmt.Sprintf("parse date %q: %v", e.Input, e.Err)
+	}
+	return fmt.Sprintf("parse date %q: %s %q: %v", e.Input, e.Field, e.Value, e.Err)
+}
+
+func (e *ParseError) Unwrap() error {
+	return e.Err
+}
+
+// ParseDate parses s as DD.MM.YYYY, YYYY-MM-DD or an unambiguous slash date.
+func ParseDate(s string) (Date, error) {
+	return ParseDateIn(s, LocaleAuto)
+}
+
+// ParseDateIn is like ParseDate but reads slash dates according to loc.
+func ParseDateIn(s string, loc Locale) (Date, error) {
+	in := strings.TrimSpace(s)
+	var sep string
+	switch {
+	case strings.Count(in, ".") == 2:
+		sep = "."
+	case strings.Count(in, "-") == 2:
+		sep = "-"
+	case strings.Count(in, "/") == 2:
+		sep = "/"
+	default:
+		return Date{}, &ParseError{Input: s, Field: FieldLayout, Value: s, Err: ErrSyntax}
+	}
+	parts := strings.Split(in, sep)
+
+	switch sep {
+	case ".":
+		return build(s, parts[2], parts[1], parts[0])
+	case "-":
+		return build(s, parts[0], parts[1], parts[2])
+	}
+
+	switch loc {
+	case LocaleEU:
+		return build(s, parts[2], parts[1], parts[0])
+	case LocaleUS:
+		return build(s, parts[2], parts[0], parts[1])
+	}
+	eu, euErr := build(s, parts[2], parts[1], parts[0])
+	us, usErr := build(s, parts[2], parts[0], parts[1])
+	switch {
+	case euErr == nil && usErr == nil && eu != us:
+		return Date{}, &ParseError{Input: s, Field: FieldLayout, Value: s, Err: ErrAmbiguous}
+	case euErr == nil:
+		return eu, nil
+	case usErr == nil:
+		return us, nil
+	}
+	// Neither reading works; report against the American reading, which is
+	// what a slash date most often means.
+	return Date{}, usErr
+}
+
+// MustParseDate is like ParseDate but panics if s cannot be parsed. It is
+// meant for dates written into the source code.
+func MustParseDate(s string) Date {
+	d, err := ParseDate(s)
+	if err != nil {
+		panic(err)
+	}
+	return d
+}
+
+func build(input, year, month, day string) (Date, error) {
+	y, err := field(input, FieldYear, year, 4, 4, 1, 9999)
+	if err != nil {
+		return Date{}, err
+	}
+	m, err := field(input, FieldMonth, month, 1, 2, 1, 12)
+	if err != nil {
+		return Date{}, err
+	}
+	d, err := field(input, FieldDay, day, 1, 2, 1, DaysIn(time.Month(m), y))
+	if err != nil {
+		return Date{}, err
+	}
+	return NewDate(y, time.Month(m), d), nil
+}
+
+// field parses the digits s of the date field f and checks that the number
+// lies within [min, max].
+func field(input string, f Field, s string, minLen, maxLen, min, max int) (int, error) {
+	if len(s) < minLen || len(s) > maxLen || strings.Trim(s, "0123456789") != "" {
+		return 0, &ParseError{Input: input, Field: f, Value: s, Err: ErrSyntax}
+	}
+	n, err := strconv.Atoi(s)
+	if err != nil {
+		return 0, &ParseError{Input: input, Field: f, Value: s, Err: ErrSyntax}
+	}
+	if n < min || n > max {
+		return 0, &ParseError{Input: input, Field: f, Value: s, Err: ErrRange}
+	}
+	return n, nil
+}
+
+// DaysIn returns the number of days in the given month of the given year.
+func DaysIn(month time.Month, year int) int {
+	return time.Date(year, month+1, 0, 0, 0, 0, 0, time.UTC).Day()
+}
+
+// IsLeap reports whether year is a leap year.
+func IsLeap(year int) bool {
+	return DaysIn(time.February, year) == 29
+}
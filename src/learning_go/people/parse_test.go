@@ -0,0 +1,106 @@
+package people_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"gbdmp/learningo/people"
+)
+
+func TestParseDateIn(t *testing.T) {
+	tim := people.NewDate(2017, time.June, 6)
+	for _, tt := range []struct {
+		in   string
+		loc  people.Locale
+		want people.Date
+	}{
+		{"06.06.2017", people.LocaleAuto, tim},
+		{"6.6.2017", people.LocaleAuto, tim},
+		{" 2017-06-06 ", people.LocaleAuto, tim},
+		{"2017-6-6", people.LocaleAuto, tim},
+		// dots and dashes read the same in every locale
+		{"06.07.2017", people.LocaleUS, people.NewDate(2017, time.July, 6)},
+		{"2017-07-06", people.LocaleEU, people.NewDate(2017, time.July, 6)},
+		// a slash date with only one valid reading
+		{"13/06/2017", people.LocaleAuto, people.NewDate(2017, time.June, 13)},
+		{"06/13/2017", people.LocaleAuto, people.NewDate(2017, time.June, 13)},
+		// day and month the same: both readings agree
+		{"06/06/2017", people.LocaleAuto, tim},
+		// an ambiguous slash date in an explicit locale
+		{"06/07/2017", people.LocaleEU, people.NewDate(2017, time.July, 6)},
+		{"06/07/2017", people.LocaleUS, people.NewDate(2017, time.June, 7)},
+		// 29 February in leap years
+		{"29.02.2024", people.LocaleAuto, people.NewDate(2024, time.February, 29)},
+		{"2000-02-29", people.LocaleAuto, people.NewDate(2000, time.February, 29)},
+		{"02/29/2024", people.LocaleAuto, people.NewDate(2024, time.February, 29)},
+	} {
+		got, err := people.ParseDateIn(tt.in, tt.loc)
+		if err != nil || got != tt.want {
+			t.Errorf("ParseDateIn(%q, %v) = %v, %v, want %v", tt.in, tt.loc, got, err, tt.want)
+		}
+	}
+}
+
+func TestParseDateInErrors(t *testing.T) {
+	for _, tt := range []struct {
+		in    string
+		loc   people.Locale
+		field people.Field
+		err   error
+	}{
+		{"06/07/2017", people.LocaleAuto, people.FieldLayout, people.ErrAmbiguous},
+		{"01/12/2020", people.LocaleAuto, people.FieldLayout, people.ErrAmbiguous},
+		{"06 06 2017", people.LocaleAuto, people.FieldLayout, people.ErrSyntax},
+		{"06.06.2017.1", people.LocaleAuto, people.FieldLayout, people.ErrSyntax},
+		{"", people.LocaleAuto, people.FieldLayout, people.ErrSyntax},
+		{"xx.06.2017", people.LocaleAuto, people.FieldDay, people.ErrSyntax},
+		{"06.06.17", people.LocaleAuto, people.FieldYear, people.ErrSyntax},
+		{"+6.06.2017", people.LocaleAuto, people.FieldDay, people.ErrSyntax},
+		{"006.06.2017", people.LocaleAuto, people.FieldDay, people.ErrSyntax},
+		// out of range
+		{"32.01.2017", people.LocaleAuto, people.FieldDay, people.ErrRange},
+		{"00.01.2017", people.LocaleAuto, people.FieldDay, people.ErrRange},
+		{"01.13.2017", people.LocaleAuto, people.FieldMonth, people.ErrRange},
+		{"2017-00-01", people.LocaleAuto, people.FieldMonth, people.ErrRange},
+		{"31.04.2017", people.LocaleAuto, people.FieldDay, people.ErrRange},
+		{"01.01.0000", people.LocaleAuto, people.FieldYear, people.ErrRange},
+		// 29 February in years that are not leap years
+		{"29.02.2023", people.LocaleAuto, people.FieldDay, people.ErrRange},
+		{"1900-02-29", people.LocaleAuto, people.FieldDay, people.ErrRange},
+		{"02/29/2023", people.LocaleUS, people.FieldDay, people.ErrRange},
+		// neither reading of a slash date works: reported like MM/DD
+		{"13/13/2017", people.LocaleAuto, people.FieldMonth, people.ErrRange},
+		{"13/06/2017", people.LocaleUS, people.FieldMonth, people.ErrRange},
+	} {
+		_, err := people.ParseDateIn(tt.in, tt.loc)
+		var pe *people.ParseError
+		if !errors.As(err, &pe) {
+			t.Errorf("ParseDateIn(%q, %v) = %v, want a *ParseError", tt.in, tt.loc, err)
+			continue
+		}
+		if pe.Field != tt.field || !errors.Is(err, tt.err) || pe.Input != tt.in {
+			t.Errorf("ParseDateIn(%q, %v) = %#v, want %s: %v", tt.in, tt.loc, pe, tt.field, tt.err)
+		}
+	}
+}
+
+func TestAgeOnLeapDay(t *testing.T) {
+	leap := people.Person{Name: "Leap", Birthday: people.NewDate(2004, time.February, 29)}
+	for _, tt := range []struct {
+		on   people.Date
+		want int
+	}{
+		{people.NewDate(2005, time.February, 28), 0},
+		{people.NewDate(2005, time.March, 1), 1},
+		{people.NewDate(2008, time.February, 28), 3},
+		{people.NewDate(2008, time.February, 29), 4},
+	} {
+		if got := leap.AgeOn(tt.on); got != tt.want {
+			t.Errorf("AgeOn(%v) = %d, want %d", tt.on, got, tt.want)
+		}
+	}
+	if got, want := leap.Birthday.AddYears(1), people.NewDate(2005, time.March, 1); got != want {
+		t.Errorf("AddYears(1) of 29 February = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,176 @@
+// Package people keeps track of family members and their birthdays.
+//
+// Ages are never stored: they are always computed from the birth date, so
+// every lesson and tool sees the same, current values.
+package people
+
+import (
+	_ "embed"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+// Person is somebody with a name and a birth date.
+type Person struct {
+	Name     string `json:"name"`
+	Birthday Date   `json:"birthday"`
+}
+
+// AgeOn returns the age of p in completed years on the given date.
+func (p Person) AgeOn(d Date) int {
+	age := d.Year - p.Birthday.Year
+	if d.Month < p.Birthday.Month || (d.Month == p.Birthday.Month && d.Day < p.Birthday.Day) {
+		age--
+	}
+	return age
+}
+
+// Age returns the age of p today.
+func (p Person) Age() int {
+	return p.AgeOn(Today())
+}
+
+var (
+	// ErrExists is returned when adding a person whose name is already taken.
+	ErrExists = errors.New("person already exists")
+	// ErrNotFound is returned when a name is not in the registry.
+	ErrNotFound = errors.New("person not found")
+	// ErrName is returned for a name that is empty or not valid UTF-8.
+	ErrName = errors.New("invalid name")
+)
+
+// CheckName returns an error wrapping ErrName if name cannot be the name
+// of a person.
+func CheckName(name string) error {
+	switch {
+	case name == "":
+		return fmt.Errorf("%w: person has no name", ErrName)
+	case !utf8.ValidString(name):
+		return fmt.Errorf("%w: %q is not valid UTF-8", ErrName, name)
+	}
+	return nil
+}
+
+// Registry is a set of people keyed by name. A registry opened on a Store
+// saves every change to it.
+type Registry struct {
+	people map[string]Person
+	store  Store
+}
+
+// NewRegistry returns a registry holding the given people. Later entries
+// replace earlier ones with the same name.
+func NewRegistry(people ...Person) *Registry {
+	r := &Registry{people: make(map[string]Person, len(people))}
+	for _, p := range people {
+		r.people[p.Name] = p
+	}
+	return r
+}
+
+// Open returns a registry holding the people in s. Changes to the
+// registry are saved to s.
+func Open(s Store) (*Registry, error) {
+	list, err := s.Load()
+	if err != nil {
+		return nil, err
+	}
+	r := NewRegistry(list...)
+	r.store = s
+	return r, nil
+}
+
+// Add adds p to the registry.
+func (r *Registry) Add(p Person) error {
+	if err := CheckName(p.Name); err != nil {
+		return err
+	}
+	if _, ok := r.people[p.Name]; ok {
+		return fmt.Errorf("%w: %s", ErrExists, p.Name)
+	}
+	r.people[p.Name] = p
+	if err := r.save(); err != nil {
+		delete(r.people, p.Name)
+		return err
+	}
+	return nil
+}
+
+// Remove deletes the person with the given name.
+func (r *Registry) Remove(name string) error {
+	p, ok := r.people[name]
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrNotFound, name)
+	}
+	delete(r.people, name)
+	if err := r.save(); err != nil {
+		r.people[name] = p
+		return err
+	}
+	return nil
+}
+
+func (r *Registry) save() error {
+	if r.store == nil {
+		return nil
+	}
+	return r.store.Save(r.List())
+}
+
+// Lookup returns the person with the given name.
+func (r *Registry) Lookup(name string) (Person, bool) {
+	p, ok := r.people[name]
+	return p, ok
+}
+
+// Len returns the number of people in the registry.
+func (r *Registry) Len() int {
+	return len(r.people)
+}
+
+// List returns everybody in the registry sorted by name.
+func (r *Registry) List() []Person {
+	list := make([]Person, 0, len(r.people))
+	for _, p := range r.people {
+		list = append(list, p)
+	}
+	sortByName(list)
+	return list
+}
+
+func sortByName(list []Person) {
+	sort.Slice(list, func(i, j int) bool { return list[i].Name < list[j].Name })
+}
+
+// AgesOn returns the age of everybody in the registry on the given date.
+func (r *Registry) AgesOn(d Date) map[string]int {
+	ages := make(map[string]int, len(r.people))
+	for name, p := range r.people {
+		ages[name] = p.AgeOn(d)
+	}
+	return ages
+}
+
+// Ages returns the age of everybody in the registry today.
+func (r *Registry) Ages() map[string]int {
+	return r.AgesOn(Today())
+}
+
+//go:embed family.csv
+var familyCSV string
+
+// Family returns a registry with the family used throughout the lessons.
+//
+// Helena's real birthday is not known, only that she was 18 when the
+// lessons were written. Her birthday in family.csv is derived from that:
+// she turns 18 on the day the golden outputs are pinned to, 13.10.2023.
+func Family() *Registry {
+	list, err := ReadCSV(strings.NewReader(familyCSV))
+	if err != nil {
+		panic("people: bad family.csv: " + err.Error())
+	}
+	return NewRegistry(list...)
+}
@@ -0,0 +1,12 @@
+package people
+
+// Store keeps the people of a registry between runs. Backends replace
+// their files atomically, or append to them and ignore a cut-off last
+// entry, so an interrupted save never leaves a partial file behind.
+type Store interface {
+	// Load returns the people in the store. A store that does not exist
+	// yet holds nobody.
+	Load() ([]Person, error)
+	// Save replaces the content of the store with the given people.
+	Save(people []Person) error
+}
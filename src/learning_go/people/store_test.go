@@ -0,0 +1,249 @@
+package people_test
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"gbdmp/learningo/people"
+)
+
+var testPeople = []people.Person{
+	{Name: "Ada", Birthday: people.NewDate(1815, time.December, 10)},
+	{Name: "Leap", Birthday: people.NewDate(2000, time.February, 29)},
+	{Name: "Semi;colon", Birthday: people.NewDate(1999, time.January, 1)},
+}
+
+func stores(t *testing.T) map[string]people.Store {
+	dir := t.TempDir()
+	return map[string]people.Store{
+		"json": people.NewJSONStore(filepath.Join(dir, "people.json")),
+		"csv":  people.NewCSVStore(filepath.Join(dir, "people.csv")),
+		"log":  people.NewLogStore(filepath.Join(dir, "people.log")),
+	}
+}
+
+func TestStoreRoundTrip(t *testing.T) {
+	for name, s := range stores(t) {
+		t.Run(name, func(t *testing.T) {
+			list, err := s.Load()
+			if err != nil || len(list) != 0 {
+				t.Fatalf("Load of a missing store = %v, %v, want nobody", list, err)
+			}
+			r, err := people.Open(s)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, p := range testPeople {
+				if err := r.Add(p); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if err := r.Remove("Ada"); err != nil {
+				t.Fatal(err)
+			}
+			r2, err := people.Open(s)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got, want := r2.List(), testPeople[1:]; !reflect.DeepEqual(got, want) {
+				t.Errorf("reopened registry = %v, want %v", got, want)
+			}
+		})
+	}
+}
+
+func TestReadCSVFamily(t *testing.T) {
+	f, err := os.Open("family.csv")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	list, err := people.ReadCSV(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := list, people.Family().List(); !reflect.DeepEqual(got, want) {
+		t.Errorf("ReadCSV(family.csv) = %v, want %v", got, want)
+	}
+	if got, want := list[0], (people.Person{Name: "Gerd", Birthday: people.NewDate(1967, time.June, 30)}); got != want {
+		t.Errorf("first person = %v, want %v", got, want)
+	}
+
+	// a CSV store imports what ReadCSV reads and writes it back the same
+	s := people.NewCSVStore(filepath.Join(t.TempDir(), "family.csv"))
+	if err := s.Save(list); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(s.Path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	orig, err := os.ReadFile("family.csv")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(data, orig) {
+		t.Errorf("saved CSV:\n%s\nwant:\n%s", data, orig)
+	}
+}
+
+func TestReadCSVErrors(t *testing.T) {
+	for _, in := range []string{
+		"Name;Birthday\nTim;31.02.2017\n",
+		"Tim\n",
+		"Tim;06.06.2017;extra\n",
+		";06.06.2017\n",
+		"Tim\x81;06.06.2017\n",
+	} {
+		if list, err := people.ReadCSV(strings.NewReader(in)); err == nil {
+			t.Errorf("ReadCSV(%q) = %v, want an error", in, list)
+		}
+	}
+}
+
+func TestAddName(t *testing.T) {
+	r := people.NewRegistry()
+	for _, name := range []string{"", "X\x81", "\xff\xfe"} {
+		if err := r.Add(people.Person{Name: name, Birthday: people.NewDate(2000, time.January, 1)}); !errors.Is(err, people.ErrName) {
+			t.Errorf("Add(%q) = %v, want ErrName", name, err)
+		}
+	}
+	if err := r.Add(people.Person{Name: "Zoë", Birthday: people.NewDate(2000, time.January, 1)}); err != nil {
+		t.Error(err)
+	}
+}
+
+func logLines(t *testing.T, path string) []string {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.SplitAfter(string(data), "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
+
+func TestLogStoreCompact(t *testing.T) {
+	s := people.NewLogStore(filepath.Join(t.TempDir(), "people.log"))
+	s.CompactAfter = 3
+	r, err := people.Open(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := r.Add(testPeople[0]); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.Add(testPeople[1]); err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 2; i++ {
+		if err := r.Remove("Ada"); err != nil {
+			t.Fatal(err)
+		}
+		if err := r.Add(testPeople[0]); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// put, put, delete, put, delete: the second delete supersedes more
+	// than 3 entries and compacts the log to Leap, the last put follows
+	if got := logLines(t, s.Path); len(got) != 2 {
+		t.Errorf("log after automatic compaction has %d lines, want 2:\n%s", len(got), strings.Join(got, ""))
+	}
+
+	if err := r.Remove("Ada"); err != nil {
+		t.Fatal(err)
+	}
+	if got := logLines(t, s.Path); len(got) != 3 {
+		t.Fatalf("log has %d lines, want 3", len(got))
+	}
+	if err := s.Compact(); err != nil {
+		t.Fatal(err)
+	}
+	if got := logLines(t, s.Path); len(got) != 1 {
+		t.Errorf("compacted log has %d lines, want 1", len(got))
+	}
+	list, err := people.NewLogStore(s.Path).Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := testPeople[1:2]; !reflect.DeepEqual(list, want) {
+		t.Errorf("Load after Compact = %v, want %v", list, want)
+	}
+}
+
+// TestLogStoreAppend checks that Save appends to the log in place
+// instead of replacing it.
+func TestLogStoreAppend(t *testing.T) {
+	s := people.NewLogStore(filepath.Join(t.TempDir(), "people.log"))
+	if err := s.Save(testPeople[:1]); err != nil {
+		t.Fatal(err)
+	}
+	before, err := os.Stat(s.Path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Save(testPeople[:2]); err != nil {
+		t.Fatal(err)
+	}
+	after, err := os.Stat(s.Path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !os.SameFile(before, after) {
+		t.Error("Save replaced the log instead of appending to it")
+	}
+	if got := logLines(t, s.Path); len(got) != 2 {
+		t.Errorf("log has %d lines, want 2:\n%s", len(got), strings.Join(got, ""))
+	}
+}
+
+func TestLogStoreTornTail(t *testing.T) {
+	s := people.NewLogStore(filepath.Join(t.TempDir(), "people.log"))
+	if err := s.Save(testPeople[:1]); err != nil {
+		t.Fatal(err)
+	}
+	// an append that was cut off in the middle of a line
+	f, err := os.OpenFile(s.Path, os.O_WRONLY|os.O_APPEND, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := f.WriteString(`{"op":"put","person":{"na`); err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+
+	r, err := people.Open(people.NewLogStore(s.Path))
+	if err != nil {
+		t.Fatalf("Open with a torn last line: %v", err)
+	}
+	if got, want := r.List(), testPeople[:1]; !reflect.DeepEqual(got, want) {
+		t.Errorf("registry = %v, want %v", got, want)
+	}
+	if err := r.Add(testPeople[1]); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.Add(testPeople[2]); err != nil {
+		t.Fatal(err)
+	}
+	for _, line := range logLines(t, s.Path) {
+		if strings.Contains(line, `{"na{`) || !strings.HasSuffix(line, "}\n") {
+			t.Errorf("log line %q is not a whole entry", line)
+		}
+	}
+	list, err := people.NewLogStore(s.Path).Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(list, testPeople) {
+		t.Errorf("Load = %v, want %v", list, testPeople)
+	}
+}
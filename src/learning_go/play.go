@@ -0,0 +1,75 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+
+	"gbdmp/learningo/internal/modroot"
+	"gbdmp/learningo/playground"
+)
+
+// runPlay runs a snippet from a file or standard input in the playground.
+func runPlay(e *env, args []string) error {
+	fs := newFlagSet(e, "play")
+	opts := playground.DefaultOptions
+	fs.DurationVar(&opts.Timeout, "timeout", opts.Timeout, "wall-clock `limit` of the program")
+	fs.DurationVar(&opts.CPUTime, "cpu", opts.CPUTime, "CPU time `limit` of the program")
+	fs.Int64Var(&opts.Memory, "mem", opts.Memory, "memory `limit` of the program in bytes")
+	fs.IntVar(&opts.MaxOutput, "max-output", opts.MaxOutput, "stop the program after this many `bytes` of output")
+	asJSON := fs.Bool("json", false, "print the result as JSON")
+	pos, err := parseArgs(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(pos) > 1 {
+		return errUsage
+	}
+	var code []byte
+	if len(pos) == 0 || pos[0] == "-" {
+		code, err = io.ReadAll(os.Stdin)
+	} else {
+		code, err = os.ReadFile(pos[0])
+	}
+	if err != nil {
+		return err
+	}
+	opts.Module, _ = modroot.Find(".")
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	res, err := playground.Run(ctx, string(code), opts)
+	if err != nil {
+		return err
+	}
+	if *asJSON {
+		enc := json.NewEncoder(e.stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(res)
+	}
+
+	if !res.Compiled {
+		for _, ce := range res.Errors {
+			fmt.Fprintln(e.stderr, ce)
+		}
+		if len(res.Errors) == 0 {
+			fmt.Fprint(e.stderr, res.BuildOutput)
+		}
+		return errors.New("does not compile")
+	}
+	io.WriteString(e.stdout, res.Stdout)
+	io.WriteString(e.stderr, res.Stderr)
+	switch {
+	case res.TimedOut:
+		return fmt.Errorf("timed out after %v", opts.Timeout)
+	case res.Truncated:
+		return fmt.Errorf("stopped after %d bytes of output", opts.MaxOutput)
+	case res.ExitCode != 0:
+		return errors.New(res.Status)
+	}
+	return nil
+}
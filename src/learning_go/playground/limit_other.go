@@ -0,0 +1,9 @@
+//go:build !unix
+
+package playground
+
+// limit leaves argv alone: resource limits are only set on Unix systems;
+// the timeouts and the output cap still apply.
+func limit(argv []string, opts Options) []string {
+	return argv
+}
@@ -0,0 +1,21 @@
+//go:build unix
+
+package playground
+
+import (
+	"fmt"
+	"time"
+)
+
+// limit runs argv through sh, which sets the resource limits of opts and
+// then replaces itself with the program.
+func limit(argv []string, opts Options) []string {
+	script := "unset PWD OLDPWD"
+	if opts.CPUTime > 0 {
+		script += fmt.Sprintf("; ulimit -t %d", int((opts.CPUTime+time.Second-1)/time.Second))
+	}
+	if opts.Memory > 0 {
+		script += fmt.Sprintf("; ulimit -d %d", opts.Memory>>10)
+	}
+	return append([]string{"/bin/sh", "-c", script + `; exec "$0" "$@"`}, argv...)
+}
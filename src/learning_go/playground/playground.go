@@ -0,0 +1,412 @@
+// Package playground builds and runs Go snippets the way the Go playground
+// does, but locally: the snippet is turned into a main package, built in a
+// throwaway module and run in a subprocess with timeouts, a CPU and memory
+// limit, an empty environment and a cap on its output.
+package playground
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+
+	"gbdmp/learningo/internal/gotool"
+	"gbdmp/learningo/internal/modroot"
+)
+
+// Options limit a program.
+type Options struct {
+	// Module is the directory of the gbdmp/learningo module, so that
+	// programs can import its packages. It may be empty.
+	Module string
+	// BuildTimeout limits the go command.
+	BuildTimeout time.Duration
+	// Timeout limits the program's wall-clock time, CPUTime the processor
+	// time it uses.
+	Timeout time.Duration
+	CPUTime time.Duration
+	// Memory limits the program's data segment, in bytes.
+	Memory int64
+	// MaxOutput caps standard output and standard error, each; a program
+	// that writes more is stopped.
+	MaxOutput int
+}
+
+// DefaultOptions hold the limits that are used where Options leave them
+// zero.
+var DefaultOptions = Options{
+	BuildTimeout: 2 * time.Minute,
+	Timeout:      10 * time.Second,
+	CPUTime:      5 * time.Second,
+	Memory:       512 << 20,
+	MaxOutput:    1 << 20,
+}
+
+func (o Options) withDefaults() Options {
+	d := DefaultOptions
+	if o.BuildTimeout <= 0 {
+		o.BuildTimeout = d.BuildTimeout
+	}
+	if o.Timeout <= 0 {
+		o.Timeout = d.Timeout
+	}
+	if o.CPUTime <= 0 {
+		o.CPUTime = d.CPUTime
+	}
+	if o.Memory <= 0 {
+		o.Memory = d.Memory
+	}
+	if o.MaxOutput <= 0 {
+		o.MaxOutput = d.MaxOutput
+	}
+	return o
+}
+
+// Result is the outcome of building and running a program.
+type Result struct {
+	// Compiled is false if the program does not build; Errors and
+	// BuildOutput then tell why.
+	Compiled    bool           `json:"compiled"`
+	Errors      []CompileError `json:"errors,omitempty"`
+	BuildOutput string         `json:"build_output,omitempty"`
+
+	Stdout string `json:"stdout"`
+	Stderr string `json:"stderr"`
+	// ExitCode is -1 if the program was killed; Status then tells by what.
+	ExitCode  int    `json:"exit_code"`
+	Status    string `json:"status,omitempty"`
+	TimedOut  bool   `json:"timed_out,omitempty"`
+	Truncated bool   `json:"truncated,omitempty"`
+	// Duration is the wall-clock time the program ran.
+	Duration time.Duration `json:"duration_ns"`
+}
+
+// OK reports whether the program built and exited with status 0.
+func (r *Result) OK() bool {
+	return r.Compiled && r.ExitCode == 0 && !r.TimedOut && !r.Truncated
+}
+
+// CompileError is a message of the compiler.
+type CompileError struct {
+	File   string `json:"file"`
+	Line   int    `json:"line"`
+	Column int    `json:"column,omitempty"`
+	Msg    string `json:"msg"`
+}
+
+func (e CompileError) String() string {
+	if e.Column > 0 {
+		return fmt.Sprintf("%s:%d:%d: %s", e.File, e.Line, e.Column, e.Msg)
+	}
+	return fmt.Sprintf("%s:%d: %s", e.File, e.Line, e.Msg)
+}
+
+// Run wraps snippet into a main package, see Wrap, and builds and runs it.
+// The line numbers of compile errors refer to the snippet.
+func Run(ctx context.Context, snippet string, opts Options) (*Result, error) {
+	src, line := Wrap(snippet)
+	res, err := RunFiles(ctx, map[string][]byte{"main.go": []byte(src)}, opts)
+	if err != nil {
+		return nil, err
+	}
+	for i, e := range res.Errors {
+		if e.File == "main.go" {
+			res.Errors[i].Line = line(e.Line)
+		}
+	}
+	return res, nil
+}
+
+// RunFiles builds the main package made of files and runs it.
+func RunFiles(ctx context.Context, files map[string][]byte, opts Options) (*Result, error) {
+	return build(ctx, files, opts, []string{"build"}, nil)
+}
+
+// Test builds the test binary of the package made of files and runs it with
+// args, such as -test.v.
+func Test(ctx context.Context, files map[string][]byte, opts Options, args ...string) (*Result, error) {
+	return build(ctx, files, opts, []string{"test", "-c"}, args)
+}
+
+// build builds files with the go subcommand cmd in a temporary module and
+// runs the result with args.
+func build(ctx context.Context, files map[string][]byte, opts Options, cmd, args []string) (*Result, error) {
+	opts = opts.withDefaults()
+	dir, err := os.MkdirTemp("", "learningo-play-")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(dir)
+	if err := WriteModule(dir, opts.Module, files); err != nil {
+		return nil, err
+	}
+
+	exe := filepath.Join(dir, "prog")
+	if runtime.GOOS == "windows" {
+		exe += ".exe"
+	}
+	buildCtx, cancel := context.WithTimeout(ctx, opts.BuildTimeout)
+	defer cancel()
+	goCmd := exec.CommandContext(buildCtx, gotool.Path(), append(cmd, "-o", exe, ".")...)
+	goCmd.Dir = dir
+	goCmd.Env = append(os.Environ(), "GOWORK=off", "GOFLAGS=-mod=mod", "GO111MODULE=on")
+	out, err := goCmd.CombinedOutput()
+	if ctx.Err() != nil {
+		return nil, ctx.Err()
+	}
+	res := &Result{}
+	if err != nil {
+		var exitErr *exec.ExitError
+		if buildCtx.Err() != nil {
+			return nil, fmt.Errorf("building took longer than %v", opts.BuildTimeout)
+		}
+		if !errors.As(err, &exitErr) {
+			return nil, err
+		}
+		res.BuildOutput = strings.ReplaceAll(string(out), dir+string(filepath.Separator), "")
+		res.Errors = parseErrors(res.BuildOutput)
+		return res, nil
+	}
+	res.Compiled = true
+	return res, execute(ctx, res, dir, append([]string{exe}, args...), opts)
+}
+
+// execute runs argv in dir within the limits of opts.
+func execute(ctx context.Context, res *Result, dir string, argv []string, opts Options) error {
+	runCtx, cancel := context.WithTimeout(ctx, opts.Timeout)
+	defer cancel()
+	argv = limit(argv, opts)
+	cmd := exec.CommandContext(runCtx, argv[0], argv[1:]...)
+	cmd.Dir = dir
+	cmd.Env = []string{}
+	cmd.WaitDelay = time.Second
+	stdout := &capped{max: opts.MaxOutput, stop: cancel}
+	stderr := &capped{max: opts.MaxOutput, stop: cancel}
+	cmd.Stdout, cmd.Stderr = stdout, stderr
+
+	start := time.Now()
+	err := cmd.Run()
+	res.Duration = time.Since(start)
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+	res.Stdout, res.Stderr = stdout.buf.String(), stderr.buf.String()
+	res.Truncated = stdout.truncated || stderr.truncated
+	res.TimedOut = errors.Is(runCtx.Err(), context.DeadlineExceeded)
+	var exitErr *exec.ExitError
+	if err != nil && !errors.As(err, &exitErr) && cmd.ProcessState == nil {
+		return err
+	}
+	res.ExitCode = cmd.ProcessState.ExitCode()
+	if res.ExitCode != 0 {
+		res.Status = cmd.ProcessState.String()
+	}
+	return nil
+}
+
+// capped keeps the first max bytes written to it and calls stop when
+// there is more.
+type capped struct {
+	buf       bytes.Buffer
+	max       int
+	truncated bool
+	stop      func()
+}
+
+func (c *capped) Write(p []byte) (int, error) {
+	if c.truncated {
+		return len(p), nil
+	}
+	if room := c.max - c.buf.Len(); len(p) > room {
+		c.buf.Write(p[:max(room, 0)])
+		c.truncated = true
+		c.stop()
+		return len(p), nil
+	}
+	return c.buf.Write(p)
+}
+
+var errorLine = regexp.MustCompile(`^(?:\./)?([^\s:]+\.go):(\d+)(?::(\d+))?: (.*)$`)
+
+// parseErrors picks the compiler messages out of the output of the go
+// command.
+func parseErrors(out string) []CompileError {
+	var list []CompileError
+	for _, line := range strings.Split(out, "\n") {
+		m := errorLine.FindStringSubmatch(line)
+		if m == nil {
+			continue
+		}
+		e := CompileError{File: m[1], Msg: m[4]}
+		e.Line, _ = strconv.Atoi(m[2])
+		e.Column, _ = strconv.Atoi(m[3])
+		list = append(list, e)
+	}
+	return list
+}
+
+// WriteModule writes files to dir along with a go.mod that resolves
+// gbdmp/learningo to the module in module, unless that is empty.
+func WriteModule(dir, module string, files map[string][]byte) error {
+	all := map[string][]byte{}
+	for name, data := range files {
+		all[name] = data
+	}
+	gomod := "module play\n\ngo 1.21\n"
+	if module != "" {
+		abs, err := filepath.Abs(module)
+		if err != nil {
+			return err
+		}
+		gomod += fmt.Sprintf("\nrequire %s v0.0.0\n\nreplace %s => %s\n", modroot.Path, modroot.Path, abs)
+		if sum, err := os.ReadFile(filepath.Join(abs, "go.sum")); err == nil {
+			all["go.sum"] = sum
+		}
+	}
+	all["go.mod"] = []byte(gomod)
+	for name, data := range all {
+		if err := os.WriteFile(filepath.Join(dir, name), data, 0o644); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Packages are the packages a snippet may use without importing them,
+// by name.
+var Packages = map[string]string{
+	"big":        "math/big",
+	"bits":       "math/bits",
+	"bytes":      "bytes",
+	"calendar":   "gbdmp/learningo/calendar",
+	"errors":     "errors",
+	"fmt":        "fmt",
+	"maps":       "maps",
+	"math":       "math",
+	"milestones": "gbdmp/learningo/milestones",
+	"os":         "os",
+	"people":     "gbdmp/learningo/people",
+	"rand":       "math/rand",
+	"reflect":    "reflect",
+	"slices":     "slices",
+	"sort":       "sort",
+	"strconv":    "strconv",
+	"strings":    "strings",
+	"time":       "time",
+	"unicode":    "unicode",
+	"utf8":       "unicode/utf8",
+}
+
+// Wrap turns snippet into the source of a main package and returns it with
+// a function that maps its line numbers back to the snippet's.
+//
+// A snippet with a package clause is left alone. Otherwise it gets one,
+// and unless it declares func main, everything after its imports becomes
+// the body of main. Packages from Packages that the snippet uses without
+// importing them are imported.
+func Wrap(snippet string) (string, func(int) int) {
+	same := func(n int) int { return n }
+	fset := token.NewFileSet()
+	if _, err := parser.ParseFile(fset, "", snippet, parser.PackageClauseOnly); err == nil {
+		return snippet, same
+	}
+
+	// The package clause and the imports go on the first line, so that the
+	// snippet keeps its line numbers as far as possible.
+	src, line := "package main\n"+snippet, func(n int) int { return n - 1 }
+	f, err := parser.ParseFile(fset, "", src, 0)
+	if err != nil || !declaresMain(f) {
+		k := importsEnd(snippet)
+		head, body := snippet[:k], snippet[k:]
+		headLines := strings.Count(head, "\n")
+		src = "package main\n" + head + "\nfunc main() {\n" + body + "\n}\n"
+		line = func(n int) int {
+			if n-1 <= headLines {
+				return n - 1
+			}
+			return n - 3
+		}
+		f, err = parser.ParseFile(fset, "", src, 0)
+	}
+	if err != nil {
+		return src, line
+	}
+	var imports []string
+	for _, name := range missingImports(f) {
+		imports = append(imports, "import "+strconv.Quote(Packages[name]))
+	}
+	if len(imports) > 0 {
+		src = "package main; " + strings.Join(imports, "; ") + src[len("package main"):]
+	}
+	return src, line
+}
+
+func declaresMain(f *ast.File) bool {
+	for _, decl := range f.Decls {
+		if fn, ok := decl.(*ast.FuncDecl); ok && fn.Recv == nil && fn.Name.Name == "main" {
+			return true
+		}
+	}
+	return false
+}
+
+// importsEnd returns the offset in snippet after its import declarations.
+func importsEnd(snippet string) int {
+	fset := token.NewFileSet()
+	const clause = "package main\n"
+	f, err := parser.ParseFile(fset, "", clause+snippet, parser.ImportsOnly)
+	if err != nil || len(f.Imports) == 0 {
+		return 0
+	}
+	last := f.Decls[len(f.Decls)-1]
+	if gen, ok := last.(*ast.GenDecl); !ok || gen.Tok != token.IMPORT {
+		return 0
+	}
+	end := fset.Position(last.End()).Offset - len(clause)
+	if i := strings.IndexByte(snippet[end:], '\n'); i >= 0 {
+		return end + i + 1
+	}
+	return len(snippet)
+}
+
+// missingImports returns the names from Packages that f uses as packages
+// without declaring or importing them.
+func missingImports(f *ast.File) []string {
+	unresolved := map[string]bool{}
+	for _, id := range f.Unresolved {
+		unresolved[id.Name] = true
+	}
+	for _, spec := range f.Imports {
+		path, _ := strconv.Unquote(spec.Path.Value)
+		name := path[strings.LastIndex(path, "/")+1:]
+		if spec.Name != nil {
+			name = spec.Name.Name
+		}
+		delete(unresolved, name)
+	}
+	var names []string
+	seen := map[string]bool{}
+	ast.Inspect(f, func(n ast.Node) bool {
+		sel, ok := n.(*ast.SelectorExpr)
+		if !ok {
+			return true
+		}
+		if id, ok := sel.X.(*ast.Ident); ok && unresolved[id.Name] && Packages[id.Name] != "" && !seen[id.Name] {
+			seen[id.Name] = true
+			names = append(names, id.Name)
+		}
+		return true
+	})
+	return names
+}
@@ -0,0 +1,113 @@
+package playground_test
+
+import (
+	"context"
+	"go/parser"
+	"go/token"
+	"strconv"
+	"strings"
+	"testing"
+
+	"gbdmp/learningo/playground"
+)
+
+// imports returns the import paths of src, which has to parse.
+func imports(t *testing.T, src string) string {
+	t.Helper()
+	f, err := parser.ParseFile(token.NewFileSet(), "main.go", src, parser.ImportsOnly)
+	if err != nil {
+		t.Fatalf("wrapped snippet does not parse: %v\n%s", err, src)
+	}
+	var paths []string
+	for _, spec := range f.Imports {
+		path, _ := strconv.Unquote(spec.Path.Value)
+		paths = append(paths, path)
+	}
+	return strings.Join(paths, " ")
+}
+
+func TestWrap(t *testing.T) {
+	for _, tt := range []struct {
+		name    string
+		snippet string
+		imports string
+	}{
+		{"statements", "x := 2\nfmt.Println(x) // MARK\n", "fmt"},
+		{"no imports needed", "x := 2\n_ = x // MARK\n", ""},
+		{"several packages", "s := strings.Repeat(\"a\", 3)\nfmt.Println(s, math.Pi) // MARK\n", "strings fmt math"},
+		{"own packages", "p := people.Person{Name: \"Ada\"}\nfmt.Println(p.Name) // MARK\n", "gbdmp/learningo/people fmt"},
+		{"imported", "import \"fmt\"\n\nfmt.Println(strings.ToUpper(\"a\")) // MARK\n", "strings fmt"},
+		{"imported with a name", "import str \"strings\"\n\n_ = str.ToUpper(\"a\") // MARK\n", "strings"},
+		{"grouped imports", "import (\n\t\"fmt\"\n\t\"os\"\n)\n\nfmt.Fprintln(os.Stderr) // MARK\n", "fmt os"},
+		{"shadowed", "strings := []string{\"a\"}\n_ = strings // MARK\nfmt.Println(len(strings))\n", "fmt"},
+		{"unknown package", "x := foo.Bar // MARK\n_ = x\n", ""},
+		{"func main", "func main() {\n\tfmt.Println(square(2)) // MARK\n}\n\nfunc square(x int) int { return x * x }\n", "fmt"},
+		{"func main and imports", "import \"fmt\"\n\nfunc main() {\n\tfmt.Println(time.Now()) // MARK\n}\n", "time fmt"},
+	} {
+		src, line := playground.Wrap(tt.snippet)
+		if !strings.HasPrefix(src, "package ") {
+			t.Errorf("%s: no package clause:\n%s", tt.name, src)
+			continue
+		}
+		if got := imports(t, src); got != tt.imports {
+			t.Errorf("%s: imports %q, want %q\n%s", tt.name, got, tt.imports, src)
+		}
+		// the line with the marker maps back to its line in the snippet
+		if got, want := line(lineOf(src, "MARK")), lineOf(tt.snippet, "MARK"); got != want {
+			t.Errorf("%s: marker on line %d, want %d\n%s", tt.name, got, want, src)
+		}
+	}
+
+	// a snippet that does not parse is wrapped all the same
+	snippet := "x := \nfmt.Println(x) // MARK\n"
+	src, line := playground.Wrap(snippet)
+	if got := line(lineOf(src, "MARK")); got != 2 {
+		t.Errorf("syntax error: marker on line %d, want 2\n%s", got, src)
+	}
+}
+
+// lineOf returns the number of the first line of s containing substr.
+func lineOf(s, substr string) int {
+	for i, l := range strings.Split(s, "\n") {
+		if strings.Contains(l, substr) {
+			return i + 1
+		}
+	}
+	return 0
+}
+
+func TestWrapPackageClause(t *testing.T) {
+	snippet := "package main\n\nfunc main() {}\n"
+	if src, line := playground.Wrap(snippet); src != snippet || line(3) != 3 {
+		t.Errorf("Wrap changed a snippet with a package clause:\n%s", src)
+	}
+}
+
+func TestRunCompileError(t *testing.T) {
+	if testing.Short() {
+		t.Skip("runs the go command")
+	}
+	res, err := playground.Run(context.Background(), "import \"os\"\n\nx := 1\nfmt.Println(os.Args)\n", playground.Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Compiled || len(res.Errors) != 1 {
+		t.Fatalf("Run = %+v, want one compile error", res)
+	}
+	if e := res.Errors[0]; e.File != "main.go" || e.Line != 3 || !strings.Contains(e.Msg, "x") {
+		t.Errorf("compile error %s, want one about x on line 3", e)
+	}
+}
+
+func TestRun(t *testing.T) {
+	if testing.Short() {
+		t.Skip("runs the go command")
+	}
+	res, err := playground.Run(context.Background(), "fmt.Println(strings.ToUpper(\"hello\"))\n", playground.Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !res.OK() || res.Stdout != "HELLO\n" {
+		t.Errorf("Run = %+v, want HELLO", res)
+	}
+}
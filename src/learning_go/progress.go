@@ -0,0 +1,129 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"os/user"
+	"path/filepath"
+	"time"
+
+	"gbdmp/learningo/progress"
+)
+
+func defaultLearner() string {
+	if name := os.Getenv("LEARNINGO_LEARNER"); name != "" {
+		return name
+	}
+	if u, err := user.Current(); err == nil && u.Username != "" {
+		return u.Username
+	}
+	return "learner"
+}
+
+func learnerFlag(fs *flag.FlagSet) *string {
+	return fs.String("learner", defaultLearner(), "record progress for this `learner`")
+}
+
+func progressStore() *progress.Store {
+	if p := os.Getenv("LEARNINGO_PROGRESS"); p != "" {
+		return progress.NewStore(p)
+	}
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return progress.NewStore("progress.json")
+	}
+	return progress.NewStore(filepath.Join(dir, "learningo", "progress.json"))
+}
+
+// record saves progress events. Failing to do so must not spoil the
+// lesson, so errors are only reported.
+func (e *env) record(learner string, events ...progress.Event) {
+	if err := progressStore().Record(learner, events...); err != nil {
+		fmt.Fprintf(e.stderr, "learningo: recording progress: %v\n", err)
+	}
+}
+
+func runLessonsProgress(e *env, args []string) error {
+	fs := newFlagSet(e, "lessons progress")
+	learner := learnerFlag(fs)
+	all := fs.Bool("all", false, "report on every learner")
+	format := formatFlag(fs)
+	pos, err := parseArgs(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(pos) != 0 {
+		return errUsage
+	}
+
+	store := progressStore()
+	var learners []*progress.Learner
+	if *all {
+		if learners, err = store.Load(); err != nil {
+			return err
+		}
+	} else {
+		l, err := store.Learner(*learner)
+		if err != nil {
+			return err
+		}
+		learners = []*progress.Learner{l}
+	}
+	now := time.Now()
+	reports := []*progress.Report{}
+	for _, l := range learners {
+		reports = append(reports, progress.Build(l, now))
+	}
+
+	switch *format {
+	case "json":
+		t := &table{value: reports}
+		if !*all {
+			t.value = reports[0]
+		}
+		return t.write(e.stdout, "json")
+	case "csv":
+		t := &table{header: []string{"Learner", "Topic", "Done", "Total"}}
+		for _, r := range reports {
+			for _, tr := range r.Topics {
+				t.add(r.Learner, tr.Topic, fmt.Sprint(tr.Done), fmt.Sprint(tr.Total))
+			}
+		}
+		return t.write(e.stdout, "csv")
+	case "table":
+	default:
+		return fmt.Errorf("unknown format %q, want table, json or csv", *format)
+	}
+
+	for i, r := range reports {
+		if i > 0 {
+			fmt.Fprintln(e.stdout)
+		}
+		fmt.Fprintf(e.stdout, "Learner: %s\nStreak:  %d day(s), longest %d\n\n", r.Learner, r.Streak.Current, r.Streak.Longest)
+		t := &table{header: []string{"Topic", "Lesson", "Runs", "Exercises", "Done"}}
+		for _, lr := range r.Lessons {
+			done := ""
+			if lr.Done {
+				done = "yes"
+			}
+			t.add(lr.Topic, lr.Lesson, fmt.Sprint(lr.Runs), fmt.Sprintf("%d/%d", lr.ExercisesPassed, lr.Exercises), done)
+		}
+		if err := t.write(e.stdout, "table"); err != nil {
+			return err
+		}
+		fmt.Fprintln(e.stdout)
+		for _, tr := range r.Topics {
+			fmt.Fprintf(e.stdout, "%-16s %d/%d lessons done\n", tr.Topic, tr.Done, tr.Total)
+		}
+		switch {
+		case r.Next == nil:
+			fmt.Fprintln(e.stdout, "\nAll lessons done.")
+		case r.Next.Exercise != "":
+			fmt.Fprintf(e.stdout, "\nNext: learningo lessons exercise %s (%s)\n", r.Next.Exercise, r.Next.Reason)
+		default:
+			fmt.Fprintf(e.stdout, "\nNext: learningo lessons run %s (%s)\n", r.Next.Lesson, r.Next.Reason)
+		}
+	}
+	return nil
+}
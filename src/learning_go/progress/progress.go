@@ -0,0 +1,124 @@
+// Package progress records which lessons and exercises each learner has
+// done and reports completion per topic, streaks and the next lesson.
+package progress
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"gbdmp/learningo/internal/atomicfile"
+)
+
+// Kind is the kind of an event.
+type Kind string
+
+const (
+	// Run is recorded when a lesson has been run.
+	Run Kind = "run"
+	// Exercise is recorded when an exercise has been checked.
+	Exercise Kind = "exercise"
+)
+
+// Event is something a learner did.
+type Event struct {
+	Time     time.Time `json:"time"`
+	Kind     Kind      `json:"kind"`
+	Lesson   string    `json:"lesson"`
+	Exercise string    `json:"exercise,omitempty"` // LESSON/N, for Exercise events
+	Passed   bool      `json:"passed,omitempty"`   // for Exercise events
+}
+
+// Learner is the record of one learner.
+type Learner struct {
+	Name   string  `json:"name"`
+	Events []Event `json:"events"`
+}
+
+// Store keeps the records of all learners in one JSON file.
+type Store struct {
+	Path string
+}
+
+// NewStore returns a store backed by the JSON file at path.
+func NewStore(path string) *Store {
+	return &Store{Path: path}
+}
+
+// Load returns all learners, sorted by name. A store that does not exist
+// yet has none.
+func (s *Store) Load() ([]*Learner, error) {
+	data, err := os.ReadFile(s.Path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var learners []*Learner
+	if err := json.Unmarshal(data, &learners); err != nil {
+		return nil, fmt.Errorf("%s: %w", s.Path, err)
+	}
+	sort.Slice(learners, func(i, j int) bool { return learners[i].Name < learners[j].Name })
+	return learners, nil
+}
+
+// Learner returns the record of the named learner, which is empty for
+// somebody new.
+func (s *Store) Learner(name string) (*Learner, error) {
+	learners, err := s.Load()
+	if err != nil {
+		return nil, err
+	}
+	for _, l := range learners {
+		if l.Name == name {
+			return l, nil
+		}
+	}
+	return &Learner{Name: name}, nil
+}
+
+// Record adds events to the record of the named learner. It holds a lock
+// on the store from loading to writing it, so that concurrent runs of
+// learningo do not lose each other's events.
+func (s *Store) Record(name string, events ...Event) (err error) {
+	if err := os.MkdirAll(filepath.Dir(s.Path), 0o755); err != nil {
+		return err
+	}
+	unlock, err := atomicfile.Lock(s.Path)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if uerr := unlock(); err == nil {
+			err = uerr
+		}
+	}()
+
+	learners, err := s.Load()
+	if err != nil {
+		return err
+	}
+	var l *Learner
+	for _, x := range learners {
+		if x.Name == name {
+			l = x
+		}
+	}
+	if l == nil {
+		l = &Learner{Name: name}
+		learners = append(learners, l)
+	}
+	l.Events = append(l.Events, events...)
+
+	data, err := json.MarshalIndent(learners, "", "  ")
+	if err != nil {
+		return err
+	}
+	return atomicfile.WriteFile(s.Path, append(data, '\n'), 0o644)
+}
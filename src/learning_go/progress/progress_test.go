@@ -0,0 +1,70 @@
+package progress_test
+
+import (
+	"fmt"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"gbdmp/learningo/progress"
+)
+
+func TestRecordConcurrently(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "learningo", "progress.json")
+	const n = 20
+	var wg sync.WaitGroup
+	errs := make(chan error, n)
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			// every run of learningo opens a store of its own
+			s := progress.NewStore(path)
+			errs <- s.Record(fmt.Sprint("learner", i%2), progress.Event{
+				Time:   time.Date(2023, time.October, 13, 10, i, 0, 0, time.UTC),
+				Kind:   progress.Run,
+				Lesson: "maps",
+			})
+		}(i)
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	learners, err := progress.NewStore(path).Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(learners) != 2 {
+		t.Fatalf("%d learners, want 2", len(learners))
+	}
+	for _, l := range learners {
+		if len(l.Events) != n/2 {
+			t.Errorf("%s has %d events, want %d", l.Name, len(l.Events), n/2)
+		}
+	}
+}
+
+func TestLearner(t *testing.T) {
+	s := progress.NewStore(filepath.Join(t.TempDir(), "progress.json"))
+	l, err := s.Learner("nobody")
+	if err != nil || l.Name != "nobody" || len(l.Events) != 0 {
+		t.Fatalf("Learner of an empty store = %+v, %v, want an empty record", l, err)
+	}
+	ev := progress.Event{Time: time.Date(2023, time.October, 13, 0, 0, 0, 0, time.UTC), Kind: progress.Exercise, Lesson: "loops", Exercise: "loops/1", Passed: true}
+	if err := s.Record("ada", ev); err != nil {
+		t.Fatal(err)
+	}
+	l, err = s.Learner("ada")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(l.Events) != 1 || !l.Events[0].Time.Equal(ev.Time) || l.Events[0].Exercise != "loops/1" || !l.Events[0].Passed {
+		t.Errorf("events of ada = %+v, want %+v", l.Events, ev)
+	}
+}
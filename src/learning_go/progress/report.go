@@ -0,0 +1,161 @@
+package progress
+
+import (
+	"time"
+
+	"gbdmp/learningo/exercises"
+	"gbdmp/learningo/lessons"
+)
+
+// Report summarizes the progress of a learner through the curriculum.
+type Report struct {
+	Learner string          `json:"learner"`
+	Date    string          `json:"date"` // the day the report is for, YYYY-MM-DD
+	Topics  []TopicReport   `json:"topics"`
+	Lessons []LessonReport  `json:"lessons"`
+	Streak  Streak          `json:"streak"`
+	Next    *LessonProposal `json:"next,omitempty"`
+}
+
+// TopicReport is the completion of one topic.
+type TopicReport struct {
+	Topic    string `json:"topic"`
+	Done     int    `json:"done"`
+	Total    int    `json:"total"`
+	Complete bool   `json:"complete"`
+}
+
+// LessonReport is the progress of one lesson. A lesson is done when it has
+// been run and all of its exercises have passed.
+type LessonReport struct {
+	Lesson          string     `json:"lesson"`
+	Topic           string     `json:"topic"`
+	Runs            int        `json:"runs"`
+	ExercisesPassed int        `json:"exercises_passed"`
+	Exercises       int        `json:"exercises"`
+	Done            bool       `json:"done"`
+	Last            *time.Time `json:"last,omitempty"` // the last activity, if any
+}
+
+// Streak counts consecutive days with any activity.
+type Streak struct {
+	// Current counts back from today, or from yesterday if there has been
+	// no activity today yet.
+	Current int `json:"current"`
+	Longest int `json:"longest"`
+}
+
+// LessonProposal is what the learner should do next.
+type LessonProposal struct {
+	Lesson   string `json:"lesson"`
+	Exercise string `json:"exercise,omitempty"`
+	Reason   string `json:"reason"`
+}
+
+// Build computes the report for l as of the day of now, in now's location.
+func Build(l *Learner, now time.Time) *Report {
+	rep := &Report{Learner: l.Name, Date: now.Format(time.DateOnly)}
+
+	runs := map[string]int{}
+	last := map[string]time.Time{}
+	passed := map[string]bool{}
+	for _, e := range l.Events {
+		switch e.Kind {
+		case Run:
+			runs[e.Lesson]++
+		case Exercise:
+			if e.Passed {
+				passed[e.Exercise] = true
+			}
+		}
+		if e.Time.After(last[e.Lesson]) {
+			last[e.Lesson] = e.Time
+		}
+	}
+	byLesson := map[string][]exercises.Exercise{}
+	for _, x := range exercises.All() {
+		byLesson[x.Lesson] = append(byLesson[x.Lesson], x)
+	}
+
+	topics := map[string]*TopicReport{}
+	for _, lesson := range lessons.All() {
+		lr := LessonReport{
+			Lesson:    lesson.Name,
+			Topic:     lesson.Topic,
+			Runs:      runs[lesson.Name],
+			Exercises: len(byLesson[lesson.Name]),
+		}
+		if t, ok := last[lesson.Name]; ok {
+			lr.Last = &t
+		}
+		var todo string
+		for _, x := range byLesson[lesson.Name] {
+			if passed[x.ID()] {
+				lr.ExercisesPassed++
+			} else if todo == "" {
+				todo = x.ID()
+			}
+		}
+		lr.Done = lr.Runs > 0 && lr.ExercisesPassed == lr.Exercises
+		rep.Lessons = append(rep.Lessons, lr)
+
+		t := topics[lesson.Topic]
+		if t == nil {
+			rep.Topics = append(rep.Topics, TopicReport{Topic: lesson.Topic})
+			t = &rep.Topics[len(rep.Topics)-1]
+			topics[lesson.Topic] = t
+		}
+		t.Total++
+		if lr.Done {
+			t.Done++
+		}
+
+		if rep.Next == nil && !lr.Done {
+			switch {
+			case lr.Runs == 0:
+				rep.Next = &LessonProposal{Lesson: lesson.Name, Reason: "not run yet"}
+			default:
+				rep.Next = &LessonProposal{Lesson: lesson.Name, Exercise: todo, Reason: "exercise not passed yet"}
+			}
+		}
+	}
+	for i := range rep.Topics {
+		rep.Topics[i].Complete = rep.Topics[i].Done == rep.Topics[i].Total
+	}
+	rep.Streak = streak(l.Events, now)
+	return rep
+}
+
+// streak computes the activity streaks as of the day of now.
+func streak(events []Event, now time.Time) Streak {
+	days := map[string]bool{}
+	for _, e := range events {
+		days[e.Time.In(now.Location()).Format(time.DateOnly)] = true
+	}
+	active := func(t time.Time) bool { return days[t.Format(time.DateOnly)] }
+	day := func(t time.Time, n int) time.Time { return t.AddDate(0, 0, n) }
+
+	var s Streak
+	d := now
+	if !active(d) {
+		d = day(d, -1)
+	}
+	for active(d) {
+		s.Current++
+		d = day(d, -1)
+	}
+
+	for key := range days {
+		t, _ := time.ParseInLocation(time.DateOnly, key, now.Location())
+		if active(day(t, -1)) {
+			continue // not the first day of a streak
+		}
+		n := 0
+		for active(t) {
+			n++
+			t = day(t, 1)
+		}
+		s.Longest = max(s.Longest, n)
+	}
+	return s
+}
@@ -0,0 +1,165 @@
+package progress_test
+
+import (
+	"testing"
+	"time"
+
+	"gbdmp/learningo/exercises"
+	"gbdmp/learningo/lessons"
+	"gbdmp/learningo/progress"
+)
+
+// now is the pinned time the reports are built for.
+var now = time.Date(2023, time.October, 13, 20, 0, 0, 0, time.UTC)
+
+// daysAgo returns a time on the day n days before now.
+func daysAgo(n int, hour int) time.Time {
+	return time.Date(2023, time.October, 13-n, hour, 0, 0, 0, time.UTC)
+}
+
+func runs(times ...time.Time) []progress.Event {
+	var events []progress.Event
+	for _, t := range times {
+		events = append(events, progress.Event{Time: t, Kind: progress.Run, Lesson: "maps"})
+	}
+	return events
+}
+
+func TestStreak(t *testing.T) {
+	for _, tt := range []struct {
+		name   string
+		events []progress.Event
+		want   progress.Streak
+	}{
+		{"nothing", nil, progress.Streak{}},
+		{"today", runs(daysAgo(0, 9)), progress.Streak{Current: 1, Longest: 1}},
+		{"yesterday", runs(daysAgo(1, 9)), progress.Streak{Current: 1, Longest: 1}},
+		{"two days ago", runs(daysAgo(2, 9)), progress.Streak{Current: 0, Longest: 1}},
+		{"same day", runs(daysAgo(0, 8), daysAgo(0, 19)), progress.Streak{Current: 1, Longest: 1}},
+		{"three days", runs(daysAgo(2, 9), daysAgo(1, 9), daysAgo(0, 9)), progress.Streak{Current: 3, Longest: 3}},
+		{"up to yesterday", runs(daysAgo(3, 9), daysAgo(2, 9), daysAgo(2, 23), daysAgo(1, 0)), progress.Streak{Current: 3, Longest: 3}},
+		{"missed day", runs(daysAgo(4, 9), daysAgo(3, 9), daysAgo(2, 9), daysAgo(0, 9)), progress.Streak{Current: 1, Longest: 3}},
+		{"missed today and yesterday", runs(daysAgo(3, 9), daysAgo(2, 9)), progress.Streak{Current: 0, Longest: 2}},
+		{"out of order", runs(daysAgo(0, 9), daysAgo(10, 9), daysAgo(1, 9), daysAgo(11, 9), daysAgo(12, 9)), progress.Streak{Current: 2, Longest: 3}},
+		{"across a month", runs(daysAgo(13, 9), daysAgo(12, 9), daysAgo(11, 9)), progress.Streak{Current: 0, Longest: 3}},
+	} {
+		rep := progress.Build(&progress.Learner{Name: "ada", Events: tt.events}, now)
+		if rep.Streak != tt.want {
+			t.Errorf("%s: Streak = %+v, want %+v", tt.name, rep.Streak, tt.want)
+		}
+	}
+}
+
+// TestStreakLocation checks that days are those of now's location: late
+// in the evening of the 12th in UTC is already the 13th in Berlin.
+func TestStreakLocation(t *testing.T) {
+	berlin := time.FixedZone("CEST", 2*60*60)
+	l := &progress.Learner{Events: runs(time.Date(2023, time.October, 12, 23, 0, 0, 0, time.UTC))}
+	if got := progress.Build(l, now.In(berlin)).Streak; got != (progress.Streak{Current: 1, Longest: 1}) {
+		t.Errorf("Streak in Berlin = %+v, want the event today", got)
+	}
+	if got := progress.Build(l, time.Date(2023, time.October, 14, 0, 30, 0, 0, berlin)).Streak; got.Current != 1 {
+		t.Errorf("Streak in Berlin the day after = %+v, want the event yesterday", got)
+	}
+	if got := progress.Build(l, time.Date(2023, time.October, 14, 0, 30, 0, 0, time.UTC)).Streak; got.Current != 0 {
+		t.Errorf("Streak in UTC two days after = %+v, want none", got)
+	}
+}
+
+// finish returns the events of a learner who ran every lesson and passed
+// every exercise, leaving out the lessons for which skip is true.
+func finish(skip func(lesson string) bool) []progress.Event {
+	var events []progress.Event
+	for _, l := range lessons.All() {
+		if skip(l.Name) {
+			continue
+		}
+		events = append(events, progress.Event{Time: daysAgo(1, 10), Kind: progress.Run, Lesson: l.Name})
+	}
+	for _, x := range exercises.All() {
+		if skip(x.Lesson) {
+			continue
+		}
+		events = append(events, progress.Event{Time: daysAgo(1, 11), Kind: progress.Exercise, Lesson: x.Lesson, Exercise: x.ID(), Passed: true})
+	}
+	return events
+}
+
+func TestBuildNext(t *testing.T) {
+	all := lessons.All()
+	first, last := all[0].Name, all[len(all)-1].Name
+
+	rep := progress.Build(&progress.Learner{Name: "new"}, now)
+	if rep.Next == nil || *rep.Next != (progress.LessonProposal{Lesson: first, Reason: "not run yet"}) {
+		t.Errorf("Next for a new learner = %+v, want %s", rep.Next, first)
+	}
+	if rep.Date != "2023-10-13" || rep.Learner != "new" || len(rep.Lessons) != len(all) {
+		t.Errorf("report = %+v", rep)
+	}
+
+	rep = progress.Build(&progress.Learner{Events: finish(func(l string) bool { return l == last })}, now)
+	if rep.Next == nil || *rep.Next != (progress.LessonProposal{Lesson: last, Reason: "not run yet"}) {
+		t.Errorf("Next before the last lesson = %+v, want %s", rep.Next, last)
+	}
+
+	rep = progress.Build(&progress.Learner{Events: finish(func(string) bool { return false })}, now)
+	if rep.Next != nil {
+		t.Errorf("Next after the last lesson = %+v, want none", rep.Next)
+	}
+	for _, tr := range rep.Topics {
+		if !tr.Complete || tr.Done != tr.Total {
+			t.Errorf("topic %+v is not complete", tr)
+		}
+	}
+	for _, lr := range rep.Lessons {
+		if !lr.Done || lr.Last == nil {
+			t.Errorf("lesson %+v is not done", lr)
+		}
+	}
+}
+
+func TestBuildExercise(t *testing.T) {
+	var lesson string
+	var xs []exercises.Exercise
+	for _, x := range exercises.All() {
+		if lesson == "" || x.Lesson == lesson {
+			lesson = x.Lesson
+			xs = append(xs, x)
+		}
+	}
+	if len(xs) < 2 {
+		t.Skip("no lesson with two exercises")
+	}
+	// everything before the lesson is done; the lesson was run and its
+	// first exercise failed, then passed, and the second failed
+	before := map[string]bool{}
+	for _, l := range lessons.All() {
+		if l.Name == lesson {
+			break
+		}
+		before[l.Name] = true
+	}
+	events := finish(func(l string) bool { return !before[l] })
+	events = append(events,
+		progress.Event{Time: daysAgo(0, 8), Kind: progress.Run, Lesson: lesson},
+		progress.Event{Time: daysAgo(0, 9), Kind: progress.Exercise, Lesson: lesson, Exercise: xs[0].ID()},
+		progress.Event{Time: daysAgo(0, 10), Kind: progress.Exercise, Lesson: lesson, Exercise: xs[0].ID(), Passed: true},
+		progress.Event{Time: daysAgo(0, 11), Kind: progress.Exercise, Lesson: lesson, Exercise: xs[1].ID()},
+	)
+	rep := progress.Build(&progress.Learner{Events: events}, now)
+	want := progress.LessonProposal{Lesson: lesson, Exercise: xs[1].ID(), Reason: "exercise not passed yet"}
+	if rep.Next == nil || *rep.Next != want {
+		t.Errorf("Next = %+v, want %+v", rep.Next, want)
+	}
+	for _, lr := range rep.Lessons {
+		if lr.Lesson != lesson {
+			continue
+		}
+		if lr.Runs != 1 || lr.ExercisesPassed != 1 || lr.Exercises != len(xs) || lr.Done || lr.Last == nil || !lr.Last.Equal(daysAgo(0, 11)) {
+			t.Errorf("report of %s = %+v", lesson, lr)
+		}
+	}
+	if rep.Streak != (progress.Streak{Current: 2, Longest: 2}) {
+		t.Errorf("Streak = %+v, want yesterday and today", rep.Streak)
+	}
+}
@@ -0,0 +1,102 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"gbdmp/learningo/runes"
+)
+
+// runRunes shows the bytes, runes and grapheme clusters of a string.
+func runRunes(e *env, args []string) error {
+	fs := newFlagSet(e, "runes")
+	format := formatFlag(fs)
+	escaped := fs.Bool("escaped", false, `interpret Go escapes like \xff or \u00e9 in the string`)
+	pos, err := parseArgs(fs, args)
+	if err != nil {
+		return err
+	}
+	s := strings.Join(pos, " ")
+	if len(pos) == 0 || s == "-" {
+		data, err := io.ReadAll(os.Stdin)
+		if err != nil {
+			return err
+		}
+		s = strings.TrimSuffix(string(data), "\n")
+	}
+	if *escaped {
+		if s, err = strconv.Unquote(`"` + strings.ReplaceAll(s, `"`, `\"`) + `"`); err != nil {
+			return fmt.Errorf("bad escape in string: %v", err)
+		}
+	}
+	rep := runes.Inspect(s)
+
+	runeTable := &table{header: []string{"Offset", "Bytes", "Rune", "Code", "Category", "Width", "Name"}, value: rep}
+	for _, r := range rep.Runes {
+		name, code := r.Name, r.Code()
+		if r.Invalid {
+			name, code = "invalid UTF-8, range yields U+FFFD", "-"
+		}
+		runeTable.add(strconv.Itoa(r.Offset), fmt.Sprintf("% x", r.Bytes), show(r), code, r.Category, strconv.Itoa(r.Width), name)
+	}
+	if *format != "table" {
+		return runeTable.write(e.stdout, *format)
+	}
+
+	valid := "valid UTF-8"
+	if !rep.Valid {
+		valid = "NOT valid UTF-8"
+	}
+	fmt.Fprintf(e.stdout, "%q (%s)\n\n", s, valid)
+	c := rep.Counts
+	counts := &table{header: []string{"Go", "Count", ""}}
+	counts.add("len(s)", strconv.Itoa(c.Len), "bytes")
+	counts.add("for range s", strconv.Itoa(c.Range), "iterations, one per rune or invalid byte")
+	counts.add("len([]rune(s))", strconv.Itoa(c.RuneSlice), "runes")
+	counts.add("utf8.RuneCountInString(s)", strconv.Itoa(c.RuneCount), "runes, without converting")
+	counts.add("uniseg.GraphemeClusterCount(s)", strconv.Itoa(c.Clusters), "characters as a reader counts them")
+	counts.add("runewidth.StringWidth(s)", strconv.Itoa(c.Width), "terminal columns")
+	if err := counts.write(e.stdout, *format); err != nil {
+		return err
+	}
+
+	fmt.Fprintln(e.stdout)
+	if err := runeTable.write(e.stdout, *format); err != nil {
+		return err
+	}
+
+	fmt.Fprintln(e.stdout)
+	clusters := &table{header: []string{"Offset", "Cluster", "Runes", "Width"}}
+	for _, cl := range rep.Clusters {
+		clusters.add(strconv.Itoa(cl.Offset), strconv.Quote(cl.Text), strconv.Itoa(cl.Runes), strconv.Itoa(cl.Width))
+	}
+	if err := clusters.write(e.stdout, *format); err != nil {
+		return err
+	}
+
+	fmt.Fprintln(e.stdout)
+	bytes := &table{header: []string{"Offset", "Byte", "Bits", "Kind", "Rune"}}
+	for _, b := range rep.Bytes {
+		bytes.add(strconv.Itoa(b.Offset), fmt.Sprintf("%02x", b.Value), fmt.Sprintf("%08b", b.Value), b.Kind, strconv.Itoa(b.Rune))
+	}
+	return bytes.write(e.stdout, *format)
+}
+
+// show returns a rune as it can be printed in a table: combining marks on
+// a dotted circle and invisible runes as escapes.
+func show(r runes.Rune) string {
+	switch {
+	case r.Invalid:
+		return fmt.Sprintf(`\x%02x`, r.Bytes[0])
+	case unicode.Is(unicode.Mn, r.Rune) || unicode.Is(unicode.Me, r.Rune):
+		return "◌" + string(r.Rune)
+	case !unicode.IsPrint(r.Rune):
+		q := strconv.QuoteRune(r.Rune)
+		return q[1 : len(q)-1]
+	}
+	return string(r.Rune)
+}
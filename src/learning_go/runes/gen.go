@@ -0,0 +1,121 @@
+//go:build ignore
+
+// gen writes names.gz, the character names of the Unicode Character
+// Database, from a copy of UnicodeData.txt. The names are those of Unicode
+// 14.0.0, from https://www.unicode.org/Public/14.0.0/ucd/UnicodeData.txt:
+//
+//	go run gen.go -ucd UnicodeData.txt
+//
+// Every line of names.gz is either "XXXX;NAME" or "XXXX..YYYY;PREFIX-" for
+// a range of characters whose name is the prefix followed by the code point
+// in hexadecimal, like the CJK ideographs. Hangul syllables are left out,
+// their names are derived from the jamo.
+package main
+
+import (
+	"bufio"
+	"compress/gzip"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// ranges maps the kinds of ranges in UnicodeData.txt to the prefix of the
+// names of their characters. A kind matches the ranges it starts, so that
+// "CJK Ideograph" also covers "CJK Ideograph Extension A" and so on.
+var ranges = []struct{ kind, prefix string }{
+	{"CJK Ideograph", "CJK UNIFIED IDEOGRAPH-"},
+	{"Tangut Ideograph", "TANGUT IDEOGRAPH-"},
+}
+
+func rangePrefix(kind string) (string, bool) {
+	for _, r := range ranges {
+		if strings.HasPrefix(kind, r.kind) {
+			return r.prefix, true
+		}
+	}
+	return "", false
+}
+
+type entry struct {
+	lo, hi rune
+	name   string
+	prefix bool
+}
+
+func main() {
+	ucd := flag.String("ucd", "UnicodeData.txt", "UnicodeData.txt `file`")
+	out := flag.String("o", "names.gz", "output `file`")
+	flag.Parse()
+
+	in, err := os.Open(*ucd)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer in.Close()
+
+	var list []entry
+	add := func(e entry) {
+		if n := len(list); n > 0 && e.prefix && list[n-1].prefix && list[n-1].name == e.name && list[n-1].hi+1 == e.lo {
+			list[n-1].hi = e.hi
+			return
+		}
+		list = append(list, e)
+	}
+	first := map[string]rune{}
+	sc := bufio.NewScanner(in)
+	for sc.Scan() {
+		f := strings.Split(sc.Text(), ";")
+		if len(f) < 2 {
+			continue
+		}
+		cp, err := strconv.ParseUint(f[0], 16, 32)
+		if err != nil {
+			log.Fatalf("%s: %v", *ucd, err)
+		}
+		r, name := rune(cp), f[1]
+		switch {
+		case strings.HasSuffix(name, ", First>"):
+			first[strings.TrimSuffix(name[1:], ", First>")] = r
+		case strings.HasSuffix(name, ", Last>"):
+			kind := strings.TrimSuffix(name[1:], ", Last>")
+			if prefix, ok := rangePrefix(kind); ok {
+				add(entry{first[kind], r, prefix, true})
+			}
+		case strings.HasPrefix(name, "<"), strings.HasPrefix(name, "HANGUL SYLLABLE "):
+		case strings.HasSuffix(name, fmt.Sprintf("-%04X", r)):
+			add(entry{r, r, strings.TrimSuffix(name, fmt.Sprintf("%04X", r)), true})
+		default:
+			add(entry{r, r, name, false})
+		}
+	}
+	if err := sc.Err(); err != nil {
+		log.Fatal(err)
+	}
+
+	f, err := os.Create(*out)
+	if err != nil {
+		log.Fatal(err)
+	}
+	zw, _ := gzip.NewWriterLevel(f, gzip.BestCompression)
+	w := bufio.NewWriter(zw)
+	for _, e := range list {
+		if e.prefix {
+			fmt.Fprintf(w, "%04X..%04X;%s\n", e.lo, e.hi, e.name)
+		} else {
+			fmt.Fprintf(w, "%04X;%s\n", e.lo, e.name)
+		}
+	}
+	if err := w.Flush(); err != nil {
+		log.Fatal(err)
+	}
+	if err := zw.Close(); err != nil {
+		log.Fatal(err)
+	}
+	if err := f.Close(); err != nil {
+		log.Fatal(err)
+	}
+}
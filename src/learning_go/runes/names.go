@@ -0,0 +1,145 @@
+package runes
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	_ "embed"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode"
+)
+
+// names.gz holds the names of Unicode 14.0.0.
+//
+//go:generate go run gen.go -ucd UnicodeData.txt
+//go:embed names.gz
+var namesGz []byte
+
+type nameEntry struct {
+	lo, hi rune
+	name   string // a prefix for ranges
+}
+
+var (
+	namesOnce sync.Once
+	names     []nameEntry
+)
+
+func loadNames() {
+	zr, err := gzip.NewReader(bytes.NewReader(namesGz))
+	if err != nil {
+		panic("runes: " + err.Error())
+	}
+	sc := bufio.NewScanner(zr)
+	for sc.Scan() {
+		code, name, _ := strings.Cut(sc.Text(), ";")
+		lo, hi, isRange := strings.Cut(code, "..")
+		e := nameEntry{lo: hex(lo), name: name}
+		e.hi = e.lo
+		if isRange {
+			e.hi = hex(hi)
+		}
+		names = append(names, e)
+	}
+	if err := sc.Err(); err != nil {
+		panic("runes: " + err.Error())
+	}
+}
+
+func hex(s string) rune {
+	n, err := strconv.ParseUint(s, 16, 32)
+	if err != nil {
+		panic("runes: bad code point " + s)
+	}
+	return rune(n)
+}
+
+// controls are the Unicode 1.0 names of the C0 controls, which have no
+// name of their own.
+var controls = [...]string{
+	"NULL", "START OF HEADING", "START OF TEXT", "END OF TEXT",
+	"END OF TRANSMISSION", "ENQUIRY", "ACKNOWLEDGE", "BELL",
+	"BACKSPACE", "CHARACTER TABULATION", "LINE FEED (LF)", "LINE TABULATION",
+	"FORM FEED (FF)", "CARRIAGE RETURN (CR)", "SHIFT OUT", "SHIFT IN",
+	"DATA LINK ESCAPE", "DEVICE CONTROL ONE", "DEVICE CONTROL TWO", "DEVICE CONTROL THREE",
+	"DEVICE CONTROL FOUR", "NEGATIVE ACKNOWLEDGE", "SYNCHRONOUS IDLE", "END OF TRANSMISSION BLOCK",
+	"CANCEL", "END OF MEDIUM", "SUBSTITUTE", "ESCAPE",
+	"INFORMATION SEPARATOR FOUR", "INFORMATION SEPARATOR THREE", "INFORMATION SEPARATOR TWO", "INFORMATION SEPARATOR ONE",
+}
+
+// Hangul syllables are named after their jamo.
+const (
+	hangulBase = 0xAC00
+	hangulLast = 0xD7A3
+)
+
+var (
+	leadJamo  = strings.Split("G GG N D DD R M B BB S SS  J JJ C K T P H", " ")
+	vowelJamo = strings.Split("A AE YA YAE EO E YEO YE O WA WAE OE YO U WEO WE WI YU EU YI I", " ")
+	trailJamo = strings.Split(" G GG GS N NJ NH D L LG LM LB LS LT LP LH M B BS S SS NG J C K T P H", " ")
+)
+
+// Name returns the Unicode name of r, e.g. "LATIN SMALL LETTER E WITH
+// ACUTE", the Unicode 1.0 name of a C0 control in angle brackets, or "" if
+// r has none.
+func Name(r rune) string {
+	switch {
+	case r >= 0 && int(r) < len(controls):
+		return "<" + controls[r] + ">"
+	case r == 0x7F:
+		return "<DELETE>"
+	case r >= hangulBase && r <= hangulLast:
+		s := int(r - hangulBase)
+		return "HANGUL SYLLABLE " + leadJamo[s/(21*28)] + vowelJamo[s%(21*28)/28] + trailJamo[s%28]
+	}
+	namesOnce.Do(loadNames)
+	i := sort.Search(len(names), func(i int) bool { return names[i].hi >= r })
+	if i == len(names) || names[i].lo > r {
+		return ""
+	}
+	if e := names[i]; e.lo != e.hi {
+		return fmt.Sprintf("%s%04X", e.name, r)
+	}
+	return names[i].name
+}
+
+// categories are the long names of the general categories.
+var categories = map[string]string{
+	"Lu": "uppercase letter", "Ll": "lowercase letter", "Lt": "titlecase letter",
+	"Lm": "modifier letter", "Lo": "other letter",
+	"Mn": "nonspacing mark", "Mc": "spacing mark", "Me": "enclosing mark",
+	"Nd": "decimal number", "Nl": "letter number", "No": "other number",
+	"Pc": "connector punctuation", "Pd": "dash punctuation", "Ps": "open punctuation",
+	"Pe": "close punctuation", "Pi": "initial punctuation", "Pf": "final punctuation",
+	"Po": "other punctuation",
+	"Sm": "math symbol", "Sc": "currency symbol", "Sk": "modifier symbol", "So": "other symbol",
+	"Zs": "space separator", "Zl": "line separator", "Zp": "paragraph separator",
+	"Cc": "control", "Cf": "format", "Cs": "surrogate", "Co": "private use",
+	"Cn": "unassigned",
+}
+
+var categoryNames = func() []string {
+	var list []string
+	for name := range categories {
+		if unicode.Categories[name] != nil {
+			list = append(list, name)
+		}
+	}
+	sort.Strings(list)
+	return list
+}()
+
+// Category returns the general category of r, e.g. "Ll", and its long
+// name, e.g. "lowercase letter". Runes in no category are "Cn".
+func Category(r rune) (string, string) {
+	for _, name := range categoryNames {
+		if unicode.Is(unicode.Categories[name], r) {
+			return name, categories[name]
+		}
+	}
+	return "Cn", categories["Cn"]
+}
@@ -0,0 +1,157 @@
+package runes_test
+
+import (
+	"bufio"
+	"compress/gzip"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	"gbdmp/learningo/internal/gotool"
+	"gbdmp/learningo/runes"
+)
+
+func TestName(t *testing.T) {
+	for _, tt := range []struct {
+		r    rune
+		want string
+	}{
+		{0, "<NULL>"},
+		{'\n', "<LINE FEED (LF)>"},
+		{0x7F, "<DELETE>"},
+		{' ', "SPACE"},
+		{'é', "LATIN SMALL LETTER E WITH ACUTE"},
+		{0x301, "COMBINING ACUTE ACCENT"},
+		{'�', "REPLACEMENT CHARACTER"},
+		{'👍', "THUMBS UP SIGN"},
+		{0x1F3FD, "EMOJI MODIFIER FITZPATRICK TYPE-4"},
+		{'世', "CJK UNIFIED IDEOGRAPH-4E16"},
+		{0x3400, "CJK UNIFIED IDEOGRAPH-3400"},
+		{0x2A6DF, "CJK UNIFIED IDEOGRAPH-2A6DF"},
+		{0xF900, "CJK COMPATIBILITY IDEOGRAPH-F900"},
+		{0x17000, "TANGUT IDEOGRAPH-17000"},
+		{0x18D08, "TANGUT IDEOGRAPH-18D08"},
+		{0x18800, "TANGUT COMPONENT-001"},
+		{0xAC00, "HANGUL SYLLABLE GA"},
+		{0xAC01, "HANGUL SYLLABLE GAG"},
+		{0xD7A3, "HANGUL SYLLABLE HIH"},
+		{0x80, ""},     // a C1 control
+		{0xE000, ""},   // private use
+		{0x378, ""},    // unassigned
+		{0x10FFFF, ""}, // the last code point
+	} {
+		if got := runes.Name(tt.r); got != tt.want {
+			t.Errorf("Name(%U) = %q, want %q", tt.r, got, tt.want)
+		}
+	}
+}
+
+type nameLine struct {
+	lo, hi rune
+	name   string
+}
+
+// readNames reads a names.gz file in the format gen.go writes.
+func readNames(t *testing.T, file string) []nameLine {
+	t.Helper()
+	f, err := os.Open(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	zr, err := gzip.NewReader(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var list []nameLine
+	sc := bufio.NewScanner(zr)
+	for n := 1; sc.Scan(); n++ {
+		code, name, ok := strings.Cut(sc.Text(), ";")
+		lo, hi, isRange := strings.Cut(code, "..")
+		if !isRange {
+			hi = lo
+		}
+		l, err1 := strconv.ParseUint(lo, 16, 32)
+		h, err2 := strconv.ParseUint(hi, 16, 32)
+		if !ok || name == "" || err1 != nil || err2 != nil || l > h || isRange != strings.HasSuffix(name, "-") {
+			t.Fatalf("%s:%d: malformed line %q", file, n, sc.Text())
+		}
+		list = append(list, nameLine{rune(l), rune(h), name})
+	}
+	if err := sc.Err(); err != nil {
+		t.Fatal(err)
+	}
+	return list
+}
+
+// TestNamesFile checks that names.gz is sorted, as Name needs, and leaves
+// out what Name derives itself.
+func TestNamesFile(t *testing.T) {
+	list := readNames(t, "names.gz")
+	for i, l := range list {
+		if i > 0 && l.lo <= list[i-1].hi {
+			t.Fatalf("%04X;%s comes after %04X..%04X", l.lo, l.name, list[i-1].lo, list[i-1].hi)
+		}
+		if l.lo < 0x80 && l.lo != ' ' && (l.lo < 0x21 || l.lo > 0x7E) || strings.HasPrefix(l.name, "HANGUL SYLLABLE ") {
+			t.Errorf("names.gz has %04X;%s", l.lo, l.name)
+		}
+	}
+}
+
+// TestGen runs gen.go over an excerpt of UnicodeData.txt and checks that
+// Name agrees with every name it writes. names.gz is regenerated with
+// go generate from the full UnicodeData.txt of Unicode 14.0.0; see gen.go.
+func TestGen(t *testing.T) {
+	if testing.Short() {
+		t.Skip("runs the go command")
+	}
+	out := filepath.Join(t.TempDir(), "names.gz")
+	cmd := exec.Command(gotool.Path(), "run", "gen.go", "-ucd", filepath.Join("testdata", "UnicodeData.txt"), "-o", out)
+	if output, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("go run gen.go: %v\n%s", err, output)
+	}
+	list := readNames(t, out)
+	var got []string
+	for _, l := range list {
+		if !strings.HasSuffix(l.name, "-") {
+			got = append(got, fmt.Sprintf("%04X;%s", l.lo, l.name))
+		} else {
+			got = append(got, fmt.Sprintf("%04X..%04X;%s", l.lo, l.hi, l.name))
+		}
+	}
+	want := []string{
+		"0020;SPACE",
+		"0041;LATIN CAPITAL LETTER A",
+		"00E9;LATIN SMALL LETTER E WITH ACUTE",
+		"0301;COMBINING ACUTE ACCENT",
+		"3400..4DBF;CJK UNIFIED IDEOGRAPH-",
+		"4E00..9FFF;CJK UNIFIED IDEOGRAPH-",
+		"F900..F901;CJK COMPATIBILITY IDEOGRAPH-",
+		"FFFD;REPLACEMENT CHARACTER",
+		"17000..187F7;TANGUT IDEOGRAPH-",
+		"18800;TANGUT COMPONENT-001",
+		"18B00..18B00;KHITAN SMALL SCRIPT CHARACTER-",
+		"18D00..18D08;TANGUT IDEOGRAPH-",
+		"1F3FD;EMOJI MODIFIER FITZPATRICK TYPE-4",
+		"1F44D;THUMBS UP SIGN",
+		"20000..2A6DF;CJK UNIFIED IDEOGRAPH-",
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("gen.go wrote\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+	for _, l := range list {
+		for _, r := range []rune{l.lo, l.hi} {
+			want := l.name
+			if strings.HasSuffix(want, "-") {
+				want += fmt.Sprintf("%04X", r)
+			}
+			if got := runes.Name(r); got != want {
+				t.Errorf("Name(%U) = %q, gen.go writes %q", r, got, want)
+			}
+		}
+	}
+}
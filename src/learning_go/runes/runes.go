@@ -0,0 +1,112 @@
+// Package runes takes a string apart the three ways Go code sees it: as
+// bytes, which is what len and indexing count; as runes, the Unicode code
+// points range and []rune decode; and as grapheme clusters, the
+// characters a reader sees, which can be several runes each.
+package runes
+
+import (
+	"fmt"
+	"unicode/utf8"
+
+	"github.com/mattn/go-runewidth"
+	"github.com/rivo/uniseg"
+)
+
+// Byte is a byte of a string.
+type Byte struct {
+	Offset int    `json:"offset"`
+	Value  byte   `json:"value"`
+	Kind   string `json:"kind"` // ascii, lead, continuation or invalid
+	Rune   int    `json:"rune"` // index of the rune the byte belongs to
+}
+
+// Rune is a rune of a string as range decodes it.
+type Rune struct {
+	Offset   int    `json:"offset"`
+	Bytes    []byte `json:"bytes"`
+	Rune     rune   `json:"rune"`
+	Name     string `json:"name,omitempty"`
+	Category string `json:"category"`
+	Width    int    `json:"width"`
+	// Invalid is set for a byte that does not start a valid UTF-8
+	// sequence, which range decodes as U+FFFD.
+	Invalid bool `json:"invalid,omitempty"`
+}
+
+// Code returns the code point of r as U+XXXX.
+func (r Rune) Code() string { return fmt.Sprintf("%U", r.Rune) }
+
+// Cluster is a grapheme cluster, a user-perceived character.
+type Cluster struct {
+	Offset int    `json:"offset"`
+	Text   string `json:"text"`
+	Runes  int    `json:"runes"`
+	Width  int    `json:"width"`
+}
+
+// Counts are the different lengths of a string.
+type Counts struct {
+	Len       int `json:"len"`       // len(s), the bytes
+	Range     int `json:"range"`     // iterations of for range s
+	RuneSlice int `json:"runeSlice"` // len([]rune(s))
+	RuneCount int `json:"runeCount"` // utf8.RuneCountInString(s)
+	Clusters  int `json:"clusters"`  // grapheme clusters
+	Width     int `json:"width"`     // columns in a terminal
+}
+
+// Report is a string taken apart.
+type Report struct {
+	Text     string    `json:"text"`
+	Valid    bool      `json:"valid"` // utf8.ValidString(s)
+	Counts   Counts    `json:"counts"`
+	Bytes    []Byte    `json:"bytes"`
+	Runes    []Rune    `json:"runes"`
+	Clusters []Cluster `json:"clusters"`
+}
+
+// Inspect takes s apart.
+func Inspect(s string) *Report {
+	rep := &Report{Text: s, Valid: utf8.ValidString(s), Bytes: []Byte{}, Runes: []Rune{}, Clusters: []Cluster{}}
+	for i, r := range s {
+		_, size := utf8.DecodeRuneInString(s[i:])
+		cat, _ := Category(r)
+		ru := Rune{Offset: i, Bytes: []byte(s[i : i+size]), Rune: r, Name: Name(r), Category: cat, Width: runewidth.RuneWidth(r)}
+		if r == utf8.RuneError && size == 1 {
+			ru.Invalid, ru.Name, ru.Category = true, "", ""
+		}
+		for j := 0; j < size; j++ {
+			b := Byte{Offset: i + j, Value: s[i+j], Kind: "continuation", Rune: len(rep.Runes)}
+			switch {
+			case ru.Invalid:
+				b.Kind = "invalid"
+			case size == 1:
+				b.Kind = "ascii"
+			case j == 0:
+				b.Kind = "lead"
+			}
+			rep.Bytes = append(rep.Bytes, b)
+		}
+		rep.Runes = append(rep.Runes, ru)
+		rep.Counts.Range++
+	}
+
+	g := uniseg.NewGraphemes(s)
+	for g.Next() {
+		// The text is cut from s: g.Str would turn invalid bytes into
+		// U+FFFD.
+		from, to := g.Positions()
+		rep.Clusters = append(rep.Clusters, Cluster{
+			Offset: from,
+			Text:   s[from:to],
+			Runes:  len(g.Runes()),
+			Width:  runewidth.StringWidth(s[from:to]),
+		})
+	}
+
+	rep.Counts.Len = len(s)
+	rep.Counts.RuneSlice = len([]rune(s))
+	rep.Counts.RuneCount = utf8.RuneCountInString(s)
+	rep.Counts.Clusters = len(rep.Clusters)
+	rep.Counts.Width = runewidth.StringWidth(s)
+	return rep
+}
@@ -0,0 +1,102 @@
+package runes_test
+
+import (
+	"reflect"
+	"testing"
+
+	"gbdmp/learningo/runes"
+)
+
+func TestInspect(t *testing.T) {
+	// e and a combining acute accent, a byte that is not UTF-8 and a thumb
+	// with a skin tone
+	rep := runes.Inspect("é\xff👍🏽")
+	if rep.Valid {
+		t.Error("Valid = true")
+	}
+	if want := (runes.Counts{Len: 12, Range: 5, RuneSlice: 5, RuneCount: 5, Clusters: 3, Width: 4}); rep.Counts != want {
+		t.Errorf("Counts = %+v, want %+v", rep.Counts, want)
+	}
+
+	var kinds []string
+	var owners []int
+	for i, b := range rep.Bytes {
+		if b.Offset != i || b.Value != rep.Text[i] {
+			t.Errorf("byte %d = %+v", i, b)
+		}
+		kinds = append(kinds, b.Kind)
+		owners = append(owners, b.Rune)
+	}
+	if want := []string{"ascii", "lead", "continuation", "invalid", "lead", "continuation", "continuation", "continuation", "lead", "continuation", "continuation", "continuation"}; !reflect.DeepEqual(kinds, want) {
+		t.Errorf("byte kinds %q, want %q", kinds, want)
+	}
+	if want := []int{0, 1, 1, 2, 3, 3, 3, 3, 4, 4, 4, 4}; !reflect.DeepEqual(owners, want) {
+		t.Errorf("bytes belong to runes %v, want %v", owners, want)
+	}
+
+	want := []runes.Rune{
+		{Offset: 0, Bytes: []byte("e"), Rune: 'e', Name: "LATIN SMALL LETTER E", Category: "Ll", Width: 1},
+		{Offset: 1, Bytes: []byte{0xCC, 0x81}, Rune: 0x301, Name: "COMBINING ACUTE ACCENT", Category: "Mn", Width: 0},
+		{Offset: 3, Bytes: []byte{0xFF}, Rune: '�', Width: 1, Invalid: true},
+		{Offset: 4, Bytes: []byte("👍"), Rune: '👍', Name: "THUMBS UP SIGN", Category: "So", Width: 2},
+		{Offset: 8, Bytes: []byte("🏽"), Rune: 0x1F3FD, Name: "EMOJI MODIFIER FITZPATRICK TYPE-4", Category: "Sk", Width: 2},
+	}
+	if !reflect.DeepEqual(rep.Runes, want) {
+		t.Errorf("Runes =\n%+v\nwant\n%+v", rep.Runes, want)
+	}
+	if got := rep.Runes[1].Code(); got != "U+0301" {
+		t.Errorf("Code = %s, want U+0301", got)
+	}
+
+	wantClusters := []runes.Cluster{
+		{Offset: 0, Text: "é", Runes: 2, Width: 1},
+		{Offset: 3, Text: "\xff", Runes: 1, Width: 1},
+		{Offset: 4, Text: "👍🏽", Runes: 2, Width: 2},
+	}
+	if !reflect.DeepEqual(rep.Clusters, wantClusters) {
+		t.Errorf("Clusters = %+v, want %+v", rep.Clusters, wantClusters)
+	}
+}
+
+func TestInspectCounts(t *testing.T) {
+	for _, tt := range []struct {
+		s    string
+		want runes.Counts
+	}{
+		{"", runes.Counts{}},
+		{"go", runes.Counts{Len: 2, Range: 2, RuneSlice: 2, RuneCount: 2, Clusters: 2, Width: 2}},
+		// é as one code point
+		{"é\xff👍🏽", runes.Counts{Len: 11, Range: 4, RuneSlice: 4, RuneCount: 4, Clusters: 3, Width: 4}},
+		{"世界", runes.Counts{Len: 6, Range: 2, RuneSlice: 2, RuneCount: 2, Clusters: 2, Width: 4}},
+		// a family: four people joined by zero width joiners
+		{"👨‍👩‍👧‍👦", runes.Counts{Len: 25, Range: 7, RuneSlice: 7, RuneCount: 7, Clusters: 1, Width: 2}},
+		// a truncated sequence counts as one invalid byte per byte
+		{"\xe4\xb8", runes.Counts{Len: 2, Range: 2, RuneSlice: 2, RuneCount: 2, Clusters: 2, Width: 2}},
+	} {
+		rep := runes.Inspect(tt.s)
+		if rep.Counts != tt.want {
+			t.Errorf("Inspect(%q).Counts = %+v, want %+v", tt.s, rep.Counts, tt.want)
+		}
+		if rep.Bytes == nil || rep.Runes == nil || rep.Clusters == nil {
+			t.Errorf("Inspect(%q) has nil tables, which are null in JSON", tt.s)
+		}
+	}
+}
+
+func TestCategory(t *testing.T) {
+	for _, tt := range []struct {
+		r          rune
+		code, name string
+	}{
+		{'A', "Lu", "uppercase letter"},
+		{0x301, "Mn", "nonspacing mark"},
+		{'7', "Nd", "decimal number"},
+		{'€', "Sc", "currency symbol"},
+		{'\t', "Cc", "control"},
+		{0x378, "Cn", "unassigned"},
+	} {
+		if code, name := runes.Category(tt.r); code != tt.code || name != tt.name {
+			t.Errorf("Category(%U) = %s, %s, want %s, %s", tt.r, code, name, tt.code, tt.name)
+		}
+	}
+}
@@ -0,0 +1,86 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"os/signal"
+	"runtime"
+	"syscall"
+	"time"
+
+	"gbdmp/learningo/api"
+	"gbdmp/learningo/calendar"
+	"gbdmp/learningo/internal/modroot"
+	"gbdmp/learningo/milestones"
+	"gbdmp/learningo/playground"
+)
+
+// shutdownTimeout is how long serve waits for requests in flight after
+// SIGINT or SIGTERM.
+const shutdownTimeout = 10 * time.Second
+
+func runServe(e *env, args []string) error {
+	fs := newFlagSet(e, "serve")
+	addr := fs.String("addr", "127.0.0.1:8080", "listen on this `address`")
+	rulesFile := fs.String("rules", "", "load the milestone rules from this YAML or JSON `file`")
+	leap := leapFlag(fs)
+	play := fs.Bool("play", false, "run Go snippets posted to /play, see \"learningo play\"")
+	pos, err := parseArgs(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(pos) != 0 {
+		return errUsage
+	}
+	policy, err := calendar.ParseLeapPolicy(*leap)
+	if err != nil {
+		return err
+	}
+	rules := milestones.Default()
+	if *rulesFile != "" {
+		if rules, err = milestones.Load(*rulesFile); err != nil {
+			return err
+		}
+	}
+	r, err := e.openRegistry()
+	if err != nil {
+		return err
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	handler := api.NewServer(r, rules, policy)
+	if *play {
+		opts := playground.DefaultOptions
+		opts.Module, _ = modroot.Find(".")
+		handler.EnablePlayground(opts, runtime.NumCPU())
+	}
+	srv := &http.Server{
+		Addr:              *addr,
+		Handler:           handler,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	errc := make(chan error, 1)
+	go func() { errc <- srv.ListenAndServe() }()
+	fmt.Fprintf(e.stderr, "learningo: serving %s on http://%s\n", e.storePath, *addr)
+
+	select {
+	case err := <-errc:
+		return err
+	case <-ctx.Done():
+	}
+	fmt.Fprintln(e.stderr, "learningo: shutting down")
+	sctx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+	if err := srv.Shutdown(sctx); err != nil {
+		return err
+	}
+	if err := <-errc; !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
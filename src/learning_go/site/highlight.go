@@ -0,0 +1,74 @@
+package site
+
+import (
+	"go/scanner"
+	"go/token"
+	"html"
+	"html/template"
+	"strings"
+)
+
+// predeclared are the identifiers of the universe block.
+var predeclared = map[string]bool{}
+
+func init() {
+	for _, name := range strings.Fields(`
+		any bool byte comparable complex64 complex128 error float32 float64
+		int int8 int16 int32 int64 rune string uint uint8 uint16 uint32 uint64 uintptr
+		true false iota nil
+		append cap clear close complex copy delete imag len make max min new panic print println real recover`) {
+		predeclared[name] = true
+	}
+}
+
+// Highlight marks up Go source as HTML, with a span of class kw, str, num,
+// com or bi around keywords, literals, comments and predeclared names.
+// The source does not need to be a complete file.
+func Highlight(src string) template.HTML {
+	var sb strings.Builder
+	fset := token.NewFileSet()
+	file := fset.AddFile("", fset.Base(), len(src))
+	var s scanner.Scanner
+	s.Init(file, []byte(src), func(token.Position, string) {}, scanner.ScanComments)
+	last := 0
+	for {
+		pos, tok, lit := s.Scan()
+		if tok == token.EOF {
+			break
+		}
+		if tok == token.SEMICOLON && lit == "\n" {
+			continue // inserted, not in the source
+		}
+		start := file.Offset(pos)
+		end := start + len(lit)
+		if lit == "" {
+			end = start + len(tok.String())
+		}
+		if start < last || end > len(src) {
+			continue
+		}
+		sb.WriteString(html.EscapeString(src[last:start]))
+		text := html.EscapeString(src[start:end])
+		class := ""
+		switch {
+		case tok.IsKeyword():
+			class = "kw"
+		case tok == token.STRING || tok == token.CHAR:
+			class = "str"
+		case tok == token.INT || tok == token.FLOAT || tok == token.IMAG:
+			class = "num"
+		case tok == token.COMMENT:
+			class = "com"
+		case tok == token.IDENT && predeclared[lit]:
+			class = "bi"
+		}
+		if class != "" {
+			sb.WriteString(`<span class="` + class + `">` + text + `</span>`)
+		} else {
+			sb.WriteString(text)
+		}
+		last = end
+	}
+	sb.WriteString(html.EscapeString(src[last:]))
+	return template.HTML(sb.String())
+}
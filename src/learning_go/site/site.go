@@ -0,0 +1,269 @@
+// Package site renders the lessons as a static HTML site that can be
+// browsed without a network: a page per lesson with its prose, highlighted
+// source and output, prev/next links, a topic index and a search index
+// built when the site is generated.
+package site
+
+import (
+	"bytes"
+	"embed"
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/russross/blackfriday/v2"
+
+	"gbdmp/learningo/literate"
+)
+
+//go:embed templates static
+var files embed.FS
+
+var templates = template.Must(template.ParseFS(files, "templates/*.html"))
+
+// Lesson is a page of the site.
+type Lesson struct {
+	Doc  *literate.Document
+	Prev *Lesson
+	Next *Lesson
+}
+
+// Name returns the name of the lesson.
+func (l *Lesson) Name() string { return l.Doc.Meta.Name }
+
+// Title returns the title of the lesson, or its name if it has none.
+func (l *Lesson) Title() string {
+	if l.Doc.Meta.Title != "" {
+		return l.Doc.Meta.Title
+	}
+	return l.Doc.Meta.Name
+}
+
+// URL returns the page of the lesson, relative to the site.
+func (l *Lesson) URL() string { return l.Name() + ".html" }
+
+// Section is a rendered block of a lesson.
+type Section struct {
+	Kind string // prose, code or output
+	HTML template.HTML
+}
+
+// Sections renders the blocks of the lesson. The heading of the prose is
+// left out, the page has its own.
+func (l *Lesson) Sections() []Section {
+	var sections []Section
+	for _, b := range l.Doc.Blocks {
+		switch b.Kind {
+		case literate.Prose:
+			text := b.Text
+			if strings.HasPrefix(text, "# ") {
+				_, text, _ = strings.Cut(text, "\n")
+			}
+			if strings.TrimSpace(text) == "" {
+				continue
+			}
+			sections = append(sections, Section{"prose", template.HTML(blackfriday.Run([]byte(text)))})
+		case literate.Code:
+			sections = append(sections, Section{"code", Highlight(b.Text)})
+		case literate.Output:
+			sections = append(sections, Section{"output", template.HTML(template.HTMLEscapeString(b.Text))})
+		}
+	}
+	return sections
+}
+
+// Topic is a group of lessons in the index.
+type Topic struct {
+	Name    string
+	Lessons []*Lesson
+}
+
+// Site is a set of lessons in curriculum order.
+type Site struct {
+	Title   string
+	Lessons []*Lesson
+}
+
+// New orders docs by their order in the curriculum, then by name, and
+// links them.
+func New(title string, docs []*literate.Document) *Site {
+	docs = append([]*literate.Document(nil), docs...)
+	sort.SliceStable(docs, func(i, j int) bool {
+		a, b := docs[i].Meta, docs[j].Meta
+		if a.Order != b.Order {
+			return a.Order < b.Order
+		}
+		return a.Name < b.Name
+	})
+	s := &Site{Title: title}
+	for i, d := range docs {
+		l := &Lesson{Doc: d}
+		if i > 0 {
+			l.Prev = s.Lessons[i-1]
+			l.Prev.Next = l
+		}
+		s.Lessons = append(s.Lessons, l)
+	}
+	return s
+}
+
+// Topics groups the lessons by topic, in the order the topics come up.
+func (s *Site) Topics() []Topic {
+	var topics []Topic
+	index := map[string]int{}
+	for _, l := range s.Lessons {
+		name := l.Doc.Meta.Topic
+		i, ok := index[name]
+		if !ok {
+			i = len(topics)
+			index[name] = i
+			topics = append(topics, Topic{Name: name})
+		}
+		topics[i].Lessons = append(topics[i].Lessons, l)
+	}
+	return topics
+}
+
+// Lookup returns the lesson with the given name.
+func (s *Site) Lookup(name string) *Lesson {
+	for _, l := range s.Lessons {
+		if l.Name() == name {
+			return l
+		}
+	}
+	return nil
+}
+
+// SearchIndex maps the lower-case words of the lessons to the indexes of
+// the lessons they appear in.
+type SearchIndex struct {
+	Lessons []SearchEntry    `json:"lessons"`
+	Words   map[string][]int `json:"words"`
+}
+
+// SearchEntry is a lesson in the search index.
+type SearchEntry struct {
+	Title string `json:"title"`
+	Topic string `json:"topic"`
+	URL   string `json:"url"`
+}
+
+// Index builds the search index over the titles, prose and code of the
+// lessons.
+func (s *Site) Index() *SearchIndex {
+	idx := &SearchIndex{Words: map[string][]int{}}
+	for i, l := range s.Lessons {
+		idx.Lessons = append(idx.Lessons, SearchEntry{Title: l.Title(), Topic: l.Doc.Meta.Topic, URL: l.URL()})
+		var text strings.Builder
+		text.WriteString(l.Title() + " " + l.Name() + " " + l.Doc.Meta.Topic + "\n")
+		for _, b := range l.Doc.Blocks {
+			if b.Kind != literate.Output {
+				text.WriteString(b.Text)
+			}
+		}
+		seen := map[string]bool{}
+		for _, w := range words(text.String()) {
+			if !seen[w] {
+				seen[w] = true
+				idx.Words[w] = append(idx.Words[w], i)
+			}
+		}
+	}
+	return idx
+}
+
+// words splits text into lower-case words of at least two letters; an
+// identifier like people.ParseDate gives people, parsedate, parse and date.
+func words(text string) []string {
+	var list []string
+	for _, field := range strings.FieldsFunc(text, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '_'
+	}) {
+		list = append(list, strings.ToLower(field))
+		for _, part := range splitCamel(field) {
+			if part != field {
+				list = append(list, strings.ToLower(part))
+			}
+		}
+	}
+	var long []string
+	for _, w := range list {
+		if len([]rune(w)) >= 2 {
+			long = append(long, w)
+		}
+	}
+	return long
+}
+
+func splitCamel(s string) []string {
+	var parts []string
+	start := 0
+	runes := []rune(s)
+	for i := 1; i < len(runes); i++ {
+		if runes[i] == '_' || unicode.IsUpper(runes[i]) && unicode.IsLower(runes[i-1]) {
+			parts = append(parts, string(runes[start:i]))
+			start = i
+			if runes[i] == '_' {
+				start++
+			}
+		}
+	}
+	return append(parts, string(runes[start:]))
+}
+
+// Write generates the site into dir: index.html, a page per lesson, the
+// search index as a script, so that it also loads from file:// URLs, and
+// the style sheet and search code.
+func (s *Site) Write(dir string) error {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+	if err := s.render(filepath.Join(dir, "index.html"), "index.html", s); err != nil {
+		return err
+	}
+	for _, l := range s.Lessons {
+		data := struct {
+			*Site
+			Lesson *Lesson
+		}{s, l}
+		if err := s.render(filepath.Join(dir, l.URL()), "lesson.html", data); err != nil {
+			return err
+		}
+	}
+
+	idx, err := json.Marshal(s.Index())
+	if err != nil {
+		return err
+	}
+	script := fmt.Sprintf("var searchIndex = %s;\n", idx)
+	if err := os.WriteFile(filepath.Join(dir, "search-index.js"), []byte(script), 0o644); err != nil {
+		return err
+	}
+	static, err := files.ReadDir("static")
+	if err != nil {
+		return err
+	}
+	for _, f := range static {
+		data, err := files.ReadFile("static/" + f.Name())
+		if err != nil {
+			return err
+		}
+		if err := os.WriteFile(filepath.Join(dir, f.Name()), data, 0o644); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *Site) render(file, name string, data any) error {
+	var buf bytes.Buffer
+	if err := templates.ExecuteTemplate(&buf, name, data); err != nil {
+		return err
+	}
+	return os.WriteFile(file, buf.Bytes(), 0o644)
+}
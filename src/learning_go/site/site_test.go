@@ -0,0 +1,171 @@
+package site_test
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"gbdmp/learningo/literate"
+	"gbdmp/learningo/site"
+)
+
+func TestHighlight(t *testing.T) {
+	for _, tt := range []struct {
+		src, want string
+	}{
+		{"x := 1", `x := <span class="num">1</span>`},
+		{"for i := range s {}", `<span class="kw">for</span> i := <span class="kw">range</span> s {}`},
+		{`fmt.Println("<b>&")`, `fmt.Println(<span class="str">&#34;&lt;b&gt;&amp;&#34;</span>)`},
+		{"if a < b && len(s) > 0 {", `<span class="kw">if</span> a &lt; b &amp;&amp; <span class="bi">len</span>(s) &gt; <span class="num">0</span> {`},
+		{"var r rune = 'x' // a <comment>\n", `<span class="kw">var</span> r <span class="bi">rune</span> = <span class="str">&#39;x&#39;</span> <span class="com">// a &lt;comment&gt;</span>` + "\n"},
+		{"s := `<raw\n>`", `s := <span class="str">` + "`&lt;raw\n&gt;`" + `</span>`},
+		{"x := 1.5e3i", `x := <span class="num">1.5e3i</span>`},
+		// not Go, but still escaped
+		{"<script>", "&lt;script&gt;"},
+		{`s := "open`, `s := <span class="str">&#34;open</span>`},
+	} {
+		if got := string(site.Highlight(tt.src)); got != tt.want {
+			t.Errorf("Highlight(%q) =\n%s\nwant\n%s", tt.src, got, tt.want)
+		}
+	}
+}
+
+// docs returns two lessons out of curriculum order.
+func docs() []*literate.Document {
+	return []*literate.Document{
+		{Meta: literate.Meta{Name: "maps", Title: "Maps & Sets", Topic: "types", Order: 2, Prerequisites: []string{"hello"}}, Blocks: []literate.Block{
+			{Kind: literate.Prose, Text: "# Maps & Sets\n\nA map is like a dictionary.\n"},
+			{Kind: literate.Code, Text: "m := map[string]int{\"a\": 1}\nif m[\"a\"] < 2 {\n\tfmt.Println(people.ParseDate)\n}\n"},
+			{Kind: literate.Output, Text: "<nil> unindexed\n"},
+		}},
+		{Meta: literate.Meta{Name: "hello", Title: "Hello", Topic: "basics", Order: 1}, Blocks: []literate.Block{
+			{Kind: literate.Prose, Text: "# Hello\n\nSay *hello* to x.\n"},
+			{Kind: literate.Code, Text: "fmt.Println(\"hello\")\n"},
+		}},
+	}
+}
+
+func TestNew(t *testing.T) {
+	s := site.New("Learn Go", docs())
+	if len(s.Lessons) != 2 || s.Lessons[0].Name() != "hello" || s.Lessons[1].Name() != "maps" {
+		t.Fatalf("lessons in the order %v, want hello, maps", s.Lessons)
+	}
+	hello, maps := s.Lessons[0], s.Lessons[1]
+	if hello.Prev != nil || hello.Next != maps || maps.Prev != hello || maps.Next != nil {
+		t.Errorf("links: hello %v/%v, maps %v/%v", hello.Prev, hello.Next, maps.Prev, maps.Next)
+	}
+	if s.Lookup("maps") != maps || s.Lookup("loops") != nil {
+		t.Error("Lookup does not find the lessons")
+	}
+	var topics []string
+	for _, tp := range s.Topics() {
+		topics = append(topics, tp.Name)
+	}
+	if want := []string{"basics", "types"}; !reflect.DeepEqual(topics, want) {
+		t.Errorf("topics %v, want %v", topics, want)
+	}
+
+	// the same order makes the name decide
+	d := docs()
+	d[0].Meta.Order = 1
+	if s := site.New("", d); s.Lessons[0].Name() != "hello" {
+		t.Errorf("lessons of the same order in the order %s, %s", s.Lessons[0].Name(), s.Lessons[1].Name())
+	}
+}
+
+func TestIndex(t *testing.T) {
+	idx := site.New("Learn Go", docs()).Index()
+	want := []site.SearchEntry{{"Hello", "basics", "hello.html"}, {"Maps & Sets", "types", "maps.html"}}
+	if !reflect.DeepEqual(idx.Lessons, want) {
+		t.Errorf("lessons %+v, want %+v", idx.Lessons, want)
+	}
+	for word, lessons := range map[string][]int{
+		"hello":      {0}, // not the prerequisite of maps
+		"fmt":        {0, 1},
+		"println":    {0, 1},
+		"maps":       {1},
+		"dictionary": {1},
+		"parsedate":  {1},
+		"parse":      {1},
+		"date":       {1},
+		"basics":     {0},
+	} {
+		if got := idx.Words[word]; !reflect.DeepEqual(got, lessons) {
+			t.Errorf("%q is in lessons %v, want %v", word, got, lessons)
+		}
+	}
+	for _, word := range []string{"x", "a", "unindexed", "nil", "Println", "ParseDate"} {
+		if got, ok := idx.Words[word]; ok {
+			t.Errorf("%q is indexed: %v", word, got)
+		}
+	}
+}
+
+func TestWrite(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "site")
+	if err := site.New("Learn <Go>", docs()).Write(dir); err != nil {
+		t.Fatal(err)
+	}
+	read := func(name string) string {
+		data, err := os.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return string(data)
+	}
+
+	for file, want := range map[string][]string{
+		"index.html": {
+			"<h1>Learn &lt;Go&gt;</h1>",
+			`<h2 id="basics">basics</h2>`,
+			`<li><a href="hello.html">Hello</a></li>`,
+			`<li><a href="maps.html">Maps &amp; Sets</a></li>`,
+		},
+		"hello.html": {
+			"<h1>Hello</h1>",
+			"<em>hello</em>",
+			`<a class="next" href="maps.html">Maps &amp; Sets &rarr;</a>`,
+		},
+		"maps.html": {
+			"<h1>Maps &amp; Sets</h1>",
+			`Before this lesson:` + "\n" + `<a href="hello.html">Hello</a>`,
+			`<a class="prev" href="hello.html">&larr; Hello</a>`,
+			`<span class="kw">if</span> m[<span class="str">&#34;a&#34;</span>] &lt; <span class="num">2</span> {`,
+			`<samp>&lt;nil&gt; unindexed` + "\n</samp>",
+		},
+	} {
+		page := read(file)
+		for _, w := range want {
+			if !strings.Contains(page, w) {
+				t.Errorf("%s does not contain %s:\n%s", file, w, page)
+			}
+		}
+	}
+	if page := read("hello.html"); strings.Contains(page, `class="prev"`) {
+		t.Errorf("the first lesson links to a previous one:\n%s", page)
+	}
+	if page := read("maps.html"); strings.Contains(page, `class="next"`) || strings.Contains(page, "# Maps") {
+		t.Errorf("the last lesson links to a next one or repeats its heading:\n%s", page)
+	}
+
+	script := read("search-index.js")
+	js, ok := strings.CutPrefix(script, "var searchIndex = ")
+	if !ok || !strings.HasSuffix(js, ";\n") {
+		t.Fatalf("search-index.js = %s", script)
+	}
+	var idx site.SearchIndex
+	if err := json.Unmarshal([]byte(strings.TrimSuffix(js, ";\n")), &idx); err != nil {
+		t.Fatal(err)
+	}
+	if len(idx.Lessons) != 2 || !reflect.DeepEqual(idx.Words["parsedate"], []int{1}) {
+		t.Errorf("search index = %+v", idx)
+	}
+	for _, f := range []string{"style.css", "search.js"} {
+		if read(f) == "" {
+			t.Errorf("%s is empty", f)
+		}
+	}
+}
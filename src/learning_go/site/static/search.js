@@ -0,0 +1,53 @@
+// Searches the index in search-index.js: a lesson matches if every word of
+// the query starts one of its words.
+(function () {
+  var input = document.getElementById("search");
+  var results = document.getElementById("results");
+  if (!input || typeof searchIndex === "undefined") {
+    return;
+  }
+  var words = Object.keys(searchIndex.words);
+
+  function search(query) {
+    var terms = query.toLowerCase().split(/[^\p{L}\p{N}_]+/u).filter(Boolean);
+    if (terms.length === 0) {
+      return [];
+    }
+    var hits = null;
+    terms.forEach(function (term) {
+      var found = {};
+      words.forEach(function (w) {
+        if (w.indexOf(term) === 0) {
+          searchIndex.words[w].forEach(function (i) { found[i] = true; });
+        }
+      });
+      if (hits === null) {
+        hits = found;
+      } else {
+        Object.keys(hits).forEach(function (i) {
+          if (!found[i]) {
+            delete hits[i];
+          }
+        });
+      }
+    });
+    return Object.keys(hits).map(Number).sort(function (a, b) { return a - b; });
+  }
+
+  input.addEventListener("input", function () {
+    results.innerHTML = "";
+    search(input.value).forEach(function (i) {
+      var lesson = searchIndex.lessons[i];
+      var li = document.createElement("li");
+      var a = document.createElement("a");
+      a.href = lesson.url;
+      a.textContent = lesson.title;
+      var topic = document.createElement("span");
+      topic.className = "topic";
+      topic.textContent = " " + lesson.topic;
+      li.appendChild(a);
+      li.appendChild(topic);
+      results.appendChild(li);
+    });
+  });
+})();
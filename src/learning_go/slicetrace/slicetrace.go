@@ -0,0 +1,241 @@
+// Package slicetrace shows what append does to a slice. A Trace records
+// every append with the length and capacity that came out of it and
+// whether the elements had to move to a new backing array; a Diagram
+// draws slices that share a backing array.
+package slicetrace
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"unsafe"
+)
+
+// Arrays names backing arrays #1, #2, ... in the order they are seen, so
+// that traces and diagrams do not depend on addresses. It keeps the arrays
+// alive, which keeps the garbage collector from reusing an address for a
+// different array while the labels are in use.
+type Arrays struct {
+	labels map[uintptr]int
+	keep   []any
+}
+
+// label returns the label of the backing array of s, or 0 if s has no
+// capacity. Slices of the same array end at the same address, wherever
+// they start, so the array is identified by its end.
+func label[T any](a *Arrays, s []T) int {
+	if cap(s) == 0 {
+		return 0
+	}
+	end := addr(s) + uintptr(cap(s))*size[T]()
+	if a.labels == nil {
+		a.labels = map[uintptr]int{}
+	}
+	n, ok := a.labels[end]
+	if !ok {
+		n = len(a.labels) + 1
+		a.labels[end] = n
+		a.keep = append(a.keep, s)
+	}
+	return n
+}
+
+func addr[T any](s []T) uintptr {
+	return uintptr(unsafe.Pointer(unsafe.SliceData(s)))
+}
+
+func size[T any]() uintptr {
+	var zero T
+	return unsafe.Sizeof(zero)
+}
+
+// show formats a value, with strings quoted so that empty ones are seen.
+func show(v any) string {
+	if s, ok := v.(string); ok {
+		return strconv.Quote(s)
+	}
+	return fmt.Sprint(v)
+}
+
+func values[T any](s []T) string {
+	list := make([]string, len(s))
+	for i, v := range s {
+		list[i] = show(v)
+	}
+	return "[" + strings.Join(list, " ") + "]"
+}
+
+func arrayName(n int) string {
+	if n == 0 {
+		return "-"
+	}
+	return fmt.Sprintf("#%d", n)
+}
+
+// Step is the state of a traced slice after an operation.
+type Step struct {
+	Op    string // e.g. `append(s, "tim")`
+	Len   int
+	Cap   int
+	Array int  // label of the backing array, 0 for none
+	Moved bool // the operation put the slice on a new array
+	Value string
+}
+
+// Trace records the appends to a slice.
+type Trace[T any] struct {
+	S      []T
+	Steps  []Step
+	Arrays *Arrays
+}
+
+// New starts a trace of s; op tells how s was made.
+func New[T any](op string, s []T) *Trace[T] {
+	t := &Trace[T]{S: s, Arrays: &Arrays{}}
+	t.record(op, false)
+	return t
+}
+
+// Append appends vs to the traced slice, records the step and returns
+// the new slice.
+func (t *Trace[T]) Append(vs ...T) []T {
+	before := label(t.Arrays, t.S)
+	t.S = append(t.S, vs...)
+	args := []string{"s"}
+	for _, v := range vs {
+		args = append(args, show(v))
+	}
+	t.record("append("+strings.Join(args, ", ")+")", label(t.Arrays, t.S) != before)
+	return t.S
+}
+
+func (t *Trace[T]) record(op string, moved bool) {
+	t.Steps = append(t.Steps, Step{
+		Op:    op,
+		Len:   len(t.S),
+		Cap:   cap(t.S),
+		Array: label(t.Arrays, t.S),
+		Moved: moved,
+		Value: values(t.S),
+	})
+}
+
+// Write prints the steps as a table.
+func (t *Trace[T]) Write(w io.Writer) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "step\top\tlen\tcap\tarray\tnew array\ts")
+	for i, s := range t.Steps {
+		moved := ""
+		if s.Moved {
+			moved = "yes"
+		}
+		fmt.Fprintf(tw, "%d\t%s\t%d\t%d\t%s\t%s\t%s\n", i, s.Op, s.Len, s.Cap, arrayName(s.Array), moved, s.Value)
+	}
+	return tw.Flush()
+}
+
+// Diagram draws named slices over their backing arrays. Each array is a
+// row of cells; below it each slice that uses the array is a bracket from
+// its first element to the end of its capacity, with = over its length
+// and - over the spare capacity:
+//
+//	array #1
+//	     +---+---+---+---+---+
+//	     | 1 | 2 | 3 | 4 | 0 |
+//	     +---+---+---+---+---+
+//	a    [===============|---]  len 4 cap 5
+//	b        [=======|-------]  len 2 cap 4
+type Diagram[T any] struct {
+	Arrays *Arrays
+	views  []view[T]
+}
+
+type view[T any] struct {
+	name string
+	s    []T
+}
+
+// Add adds the slice s under name.
+func (d *Diagram[T]) Add(name string, s []T) {
+	d.views = append(d.views, view[T]{name, s})
+}
+
+// Write draws the arrays in the order of their labels, followed by the
+// slices without an array.
+func (d *Diagram[T]) Write(w io.Writer) error {
+	if d.Arrays == nil {
+		d.Arrays = &Arrays{}
+	}
+	indent := 0
+	groups := map[int][]view[T]{}
+	var labels []int
+	for _, v := range d.views {
+		indent = max(indent, len(v.name)+2)
+		n := label(d.Arrays, v.s)
+		if _, ok := groups[n]; !ok {
+			labels = append(labels, n)
+		}
+		groups[n] = append(groups[n], v)
+	}
+	sort.Slice(labels, func(i, j int) bool {
+		a, b := labels[i], labels[j]
+		return a != 0 && (b == 0 || a < b)
+	})
+
+	var sb strings.Builder
+	for _, n := range labels {
+		views := groups[n]
+		if n == 0 {
+			for _, v := range views {
+				fmt.Fprintf(&sb, "%-*s(no array)  len %d cap %d\n", indent, v.name, len(v.s), cap(v.s))
+			}
+			continue
+		}
+		// the slice that starts first shows the whole array
+		array := views[0].s
+		for _, v := range views[1:] {
+			if addr(v.s) < addr(array) {
+				array = v.s
+			}
+		}
+		array = array[:cap(array)]
+		cells := make([]string, len(array))
+		width := 1
+		for i, e := range array {
+			cells[i] = show(e)
+			width = max(width, len(cells[i]))
+		}
+		width += 2
+
+		pad := strings.Repeat(" ", indent)
+		border := pad + "+" + strings.Repeat(strings.Repeat("-", width)+"+", len(cells))
+		fmt.Fprintf(&sb, "array %s\n%s\n%s|", arrayName(n), border, pad)
+		for _, c := range cells {
+			fmt.Fprintf(&sb, " %-*s|", width-1, c)
+		}
+		fmt.Fprintf(&sb, "\n%s\n", border)
+		for _, v := range views {
+			off := int((addr(v.s) - addr(array)) / size[T]())
+			line := []byte(strings.Repeat(" ", off*(width+1)) + "[")
+			for i := 0; i < cap(v.s); i++ {
+				fill, sep := "=", byte('|')
+				if i >= len(v.s) {
+					fill = "-"
+				}
+				if i+1 == cap(v.s) {
+					sep = ']'
+				} else if i+1 != len(v.s) {
+					sep = fill[0]
+				}
+				line = append(line, strings.Repeat(fill, width)...)
+				line = append(line, sep)
+			}
+			fmt.Fprintf(&sb, "%-*s%s  len %d cap %d\n", indent, v.name, line, len(v.s), cap(v.s))
+		}
+	}
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
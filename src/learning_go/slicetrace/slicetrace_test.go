@@ -0,0 +1,135 @@
+package slicetrace_test
+
+import (
+	"strings"
+	"testing"
+
+	"gbdmp/learningo/slicetrace"
+)
+
+func TestTrace(t *testing.T) {
+	tr := slicetrace.New("make([]int, 0, 2)", make([]int, 0, 2))
+	for i := 1; i <= 20; i++ {
+		tr.Append(i)
+	}
+	if first := tr.Steps[0]; first != (slicetrace.Step{Op: "make([]int, 0, 2)", Len: 0, Cap: 2, Array: 1, Value: "[]"}) {
+		t.Errorf("first step = %+v", first)
+	}
+	arrays := 1
+	for i, s := range tr.Steps[1:] {
+		prev := tr.Steps[i]
+		// append moves the elements exactly when it runs out of capacity,
+		// and every move is to an array not seen before
+		if grew := s.Cap != prev.Cap; s.Moved != grew || grew != (prev.Len == prev.Cap) {
+			t.Errorf("step %d: %+v after %+v", i+1, s, prev)
+		}
+		if s.Moved {
+			arrays++
+		}
+		if s.Array != arrays || s.Len != i+1 || s.Cap < s.Len {
+			t.Errorf("step %d: %+v, want array #%d and len %d", i+1, s, arrays, i+1)
+		}
+	}
+	if last := tr.Steps[len(tr.Steps)-1]; last.Op != "append(s, 20)" || !strings.HasSuffix(last.Value, " 19 20]") {
+		t.Errorf("last step = %+v", last)
+	}
+	if len(tr.S) != 20 || tr.S[19] != 20 {
+		t.Errorf("traced slice = %v", tr.S)
+	}
+}
+
+func TestTraceNil(t *testing.T) {
+	tr := slicetrace.New("var s []string", []string(nil))
+	s := tr.Append("", "tim")
+	want := []slicetrace.Step{
+		{Op: "var s []string", Value: "[]"},
+		{Op: `append(s, "", "tim")`, Len: 2, Cap: cap(s), Array: 1, Moved: true, Value: `["" "tim"]`},
+	}
+	if len(tr.Steps) != 2 || tr.Steps[0] != want[0] || tr.Steps[1] != want[1] {
+		t.Errorf("steps = %+v, want %+v", tr.Steps, want)
+	}
+
+	var sb strings.Builder
+	if err := tr.Write(&sb); err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(sb.String(), "\n")
+	if len(lines) != 4 || !strings.HasPrefix(lines[0], "step  op ") || !strings.Contains(lines[1], "  -      ") || !strings.Contains(lines[2], "#1     yes") {
+		t.Errorf("Write =\n%s", sb.String())
+	}
+}
+
+// TestTraceShared checks that an append within the capacity writes to the
+// array it shares with the original slice.
+func TestTraceShared(t *testing.T) {
+	a := make([]int, 2, 3)
+	tr := slicetrace.New("a[:2]", a)
+	b := tr.Append(7)
+	if tr.Steps[1].Moved || tr.Steps[1].Array != 1 || a[:3][2] != 7 {
+		t.Errorf("append within the capacity = %+v, a[:3] = %v", tr.Steps[1], a[:3])
+	}
+	c := tr.Append(8)
+	if !tr.Steps[2].Moved || tr.Steps[2].Array != 2 {
+		t.Errorf("append beyond the capacity = %+v", tr.Steps[2])
+	}
+	c[0] = 1
+	if a[0] != 0 || b[0] != 0 {
+		t.Error("the new array is shared with the old one")
+	}
+}
+
+func TestDiagram(t *testing.T) {
+	a := make([]int, 4, 5)
+	copy(a, []int{1, 2, 3, 4})
+	b := a[1:3]
+	d := slicetrace.Diagram[int]{}
+	d.Add("nil", nil)
+	d.Add("c", []int{9})
+	d.Add("a", a)
+	d.Add("b", b)
+	var sb strings.Builder
+	if err := d.Write(&sb); err != nil {
+		t.Fatal(err)
+	}
+	want := `array #1
+     +---+
+     | 9 |
+     +---+
+c    [===]  len 1 cap 1
+array #2
+     +---+---+---+---+---+
+     | 1 | 2 | 3 | 4 | 0 |
+     +---+---+---+---+---+
+a    [===============|---]  len 4 cap 5
+b        [=======|-------]  len 2 cap 4
+nil  (no array)  len 0 cap 0
+`
+	if got := sb.String(); got != want {
+		t.Errorf("Write =\n%s\nwant\n%s", got, want)
+	}
+}
+
+// TestDiagramLabels checks that a diagram sharing the Arrays of a trace
+// uses its labels, and that a slice starting later in the array still
+// draws the whole array.
+func TestDiagramLabels(t *testing.T) {
+	tr := slicetrace.New(`[]string{"a"}`, []string{"a"})
+	grown := tr.Append("bb")
+	d := slicetrace.Diagram[string]{Arrays: tr.Arrays}
+	d.Add("tail", grown[1:])
+	d.Add("s", grown)
+	var sb strings.Builder
+	if err := d.Write(&sb); err != nil {
+		t.Fatal(err)
+	}
+	want := `array #2
+      +------+------+
+      | "a"  | "bb" |
+      +------+------+
+tail         [======]  len 1 cap 1
+s     [=============]  len 2 cap 2
+`
+	if got := sb.String(); got != want {
+		t.Errorf("Write =\n%s\nwant\n%s", got, want)
+	}
+}